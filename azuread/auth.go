@@ -0,0 +1,377 @@
+package azuread
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/hashicorp/go-hclog"
+)
+
+// Authentication modes which can be selected with auth_mode.
+const (
+	authModeCli              = "cli"
+	authModeClientSecret     = "client_secret"
+	authModeCertificate      = "certificate"
+	authModeMsi              = "msi"
+	authModeWorkloadIdentity = "workload_identity"
+	authModeDefaultChain     = "default_chain"
+)
+
+// authModes are the valid auth_mode values. The methods of the default chain
+// are tried in the same order, before the chain itself.
+var authModes = []string{authModeClientSecret, authModeCertificate, authModeWorkloadIdentity, authModeMsi, authModeCli, authModeDefaultChain}
+
+// msiProbeTimeout is how long the default chain waits for a managed identity
+// token when enable_msi is not set, since outside of Azure the Instance
+// Metadata Service endpoint does not answer.
+const msiProbeTimeout = 2 * time.Second
+
+// authSettings are the credential settings of a connection, read from the
+// connection config or the standard Azure environment variables.
+type authSettings struct {
+	mode                string
+	tenantID            string
+	clientID            string
+	clientSecret        string
+	certificatePath     string
+	certificatePassword string
+	federatedTokenFile  string
+	// federatedAuthorityHost is the authority host set in the environment for
+	// workload identity, if the connection config sets no cloud
+	federatedAuthorityHost string
+	enableMsi              bool
+	msiEndpoint            string
+	msiResourceID          string
+	cloud                  cloud.Configuration
+	// transport is the proxy and CA certificate aware transport for token requests, if any
+	transport *http.Transport
+}
+
+func getAuthSettings(config azureADConfig, cloudConfiguration cloud.Configuration, transport *http.Transport) authSettings {
+	settings := authSettings{cloud: cloudConfiguration, transport: transport}
+
+	if config.AuthMode != nil {
+		settings.mode = *config.AuthMode
+	}
+
+	if config.TenantID != nil {
+		settings.tenantID = *config.TenantID
+	} else {
+		settings.tenantID = os.Getenv("AZURE_TENANT_ID")
+	}
+
+	// 1. Client secret credentials
+	if config.ClientID != nil {
+		settings.clientID = *config.ClientID
+	} else {
+		settings.clientID = os.Getenv("AZURE_CLIENT_ID")
+	}
+
+	if config.ClientSecret != nil {
+		settings.clientSecret = *config.ClientSecret
+	} else {
+		settings.clientSecret = os.Getenv("AZURE_CLIENT_SECRET")
+	}
+
+	// 2. Client certificate credentials
+	if config.CertificatePath != nil {
+		settings.certificatePath = *config.CertificatePath
+	} else {
+		settings.certificatePath = os.Getenv("AZURE_CERTIFICATE_PATH")
+	}
+
+	if config.CertificatePassword != nil {
+		settings.certificatePassword = *config.CertificatePassword
+	} else {
+		settings.certificatePassword = os.Getenv("AZURE_CERTIFICATE_PASSWORD")
+	}
+
+	// 3. Workload identity credentials
+	if config.FederatedTokenFile != nil {
+		settings.federatedTokenFile = *config.FederatedTokenFile
+	} else {
+		settings.federatedTokenFile = os.Getenv("AZURE_FEDERATED_TOKEN_FILE")
+	}
+	// The workload identity webhook of Azure Kubernetes Service sets the authority
+	// host of the cloud, which the environment and authority_host arguments override
+	if (config.Environment == nil || *config.Environment == "") && (config.AuthorityHost == nil || *config.AuthorityHost == "") {
+		settings.federatedAuthorityHost = os.Getenv("AZURE_AUTHORITY_HOST")
+	}
+
+	// 4. Managed identity credentials
+	if config.EnableMsi != nil {
+		settings.enableMsi = *config.EnableMsi
+	}
+	if config.MsiEndpoint != nil {
+		settings.msiEndpoint = *config.MsiEndpoint
+	}
+	if config.MsiResourceID != nil {
+		settings.msiResourceID = *config.MsiResourceID
+	}
+
+	return settings
+}
+
+// validate returns an error if the settings required by an authentication mode are missing.
+func (s authSettings) validate(mode string) error {
+	var missing []string
+	require := func(name, value string) {
+		if value == "" {
+			missing = append(missing, name)
+		}
+	}
+
+	switch mode {
+	case authModeClientSecret:
+		require("tenant_id", s.tenantID)
+		require("client_id", s.clientID)
+		require("client_secret", s.clientSecret)
+	case authModeCertificate:
+		require("tenant_id", s.tenantID)
+		require("client_id", s.clientID)
+		require("certificate_path", s.certificatePath)
+	case authModeWorkloadIdentity:
+		require("tenant_id", s.tenantID)
+		require("client_id", s.clientID)
+		require("federated_token_file", s.federatedTokenFile)
+	case authModeMsi, authModeCli, authModeDefaultChain:
+	default:
+		return fmt.Errorf("invalid auth_mode %q, must be one of: %s", mode, strings.Join(authModes, ", "))
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("auth_mode %q requires %s to be set in the connection config or environment variables", mode, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// implicitMode returns the authentication mode used when auth_mode is not
+// set, in the order:
+// 1. Client secret
+// 2. Client certificate
+// 3. Workload identity (federated token file)
+// 4. MSI
+// 5. CLI, if no tenant is set
+func (s authSettings) implicitMode() (string, error) {
+	if s.tenantID == "" {
+		return authModeCli, nil
+	}
+	for _, mode := range []string{authModeClientSecret, authModeCertificate, authModeWorkloadIdentity} {
+		if s.validate(mode) == nil {
+			return mode, nil
+		}
+	}
+	if s.enableMsi {
+		return authModeMsi, nil
+	}
+	return "", fmt.Errorf("tenant_id is set but no credentials were found for it: set client_id with client_secret, certificate_path or federated_token_file, set enable_msi = true, or choose an auth_mode")
+}
+
+// authMode returns the authentication mode of the connection, auth_mode or
+// else the implicit mode, or an error if the settings it requires are missing.
+func (s authSettings) authMode() (string, error) {
+	mode := s.mode
+	if mode == "" {
+		implicit, err := s.implicitMode()
+		if err != nil {
+			return "", err
+		}
+		mode = implicit
+	}
+
+	if err := s.validate(mode); err != nil {
+		return "", err
+	}
+	return mode, nil
+}
+
+// getGraphCredential returns the credential for the authentication mode of the connection.
+func getGraphCredential(logger hclog.Logger, settings authSettings) (azcore.TokenCredential, error) {
+	mode, err := settings.authMode()
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == authModeDefaultChain {
+		return newDefaultChainCredential(logger, settings), nil
+	}
+
+	cred, err := settings.newCredential(mode)
+	if err != nil {
+		logger.Error("GetGraphClient", "credential_error", err, "auth_mode", mode)
+		return nil, err
+	}
+	return cred, nil
+}
+
+// newCredential creates the credential of a single authentication mode.
+func (s authSettings) newCredential(mode string) (azcore.TokenCredential, error) {
+	clientOptions := policy.ClientOptions{
+		Cloud: s.cloud,
+	}
+	if s.transport != nil {
+		clientOptions.Transport = &http.Client{Transport: s.transport}
+	}
+
+	switch mode {
+	case authModeCli:
+		return azidentity.NewAzureCLICredential(
+			&azidentity.AzureCLICredentialOptions{},
+		)
+	case authModeClientSecret:
+		return azidentity.NewClientSecretCredential(
+			s.tenantID,
+			s.clientID,
+			s.clientSecret,
+			&azidentity.ClientSecretCredentialOptions{
+				ClientOptions: clientOptions,
+			},
+		)
+	case authModeCertificate:
+		// Load certificate from given path
+		loadFile, err := os.ReadFile(s.certificatePath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading certificate from %s: %v", s.certificatePath, err)
+		}
+
+		var certs []*x509.Certificate
+		var key crypto.PrivateKey
+		if s.certificatePassword == "" {
+			certs, key, err = azidentity.ParseCertificates(loadFile, nil)
+		} else {
+			certs, key, err = azidentity.ParseCertificates(loadFile, []byte(s.certificatePassword))
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("error parsing certificate from %s: %v", s.certificatePath, err)
+		}
+
+		return azidentity.NewClientCertificateCredential(
+			s.tenantID,
+			s.clientID,
+			certs,
+			key,
+			&azidentity.ClientCertificateCredentialOptions{
+				ClientOptions: clientOptions,
+			},
+		)
+	case authModeWorkloadIdentity:
+		authorityHost := s.cloud.ActiveDirectoryAuthorityHost
+		if s.federatedAuthorityHost != "" {
+			authorityHost = s.federatedAuthorityHost
+		}
+		cred := newWorkloadIdentityCredential(s.tenantID, s.clientID, s.federatedTokenFile, authorityHost)
+		if s.transport != nil {
+			cred.client.Transport = s.transport
+		}
+		return cred, nil
+	case authModeMsi:
+		return newManagedIdentityCredential(s.clientID, s.msiResourceID, s.msiEndpoint, clientOptions)
+	}
+
+	return nil, fmt.Errorf("invalid auth_mode %q", mode)
+}
+
+// chainSource is one authentication method of the default chain.
+type chainSource struct {
+	mode string
+	cred azcore.TokenCredential
+	// probeTimeout limits the first token request, if set
+	probeTimeout time.Duration
+}
+
+// defaultChainCredential tries each configured authentication method in
+// order until one returns a token, then keeps using that method.
+type defaultChainCredential struct {
+	logger  hclog.Logger
+	sources []chainSource
+	// skipped holds why methods were left out of the chain
+	skipped []string
+
+	mu       sync.Mutex
+	selected azcore.TokenCredential
+}
+
+func newDefaultChainCredential(logger hclog.Logger, settings authSettings) *defaultChainCredential {
+	c := &defaultChainCredential{logger: logger}
+
+	for _, mode := range authModes {
+		if mode == authModeDefaultChain {
+			continue
+		}
+		if err := settings.validate(mode); err != nil {
+			c.skipped = append(c.skipped, fmt.Sprintf("%s: %v", mode, err))
+			continue
+		}
+		cred, err := settings.newCredential(mode)
+		if err != nil {
+			c.skipped = append(c.skipped, fmt.Sprintf("%s: %v", mode, err))
+			continue
+		}
+
+		source := chainSource{mode: mode, cred: cred}
+		if mode == authModeMsi && !settings.enableMsi {
+			source.probeTimeout = msiProbeTimeout
+		}
+		c.sources = append(c.sources, source)
+	}
+
+	return c
+}
+
+// defaultChainError is returned when no method of the default chain returns a token.
+type defaultChainError struct {
+	attempts []string
+}
+
+func (e *defaultChainError) Error() string {
+	return "auth_mode \"default_chain\" found no working credentials: " + strings.Join(e.attempts, "; ")
+}
+
+// NonRetriable marks the error as a credential error, like the azidentity credential errors.
+func (e *defaultChainError) NonRetriable() {}
+
+func (c *defaultChainCredential) GetToken(ctx context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.selected != nil {
+		return c.selected.GetToken(ctx, opts)
+	}
+
+	attempts := append([]string{}, c.skipped...)
+	for _, source := range c.sources {
+		token, err := c.probe(ctx, source, opts)
+		if err != nil {
+			c.logger.Debug("GetGraphClient", "default_chain_attempt", source.mode, "error", err)
+			attempts = append(attempts, fmt.Sprintf("%s: %v", source.mode, err))
+			continue
+		}
+
+		c.logger.Info("GetGraphClient", "default_chain_selected", source.mode)
+		c.selected = source.cred
+		return token, nil
+	}
+
+	return azcore.AccessToken{}, &defaultChainError{attempts}
+}
+
+func (c *defaultChainCredential) probe(ctx context.Context, source chainSource, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	if source.probeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, source.probeTimeout)
+		defer cancel()
+	}
+	return source.cred.GetToken(ctx, opts)
+}
@@ -0,0 +1,126 @@
+package azuread
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/hashicorp/go-hclog"
+)
+
+func TestAuthSettingsValidate(t *testing.T) {
+	settings := authSettings{tenantID: testTenantID, clientID: "app-1"}
+
+	if err := settings.validate("password"); err == nil || !strings.Contains(err.Error(), "must be one of") {
+		t.Errorf("expected an invalid auth_mode error, got %v", err)
+	}
+	if err := settings.validate(authModeClientSecret); err == nil || !strings.Contains(err.Error(), "requires client_secret") {
+		t.Errorf("expected a missing client_secret error, got %v", err)
+	}
+	if err := (authSettings{}).validate(authModeWorkloadIdentity); err == nil || !strings.Contains(err.Error(), "tenant_id, client_id, federated_token_file") {
+		t.Errorf("expected every missing setting to be named, got %v", err)
+	}
+	for _, mode := range []string{authModeCli, authModeMsi, authModeDefaultChain} {
+		if err := settings.validate(mode); err != nil {
+			t.Errorf("%s: unexpected error %v", mode, err)
+		}
+	}
+}
+
+func TestAuthSettingsImplicitMode(t *testing.T) {
+	cases := []struct {
+		settings authSettings
+		want     string
+	}{
+		{authSettings{}, authModeCli},
+		{authSettings{tenantID: testTenantID, clientID: "app-1", clientSecret: "secret"}, authModeClientSecret},
+		{authSettings{tenantID: testTenantID, clientID: "app-1", certificatePath: "cert.pem"}, authModeCertificate},
+		{authSettings{tenantID: testTenantID, clientID: "app-1", federatedTokenFile: "token"}, authModeWorkloadIdentity},
+		{authSettings{tenantID: testTenantID, enableMsi: true}, authModeMsi},
+	}
+	for _, tc := range cases {
+		got, err := tc.settings.implicitMode()
+		if err != nil || got != tc.want {
+			t.Errorf("expected %s, got %s (%v)", tc.want, got, err)
+		}
+	}
+
+	// A tenant without any credentials used to leave the credential unset
+	if _, err := (authSettings{tenantID: testTenantID}).implicitMode(); err == nil {
+		t.Error("expected an error for a tenant without credentials")
+	}
+}
+
+func TestAuthSettingsFederatedAuthorityHost(t *testing.T) {
+	t.Setenv("AZURE_AUTHORITY_HOST", "https://login.example.com/")
+
+	cases := []struct {
+		config azureADConfig
+		want   string
+	}{
+		{azureADConfig{}, "https://login.example.com/"},
+		// The connection config wins over the environment variable
+		{azureADConfig{Environment: stringPtr(environmentUSGovernment)}, ""},
+		{azureADConfig{AuthorityHost: stringPtr("https://login.microsoftonline.us/")}, ""},
+	}
+	for _, tc := range cases {
+		if got := getAuthSettings(tc.config, cloud.AzurePublic, nil).federatedAuthorityHost; got != tc.want {
+			t.Errorf("expected %q, got %q", tc.want, got)
+		}
+	}
+}
+
+// testCredential returns a token, or an error if err is set.
+type testCredential struct {
+	token string
+	err   error
+	calls int
+}
+
+func (c *testCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	c.calls++
+	if c.err != nil {
+		return azcore.AccessToken{}, c.err
+	}
+	return azcore.AccessToken{Token: c.token}, nil
+}
+
+func TestDefaultChainCredential(t *testing.T) {
+	msi := &testCredential{err: errors.New("no managed identity endpoint")}
+	cli := &testCredential{token: "cli-token"}
+	chain := &defaultChainCredential{
+		logger: hclog.NewNullLogger(),
+		sources: []chainSource{
+			{mode: authModeMsi, cred: msi, probeTimeout: msiProbeTimeout},
+			{mode: authModeCli, cred: cli},
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		token, err := chain.GetToken(context.Background(), policy.TokenRequestOptions{})
+		if err != nil || token.Token != "cli-token" {
+			t.Fatalf("expected the cli token, got %q (%v)", token.Token, err)
+		}
+	}
+	// Once a method has worked, the chain keeps using it
+	if msi.calls != 1 || cli.calls != 2 {
+		t.Errorf("unexpected calls: msi %d, cli %d", msi.calls, cli.calls)
+	}
+
+	failing := &defaultChainCredential{
+		logger:  hclog.NewNullLogger(),
+		sources: []chainSource{{mode: authModeCli, cred: &testCredential{err: errors.New("az login required")}}},
+		skipped: []string{"client_secret: auth_mode \"client_secret\" requires client_secret"},
+	}
+	_, err := failing.GetToken(context.Background(), policy.TokenRequestOptions{})
+	if err == nil || !strings.Contains(err.Error(), "az login required") || !strings.Contains(err.Error(), "requires client_secret") {
+		t.Fatalf("expected every attempt in the error, got %v", err)
+	}
+	if got := getErrorObject(nil, err).Category; got != ErrorCategoryAuthentication {
+		t.Errorf("expected category %q, got %q", ErrorCategoryAuthentication, got)
+	}
+}
@@ -0,0 +1,470 @@
+package azuread
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	khttp "github.com/microsoft/kiota-http-go"
+)
+
+const (
+	cassetteModeRecord = "record"
+	cassetteModeReplay = "replay"
+
+	// scrubbedValuePrefix marks values replaced by the scrubber, so scrubbing is idempotent
+	scrubbedValuePrefix = "scrubbed-"
+
+	// cassetteTokenClaimsFile is the file of the recorded access token claims in the cassette directory
+	cassetteTokenClaimsFile = "token_claims.json"
+)
+
+// cassetteTokenClaims are the only access token claims written to a cassette,
+// i.e. the permissions the token grants and the application it was issued to,
+// so they can be replayed, e.g. by azuread_plugin_permission_check.
+var cassetteTokenClaims = []string{"appid", "azp", "roles", "scp"}
+
+// cassettePIIProperties are the Graph JSON properties holding personal data,
+// which are scrubbed before a response is written to a cassette.
+var cassettePIIProperties = map[string]bool{
+	"businessPhones":              true,
+	"city":                        true,
+	"employeeId":                  true,
+	"faxNumber":                   true,
+	"givenName":                   true,
+	"imAddresses":                 true,
+	"ipAddress":                   true,
+	"mail":                        true,
+	"mailNickname":                true,
+	"mobilePhone":                 true,
+	"onPremisesDistinguishedName": true,
+	"onPremisesImmutableId":       true,
+	"onPremisesSamAccountName":    true,
+	"onPremisesUserPrincipalName": true,
+	"otherMails":                  true,
+	"postalCode":                  true,
+	"principalDisplayName":        true,
+	"proxyAddresses":              true,
+	"streetAddress":               true,
+	"surname":                     true,
+	"userDisplayName":             true,
+	"userPrincipalName":           true,
+}
+
+// cassetteRecordedHeaders are the only response headers written to a cassette.
+var cassetteRecordedHeaders = []string{"Content-Type", "Retry-After"}
+
+// odataStringLiteral matches string literals in $filter and $search expressions.
+var odataStringLiteral = regexp.MustCompile(`'((?:[^']|'')*)'`)
+
+// cassette is the content of a cassette file: every response recorded for one request.
+type cassette struct {
+	Method    string             `json:"method"`
+	Url       string             `json:"url"`
+	Responses []cassetteResponse `json:"responses"`
+}
+
+type cassetteResponse struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body"`
+}
+
+// cassetteHandler is a middleware for the Graph HTTP client which records Graph
+// responses to cassette files, or replays them without sending any request.
+//
+// Each request is stored in its own file named after a hash of its method and
+// scrubbed URL. Repeated requests, e.g. retries of a throttled request, are
+// replayed in the order they were recorded. Access tokens and request headers
+// are never recorded, and personal data is scrubbed from URLs and response
+// bodies before they are written.
+type cassetteHandler struct {
+	mode string
+	dir  string
+
+	mu sync.Mutex
+	// recorded holds the cassettes written in this session, which are
+	// overwritten rather than appended to on their first write
+	recorded map[string]bool
+	// replayed holds the number of responses replayed for each cassette
+	replayed map[string]int
+	// tokenRecorded is true once the access token claims have been written
+	tokenRecorded bool
+}
+
+func newCassetteHandler(mode, dir string) *cassetteHandler {
+	return &cassetteHandler{
+		mode:     mode,
+		dir:      dir,
+		recorded: map[string]bool{},
+		replayed: map[string]int{},
+	}
+}
+
+// Intercept implements the khttp.Middleware interface.
+func (h *cassetteHandler) Intercept(pipeline khttp.Pipeline, middlewareIndex int, req *http.Request) (*http.Response, error) {
+	scrubber := newCassetteScrubber()
+	scrubbedUrl := scrubber.url(req.URL)
+	key := cassetteKey(req.Method, scrubbedUrl)
+
+	if h.mode == cassetteModeReplay {
+		return h.replay(req, key)
+	}
+
+	resp, err := pipeline.Next(req, middlewareIndex)
+	if err != nil {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	recorded := cassetteResponse{
+		StatusCode: resp.StatusCode,
+		Headers:    map[string]string{},
+		Body:       scrubber.body(body),
+	}
+	for _, header := range cassetteRecordedHeaders {
+		if value := resp.Header.Get(header); value != "" {
+			recorded.Headers[header] = value
+		}
+	}
+
+	if err := h.record(req.Method, scrubbedUrl, key, recorded); err != nil {
+		return nil, fmt.Errorf("error recording cassette: %v", err)
+	}
+	if err := h.recordTokenClaims(req); err != nil {
+		return nil, fmt.Errorf("error recording access token claims: %v", err)
+	}
+
+	return resp, nil
+}
+
+func (h *cassetteHandler) record(method, scrubbedUrl, key string, recorded cassetteResponse) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	c := &cassette{Method: method, Url: scrubbedUrl}
+	if h.recorded[key] {
+		existing, err := h.load(key)
+		if err != nil {
+			return err
+		}
+		c = existing
+	}
+	c.Responses = append(c.Responses, recorded)
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(h.dir, 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(h.path(key), data, 0600); err != nil {
+		return err
+	}
+	h.recorded[key] = true
+
+	return nil
+}
+
+// recordTokenClaims writes the cassetteTokenClaims of the access token of the
+// first request of the session. The token itself is never written.
+func (h *cassetteHandler) recordTokenClaims(req *http.Request) error {
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return nil
+	}
+	var claims map[string]interface{}
+	payload, err := accessTokenPayload(token)
+	if err != nil || json.Unmarshal(payload, &claims) != nil {
+		// Opaque tokens have no claims to record
+		return nil
+	}
+
+	recorded := map[string]interface{}{}
+	for _, claim := range cassetteTokenClaims {
+		if value, ok := claims[claim]; ok {
+			recorded[claim] = value
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.tokenRecorded {
+		return nil
+	}
+	data, err := json.MarshalIndent(recorded, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(h.dir, 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(h.dir, cassetteTokenClaimsFile), data, 0600); err != nil {
+		return err
+	}
+	h.tokenRecorded = true
+
+	return nil
+}
+
+func (h *cassetteHandler) replay(req *http.Request, key string) (*http.Response, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	c, err := h.load(key)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no cassette recorded for %s %s in %s", req.Method, req.URL.Path, h.dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Responses) == 0 {
+		return nil, fmt.Errorf("cassette %s has no responses", h.path(key))
+	}
+
+	// Once every recorded response has been replayed, keep serving the last one
+	i := min(h.replayed[key], len(c.Responses)-1)
+	h.replayed[key]++
+	recorded := c.Responses[i]
+
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}
+	for header, value := range recorded.Headers {
+		resp.Header.Set(header, value)
+	}
+
+	return resp, nil
+}
+
+func (h *cassetteHandler) load(key string) (*cassette, error) {
+	data, err := os.ReadFile(h.path(key))
+	if err != nil {
+		return nil, err
+	}
+
+	var c cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("error reading cassette %s: %v", h.path(key), err)
+	}
+	return &c, nil
+}
+
+func (h *cassetteHandler) path(key string) string {
+	return filepath.Join(h.dir, key+".json")
+}
+
+func cassetteKey(method, scrubbedUrl string) string {
+	sum := sha256.Sum256([]byte(method + " " + scrubbedUrl))
+	return hex.EncodeToString(sum[:])
+}
+
+// scrubCassetteValue replaces a value with a stable placeholder, so scrubbed
+// values still match across requests and responses.
+func scrubCassetteValue(value string) string {
+	if value == "" || strings.HasPrefix(value, scrubbedValuePrefix) {
+		return value
+	}
+	sum := sha256.Sum256([]byte(value))
+	return scrubbedValuePrefix + hex.EncodeToString(sum[:6])
+}
+
+// cassetteScrubber scrubs the personal data of a request and its response.
+// The user principal names and mail addresses it finds are also replaced
+// wherever else they appear in the response body, e.g. in error messages.
+type cassetteScrubber struct {
+	addresses map[string]string
+}
+
+func newCassetteScrubber() *cassetteScrubber {
+	return &cassetteScrubber{addresses: map[string]string{}}
+}
+
+func (s *cassetteScrubber) value(value string) string {
+	scrubbed := scrubCassetteValue(value)
+	if scrubbed != value && strings.Contains(value, "@") {
+		s.addresses[value] = scrubbed
+	}
+	return scrubbed
+}
+
+// url returns the URL with the user principal names in its path and the string
+// literals in its $filter and $search parameters scrubbed. Query parameters are
+// sorted, so the same request always has the same scrubbed URL.
+func (s *cassetteScrubber) url(u *url.URL) string {
+	segments := strings.Split(u.EscapedPath(), "/")
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil && strings.Contains(unescaped, "@") {
+			segments[i] = s.value(unescaped)
+		}
+	}
+
+	query := u.Query()
+	for _, param := range []string{"$filter", "$search"} {
+		for i, value := range query[param] {
+			query[param][i] = odataStringLiteral.ReplaceAllStringFunc(value, func(literal string) string {
+				return "'" + s.value(literal[1:len(literal)-1]) + "'"
+			})
+		}
+	}
+
+	scrubbed := fmt.Sprintf("%s://%s%s", u.Scheme, u.Host, strings.Join(segments, "/"))
+	if len(query) > 0 {
+		scrubbed += "?" + query.Encode()
+	}
+	return scrubbed
+}
+
+// body scrubs the personal data in a response body.
+func (s *cassetteScrubber) body(body []byte) string {
+	scrubbed := string(body)
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err == nil {
+		if b, err := json.Marshal(s.json(data)); err == nil {
+			scrubbed = string(b)
+		}
+	}
+
+	// Replace the longest addresses first, in case one contains another
+	addresses := make([]string, 0, len(s.addresses))
+	for address := range s.addresses {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool { return len(addresses[i]) > len(addresses[j]) })
+	for _, address := range addresses {
+		scrubbed = strings.ReplaceAll(scrubbed, address, s.addresses[address])
+	}
+
+	return scrubbed
+}
+
+func (s *cassetteScrubber) json(data interface{}) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		// The display name of a user is personal data too
+		_, isUser := v["userPrincipalName"]
+		isUser = isUser || v["@odata.type"] == "#microsoft.graph.user"
+
+		for key, value := range v {
+			switch {
+			case key == "@odata.nextLink":
+				if link, ok := value.(string); ok {
+					if u, err := url.Parse(link); err == nil {
+						v[key] = s.url(u)
+					}
+				}
+			case cassettePIIProperties[key] || (isUser && key == "displayName"):
+				v[key] = s.strings(value)
+			default:
+				v[key] = s.json(value)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, value := range v {
+			v[i] = s.json(value)
+		}
+		return v
+	}
+	return data
+}
+
+// strings scrubs a string or every string in an array.
+func (s *cassetteScrubber) strings(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return s.value(v)
+	case []interface{}:
+		for i, item := range v {
+			if str, ok := item.(string); ok {
+				v[i] = s.value(str)
+			}
+		}
+		return v
+	}
+	return value
+}
+
+// cassetteTokenCredential is used in replay mode, where no token is needed
+// since no request leaves the plugin. It returns an unsigned JWT with the
+// access token claims recorded in the cassette directory, if any, so the
+// token can still be inspected.
+type cassetteTokenCredential struct {
+	dir string
+}
+
+func (c cassetteTokenCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	claims := map[string]interface{}{}
+	data, err := os.ReadFile(filepath.Join(c.dir, cassetteTokenClaimsFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return azcore.AccessToken{}, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &claims); err != nil {
+			return azcore.AccessToken{}, fmt.Errorf("error reading %s: %v", cassetteTokenClaimsFile, err)
+		}
+	}
+
+	expiresOn := time.Now().Add(24 * time.Hour)
+	claims["exp"] = expiresOn.Unix()
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return azcore.AccessToken{}, err
+	}
+
+	encode := base64.RawURLEncoding.EncodeToString
+	token := encode([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + encode(payload) + "."
+	return azcore.AccessToken{Token: token, ExpiresOn: expiresOn}, nil
+}
+
+// getCassetteConfig returns the cassette mode and directory of the connection.
+// The mode is empty if Graph traffic is neither recorded nor replayed.
+func getCassetteConfig(config azureADConfig) (string, string, error) {
+	if config.CassetteMode == nil || *config.CassetteMode == "" {
+		return "", "", nil
+	}
+
+	mode := *config.CassetteMode
+	if mode != cassetteModeRecord && mode != cassetteModeReplay {
+		return "", "", fmt.Errorf("invalid cassette_mode %q, must be one of: %s, %s", mode, cassetteModeRecord, cassetteModeReplay)
+	}
+	if config.CassetteDir == nil || *config.CassetteDir == "" {
+		return "", "", fmt.Errorf("cassette_dir must be set when cassette_mode is %q", mode)
+	}
+
+	return mode, *config.CassetteDir, nil
+}
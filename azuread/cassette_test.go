@@ -0,0 +1,225 @@
+package azuread
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	khttp "github.com/microsoft/kiota-http-go"
+)
+
+func cassetteGet(t *testing.T, client *http.Client, url string) (int, string) {
+	t.Helper()
+
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading body failed: %v", err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("users",
+		map[string]interface{}{"id": "u1", "displayName": "Ada Lovelace", "userPrincipalName": "ada@example.com", "accountEnabled": true},
+		map[string]interface{}{"id": "u2", "displayName": "Alan Turing", "userPrincipalName": "alan@example.com", "accountEnabled": true},
+		map[string]interface{}{"id": "u3", "displayName": "Grace Hopper", "userPrincipalName": "grace@example.com", "accountEnabled": true},
+	)
+	standIn.setThrottled("users/ada@example.com", 1, "0")
+
+	dir := t.TempDir()
+	firstPage := standIn.baseUrl() + "/users?$filter=" + url.QueryEscape("accountEnabled eq true and mail eq 'ada@example.com'")
+	user := standIn.baseUrl() + "/users/ada@example.com"
+
+	// Record
+	recorder := khttp.GetDefaultClient(newCassetteHandler(cassetteModeRecord, dir))
+	status, recordedPage := cassetteGet(t, recorder, firstPage)
+	if status != http.StatusOK || !strings.Contains(recordedPage, "ada@example.com") {
+		t.Fatalf("expected the live response while recording, got %d %s", status, recordedPage)
+	}
+	if status, _ := cassetteGet(t, recorder, user); status != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", status)
+	}
+	if status, _ := cassetteGet(t, recorder, user); status != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", status)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 cassettes, got %d", len(files))
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, pii := range []string{"ada@example.com", "Ada Lovelace", "test-token"} {
+			if strings.Contains(string(data), pii) {
+				t.Errorf("cassette %s contains %q", file, pii)
+			}
+		}
+	}
+
+	// Replay with nothing served by the stand-in server
+	standIn.reset()
+	replayer := khttp.GetDefaultClient(newCassetteHandler(cassetteModeReplay, dir))
+
+	status, replayedPage := cassetteGet(t, replayer, firstPage)
+	if status != http.StatusOK || strings.Contains(replayedPage, "ada@example.com") || !strings.Contains(replayedPage, `"id":"u1"`) {
+		t.Fatalf("expected the scrubbed recorded response, got %d %s", status, replayedPage)
+	}
+	if status, _ := cassetteGet(t, replayer, user); status != http.StatusTooManyRequests {
+		t.Fatalf("expected the recorded 429, got %d", status)
+	}
+	if status, _ := cassetteGet(t, replayer, user); status != http.StatusNotFound {
+		t.Fatalf("expected the recorded 404, got %d", status)
+	}
+	if got := len(standIn.requestsFor("users")); got != 0 {
+		t.Errorf("expected no requests in replay mode, got %d", got)
+	}
+
+	// Requests which were not recorded fail
+	if _, err := replayer.Get(standIn.baseUrl() + "/groups"); err == nil {
+		t.Error("expected an error for a request without a cassette")
+	}
+}
+
+func TestCassetteReplaysNextLink(t *testing.T) {
+	standIn.reset()
+	standIn.setPageSize(1)
+	standIn.setCollection("users",
+		map[string]interface{}{"id": "u1", "userPrincipalName": "ada@example.com"},
+		map[string]interface{}{"id": "u2", "userPrincipalName": "alan@example.com"},
+	)
+
+	dir := t.TempDir()
+	firstPage := standIn.baseUrl() + "/users?$filter=" + url.QueryEscape("userType eq 'Member'")
+
+	recorder := khttp.GetDefaultClient(newCassetteHandler(cassetteModeRecord, dir))
+	_, body := cassetteGet(t, recorder, firstPage)
+	nextLink := extractNextLink(t, body)
+	cassetteGet(t, recorder, nextLink)
+
+	// The recorded next link is scrubbed, and must resolve to the recorded second page
+	standIn.reset()
+	replayer := khttp.GetDefaultClient(newCassetteHandler(cassetteModeReplay, dir))
+	_, body = cassetteGet(t, replayer, firstPage)
+	status, body := cassetteGet(t, replayer, extractNextLink(t, body))
+	if status != http.StatusOK || !strings.Contains(body, `"id":"u2"`) {
+		t.Fatalf("expected the recorded second page, got %d %s", status, body)
+	}
+}
+
+func extractNextLink(t *testing.T, body string) string {
+	t.Helper()
+
+	var page struct {
+		NextLink string `json:"@odata.nextLink"`
+	}
+	if err := json.Unmarshal([]byte(body), &page); err != nil || page.NextLink == "" {
+		t.Fatalf("no next link in %s", body)
+	}
+	return page.NextLink
+}
+
+func TestCassetteReplaysAccessTokenClaims(t *testing.T) {
+	standIn.reset()
+	standIn.setObject("organization", map[string]interface{}{"id": "o1"})
+
+	dir := t.TempDir()
+
+	// Replaying without recorded claims still returns a JWT
+	token, err := cassetteTokenCredential{dir: dir}.GetToken(context.Background(), policy.TokenRequestOptions{})
+	if err != nil {
+		t.Fatalf("getting the replay token failed: %v", err)
+	}
+	if claims, err := parseAccessToken(token.Token); err != nil || len(claims.Roles) != 0 {
+		t.Fatalf("expected a JWT without roles, got %v %v", claims, err)
+	}
+
+	// Record a request authenticated with a token
+	recorded := testAccessToken([]string{"Directory.Read.All"}, time.Now().Add(time.Hour))
+	req, err := http.NewRequest(http.MethodGet, standIn.baseUrl()+"/organization", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+recorded)
+	resp, err := khttp.GetDefaultClient(newCassetteHandler(cassetteModeRecord, dir)).Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, cassetteTokenClaimsFile))
+	if err != nil {
+		t.Fatalf("expected the token claims to be recorded: %v", err)
+	}
+	if strings.Contains(string(data), testTenantID) || strings.Contains(string(data), strings.Split(recorded, ".")[2]) {
+		t.Errorf("expected only the permission claims to be recorded, got %s", data)
+	}
+
+	// The replay token grants the recorded permissions
+	token, err = cassetteTokenCredential{dir: dir}.GetToken(context.Background(), policy.TokenRequestOptions{})
+	if err != nil {
+		t.Fatalf("getting the replay token failed: %v", err)
+	}
+	claims, err := parseAccessToken(token.Token)
+	if err != nil {
+		t.Fatalf("expected a JWT, got %v", err)
+	}
+	if len(claims.Roles) != 1 || claims.Roles[0] != "Directory.Read.All" || claims.AppId != "55555555-5555-5555-5555-555555555555" || claims.Exp == 0 {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+}
+
+func TestCassetteScrubberUrl(t *testing.T) {
+	u, err := url.Parse("https://graph.microsoft.com/v1.0/users/ada@example.com?$select=id&$filter=" + url.QueryEscape("mail eq 'ada@example.com' and accountEnabled eq true"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scrubbed := newCassetteScrubber().url(u)
+	if strings.Contains(scrubbed, "ada") {
+		t.Errorf("expected personal data to be scrubbed, got %s", scrubbed)
+	}
+
+	// Scrubbing a scrubbed URL must not change it, so recorded next links can be replayed
+	u, err = url.Parse(scrubbed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again := newCassetteScrubber().url(u); again != scrubbed {
+		t.Errorf("expected %s, got %s", scrubbed, again)
+	}
+}
+
+func TestGetCassetteConfig(t *testing.T) {
+	mode, dir, err := getCassetteConfig(azureADConfig{})
+	if mode != "" || dir != "" || err != nil {
+		t.Errorf("expected cassettes to be disabled by default, got %q %q %v", mode, dir, err)
+	}
+
+	if _, _, err := getCassetteConfig(azureADConfig{CassetteMode: stringPtr("rewind"), CassetteDir: stringPtr("dir")}); err == nil {
+		t.Error("expected an error for an invalid mode")
+	}
+	if _, _, err := getCassetteConfig(azureADConfig{CassetteMode: stringPtr(cassetteModeReplay)}); err == nil {
+		t.Error("expected an error without a directory")
+	}
+}
@@ -0,0 +1,115 @@
+package azuread
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+)
+
+// Environments which can be selected with environment or AZURE_ENVIRONMENT.
+const (
+	environmentPublic         = "AZUREPUBLICCLOUD"
+	environmentChina          = "AZURECHINACLOUD"
+	environmentUSGovernment   = "AZUREUSGOVERNMENTCLOUD"
+	environmentUSGovernmentL4 = "AZUREUSGOVERNMENTL4CLOUD"
+	environmentUSGovernmentL5 = "AZUREUSGOVERNMENTL5CLOUD"
+	environmentCustom         = "CUSTOM"
+)
+
+// graphCloud holds the endpoints of a national cloud.
+type graphCloud struct {
+	// AuthorityHost is the Microsoft Entra endpoint tokens are requested from
+	AuthorityHost string
+	// GraphEndpoint is the Microsoft Graph endpoint, without the API version
+	GraphEndpoint string
+}
+
+// graphClouds holds the endpoints of each national cloud, see
+// https://learn.microsoft.com/en-us/graph/deployments#microsoft-graph-and-graph-explorer-service-root-endpoints
+var graphClouds = map[string]graphCloud{
+	environmentPublic: {
+		AuthorityHost: "https://login.microsoftonline.com/",
+		GraphEndpoint: "https://graph.microsoft.com",
+	},
+	environmentChina: {
+		AuthorityHost: "https://login.chinacloudapi.cn/",
+		GraphEndpoint: "https://microsoftgraph.chinacloudapi.cn",
+	},
+	// US Government L4, also known as GCC High
+	environmentUSGovernment: {
+		AuthorityHost: "https://login.microsoftonline.us/",
+		GraphEndpoint: "https://graph.microsoft.us",
+	},
+	environmentUSGovernmentL4: {
+		AuthorityHost: "https://login.microsoftonline.us/",
+		GraphEndpoint: "https://graph.microsoft.us",
+	},
+	// US Government L5, also known as DoD
+	environmentUSGovernmentL5: {
+		AuthorityHost: "https://login.microsoftonline.us/",
+		GraphEndpoint: "https://dod-graph.microsoft.us",
+	},
+}
+
+// TokenScope returns the scope of access tokens for Microsoft Graph in the cloud.
+func (c graphCloud) TokenScope() string {
+	return strings.TrimSuffix(c.GraphEndpoint, "/") + "/.default"
+}
+
+// BaseUrl returns the base URL of the given version of the Microsoft Graph API in the cloud.
+func (c graphCloud) BaseUrl(apiVersion string) string {
+	return strings.TrimSuffix(c.GraphEndpoint, "/") + "/" + apiVersion
+}
+
+// Configuration returns the azidentity configuration of the cloud.
+func (c graphCloud) Configuration() cloud.Configuration {
+	return cloud.Configuration{ActiveDirectoryAuthorityHost: c.AuthorityHost}
+}
+
+// getGraphCloud returns the endpoints of the cloud of the connection. The
+// graph_endpoint and authority_host arguments override those of a national
+// cloud, and are both required for the CUSTOM environment.
+func getGraphCloud(config azureADConfig) (graphCloud, error) {
+	var environment string
+	if config.Environment != nil {
+		environment = *config.Environment
+	} else {
+		environment = os.Getenv("AZURE_ENVIRONMENT")
+	}
+	if environment == "" {
+		environment = environmentPublic
+	}
+	environment = strings.ToUpper(environment)
+
+	c, ok := graphClouds[environment]
+	if !ok && environment != environmentCustom {
+		environments := []string{environmentCustom}
+		for name := range graphClouds {
+			environments = append(environments, name)
+		}
+		sort.Strings(environments)
+		return graphCloud{}, fmt.Errorf("invalid environment %q, must be one of: %s", environment, strings.Join(environments, ", "))
+	}
+
+	if config.AuthorityHost != nil && *config.AuthorityHost != "" {
+		c.AuthorityHost = *config.AuthorityHost
+	}
+	if config.GraphEndpoint != nil && *config.GraphEndpoint != "" {
+		c.GraphEndpoint = *config.GraphEndpoint
+	}
+
+	for _, endpoint := range []struct{ name, value string }{{"authority_host", c.AuthorityHost}, {"graph_endpoint", c.GraphEndpoint}} {
+		if endpoint.value == "" {
+			return graphCloud{}, fmt.Errorf("%s must be set when environment is %q", endpoint.name, environmentCustom)
+		}
+		if u, err := url.Parse(endpoint.value); err != nil || u.Scheme == "" || u.Host == "" {
+			return graphCloud{}, fmt.Errorf("invalid %s %q, must be an absolute URL", endpoint.name, endpoint.value)
+		}
+	}
+
+	return c, nil
+}
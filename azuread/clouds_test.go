@@ -0,0 +1,58 @@
+package azuread
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetGraphCloud(t *testing.T) {
+	t.Setenv("AZURE_ENVIRONMENT", "")
+
+	cases := []struct {
+		config        azureADConfig
+		authorityHost string
+		baseUrl       string
+		scope         string
+	}{
+		{azureADConfig{}, "https://login.microsoftonline.com/", "https://graph.microsoft.com/v1.0", "https://graph.microsoft.com/.default"},
+		{azureADConfig{Environment: stringPtr("AZURECHINACLOUD")}, "https://login.chinacloudapi.cn/", "https://microsoftgraph.chinacloudapi.cn/v1.0", "https://microsoftgraph.chinacloudapi.cn/.default"},
+		{azureADConfig{Environment: stringPtr("AZUREUSGOVERNMENTCLOUD")}, "https://login.microsoftonline.us/", "https://graph.microsoft.us/v1.0", "https://graph.microsoft.us/.default"},
+		{azureADConfig{Environment: stringPtr("azureusgovernmentl5cloud")}, "https://login.microsoftonline.us/", "https://dod-graph.microsoft.us/v1.0", "https://dod-graph.microsoft.us/.default"},
+		{
+			azureADConfig{Environment: stringPtr("CUSTOM"), AuthorityHost: stringPtr("https://login.example.com/"), GraphEndpoint: stringPtr("https://graph.example.com/")},
+			"https://login.example.com/", "https://graph.example.com/v1.0", "https://graph.example.com/.default",
+		},
+		{azureADConfig{GraphEndpoint: stringPtr("https://graph.proxy.example.com")}, "https://login.microsoftonline.com/", "https://graph.proxy.example.com/v1.0", "https://graph.proxy.example.com/.default"},
+	}
+
+	for _, tc := range cases {
+		c, err := getGraphCloud(tc.config)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if c.Configuration().ActiveDirectoryAuthorityHost != tc.authorityHost || c.BaseUrl(graphApiVersionV1) != tc.baseUrl || c.TokenScope() != tc.scope {
+			t.Errorf("unexpected endpoints %+v", c)
+		}
+		if want := strings.TrimSuffix(tc.baseUrl, "v1.0") + "beta"; c.BaseUrl(graphApiVersionBeta) != want {
+			t.Errorf("expected beta base URL %s, got %s", want, c.BaseUrl(graphApiVersionBeta))
+		}
+	}
+}
+
+func TestGetGraphCloudErrors(t *testing.T) {
+	cases := []struct {
+		config azureADConfig
+		want   string
+	}{
+		{azureADConfig{Environment: stringPtr("AZUREGERMANCLOUD")}, "invalid environment"},
+		{azureADConfig{Environment: stringPtr("CUSTOM"), GraphEndpoint: stringPtr("https://graph.example.com")}, "authority_host must be set"},
+		{azureADConfig{Environment: stringPtr("CUSTOM"), AuthorityHost: stringPtr("https://login.example.com/")}, "graph_endpoint must be set"},
+		{azureADConfig{GraphEndpoint: stringPtr("graph.example.com")}, "invalid graph_endpoint"},
+	}
+
+	for _, tc := range cases {
+		if _, err := getGraphCloud(tc.config); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("expected an error containing %q, got %v", tc.want, err)
+		}
+	}
+}
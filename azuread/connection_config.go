@@ -5,14 +5,38 @@ import (
 )
 
 type azureADConfig struct {
+	AuthMode            *string `hcl:"auth_mode"`
 	TenantID            *string `hcl:"tenant_id"`
 	ClientID            *string `hcl:"client_id"`
 	ClientSecret        *string `hcl:"client_secret"`
 	CertificatePath     *string `hcl:"certificate_path"`
 	CertificatePassword *string `hcl:"certificate_password"`
+	FederatedTokenFile  *string `hcl:"federated_token_file"`
 	EnableMsi           *bool   `hcl:"enable_msi"`
 	MsiEndpoint         *string `hcl:"msi_endpoint"`
+	MsiResourceID       *string `hcl:"msi_resource_id"`
 	Environment         *string `hcl:"environment"`
+	AuthorityHost       *string `hcl:"authority_host"`
+	GraphEndpoint       *string `hcl:"graph_endpoint"`
+
+	GraphApiVersion *string  `hcl:"graph_api_version"`
+	BetaTables      []string `hcl:"beta_tables,optional"`
+
+	ProxyUrl           *string  `hcl:"proxy_url"`
+	NoProxy            []string `hcl:"no_proxy,optional"`
+	CaCertificateFiles []string `hcl:"ca_certificate_files,optional"`
+
+	MaxErrorRetryAttempts *int `hcl:"max_error_retry_attempts"`
+	MaxErrorRetryDelay    *int `hcl:"max_error_retry_delay"`
+
+	MaxRequestsPerSecond  *float64 `hcl:"max_requests_per_second"`
+	MaxConcurrentRequests *int     `hcl:"max_concurrent_requests"`
+
+	CassetteMode *string `hcl:"cassette_mode"`
+	CassetteDir  *string `hcl:"cassette_dir"`
+
+	DeltaSyncTables []string `hcl:"delta_sync_tables,optional"`
+	DeltaSyncDir    *string  `hcl:"delta_sync_dir"`
 }
 
 func ConfigInstance() interface{} {
@@ -0,0 +1,362 @@
+package azuread
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	"github.com/microsoft/kiota-abstractions-go/serialization"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// deltaSyncResources are the tables which can be listed from a delta sync
+// store, see delta_sync_tables, and the Graph collections of their objects.
+var deltaSyncResources = map[string]string{
+	"azuread_group":             "groups",
+	"azuread_service_principal": "servicePrincipals",
+	"azuread_user":              "users",
+}
+
+// deltaSyncStoreVersion is the version of the store file format. Stores of other versions are synced again in full.
+const deltaSyncStoreVersion = 1
+
+// deltaSyncState is what a delta sync store file holds: the objects of a
+// table as of the last sync, and the delta link which returns the changes
+// since then.
+type deltaSyncState struct {
+	Version int `json:"version"`
+	// Url is the delta request of the first sync. A store synced with another
+	// request, e.g. for other properties or another endpoint, is synced again.
+	Url       string                      `json:"url"`
+	DeltaLink string                      `json:"delta_link"`
+	Objects   map[string]*deltaSyncObject `json:"objects"`
+}
+
+// deltaSyncObject is an object of a delta sync store, with the JSON properties returned by Graph.
+type deltaSyncObject struct {
+	Object map[string]interface{} `json:"object"`
+	// LastChangeTime is the time of the sync which first returned the object or last returned changes to it
+	LastChangeTime time.Time `json:"last_change_time"`
+}
+
+// deltaSyncStore is the store file of a table of a connection. The state is
+// kept in memory after it is first loaded, and the store is locked while it
+// is synced, so concurrent queries wait for a single sync.
+type deltaSyncStore struct {
+	path string
+
+	mu sync.Mutex
+	// state is nil until loaded, and after a failed sync so it is loaded again
+	state *deltaSyncState
+}
+
+// deltaSyncStores are the stores of the plugin process by path.
+var deltaSyncStores sync.Map
+
+func getDeltaSyncStore(path string) *deltaSyncStore {
+	store, _ := deltaSyncStores.LoadOrStore(path, &deltaSyncStore{path: path})
+	return store.(*deltaSyncStore)
+}
+
+// validateDeltaSync returns an error if delta_sync_tables names a table which can't be delta synced.
+func validateDeltaSync(config azureADConfig) error {
+	var unsupported []string
+	for _, name := range config.DeltaSyncTables {
+		if _, ok := deltaSyncResources[name]; !ok {
+			unsupported = append(unsupported, name)
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		supported := helpers.SortedMapKeys(deltaSyncResources)
+		return fmt.Errorf("delta_sync_tables contains unsupported tables: %s, must be any of: %s", strings.Join(unsupported, ", "), strings.Join(supported, ", "))
+	}
+	return nil
+}
+
+// getDeltaSyncDir returns the directory of the delta sync stores of the
+// connection: delta_sync_dir, or the plugin's directory in the user cache
+// directory, with a subdirectory per connection.
+func getDeltaSyncDir(config azureADConfig, connectionName string) (string, error) {
+	dir := ""
+	if config.DeltaSyncDir != nil && *config.DeltaSyncDir != "" {
+		dir = *config.DeltaSyncDir
+	} else {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("delta_sync_dir must be set, the user cache directory is unknown: %v", err)
+		}
+		dir = filepath.Join(cacheDir, "steampipe-plugin-azuread", "delta")
+	}
+	return filepath.Join(dir, connectionName), nil
+}
+
+// usesDeltaSync reports whether the list of the query is served from the
+// delta sync store of the table. Queries with a filter or search are sent to
+// Graph as usual, since they can't be applied to the stored objects.
+func usesDeltaSync(d *plugin.QueryData) bool {
+	if d.Table == nil || !helpers.StringSliceContains(GetConfig(d.Connection).DeltaSyncTables, d.Table.Name) {
+		return false
+	}
+	return d.EqualsQuals["filter"] == nil && d.EqualsQuals["search"] == nil
+}
+
+// deltaSyncSelect returns the properties synced for the table: those of
+// every column, see selectProperties, except the ones delta queries can't
+// return, e.g. signInActivity.
+func deltaSyncSelect(d *plugin.QueryData, properties graphSelectProperties, unsupported ...string) []string {
+	var columns []string
+	for _, c := range d.Table.Columns {
+		columns = append(columns, c.Name)
+	}
+
+	var names []string
+	for _, name := range selectProperties(d.Table.Columns, columns, properties) {
+		if !helpers.StringSliceContains(unsupported, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+/*
+deltaSyncObjects returns the objects of the table of the query from its delta
+sync store, after applying the changes since the last sync.
+
+The first sync, and a sync after the delta link has expired, requests every
+object with a delta query. Later syncs follow the delta link of the previous
+one, which only returns the objects created, changed or deleted since.
+*/
+func deltaSyncObjects(ctx context.Context, d *plugin.QueryData, properties []string) ([]*deltaSyncObject, error) {
+	logger := plugin.Logger(ctx)
+
+	config := GetConfig(d.Connection)
+	dir, err := getDeltaSyncDir(config, d.Connection.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	_, adapter, err := GetGraphClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	deltaUrl := adapter.GetBaseUrl() + "/" + deltaSyncResources[d.Table.Name] + "/delta"
+	if len(properties) > 0 {
+		deltaUrl += "?" + url.Values{"$select": {strings.Join(properties, ",")}}.Encode()
+	}
+
+	store := getDeltaSyncStore(filepath.Join(dir, d.Table.Name+".json"))
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.state == nil {
+		if store.state, err = loadDeltaSyncState(store.path); err != nil {
+			logger.Warn("deltaSyncObjects", "load_store_error", err, "path", store.path)
+		}
+	}
+	state := store.state
+	if state == nil || state.Version != deltaSyncStoreVersion || state.Url != deltaUrl || state.DeltaLink == "" {
+		state = newDeltaSyncState(deltaUrl)
+	}
+	// Changes are applied to the state in memory, which is loaded again from
+	// the store if the sync fails part way through
+	store.state = nil
+
+	syncTime := time.Now().UTC()
+	next := state.DeltaLink
+	if next == "" {
+		next = deltaUrl
+	}
+	for next != "" {
+		page, err := getDeltaSyncPage(ctx, adapter, next)
+		if err != nil {
+			if isDeltaLinkExpired(err) && state.DeltaLink != "" {
+				logger.Warn("deltaSyncObjects", "delta_link_expired", err, "table", d.Table.Name)
+				state = newDeltaSyncState(deltaUrl)
+				next = deltaUrl
+				continue
+			}
+			return nil, err
+		}
+
+		for _, object := range page.Value {
+			state.apply(object, syncTime)
+		}
+
+		next = page.NextLink
+		if next == "" {
+			if page.DeltaLink == "" {
+				return nil, errors.New("the delta response has neither a next link nor a delta link")
+			}
+			state.DeltaLink = page.DeltaLink
+		}
+	}
+
+	if err := saveDeltaSyncState(store.path, state); err != nil {
+		return nil, err
+	}
+	store.state = state
+
+	ids := helpers.SortedMapKeys(state.Objects)
+	objects := make([]*deltaSyncObject, 0, len(ids))
+	for _, id := range ids {
+		objects = append(objects, state.Objects[id])
+	}
+	logger.Debug("deltaSyncObjects", "table", d.Table.Name, "objects", len(objects))
+
+	return objects, nil
+}
+
+func newDeltaSyncState(deltaUrl string) *deltaSyncState {
+	return &deltaSyncState{
+		Version: deltaSyncStoreVersion,
+		Url:     deltaUrl,
+		Objects: map[string]*deltaSyncObject{},
+	}
+}
+
+// apply applies an object returned by a delta query to the state: deleted
+// objects are removed, and the returned properties of new and changed objects
+// are merged into them.
+func (s *deltaSyncState) apply(object map[string]interface{}, syncTime time.Time) {
+	id, _ := object["id"].(string)
+	if id == "" {
+		return
+	}
+	if _, ok := object["@removed"]; ok {
+		delete(s.Objects, id)
+		return
+	}
+
+	stored := s.Objects[id]
+	if stored == nil {
+		stored = &deltaSyncObject{Object: map[string]interface{}{}}
+		s.Objects[id] = stored
+	}
+	for name, value := range object {
+		// Changes of relationships, e.g. members@delta, are not properties
+		if strings.HasSuffix(name, "@delta") {
+			continue
+		}
+		stored.Object[name] = value
+	}
+	stored.LastChangeTime = syncTime
+}
+
+// deltaSyncPage is a page of the response of a delta query.
+type deltaSyncPage struct {
+	Value     []map[string]interface{} `json:"value"`
+	NextLink  string                   `json:"@odata.nextLink"`
+	DeltaLink string                   `json:"@odata.deltaLink"`
+}
+
+// getDeltaSyncPage requests a page of a delta query, whose objects are kept as returned.
+func getDeltaSyncPage(ctx context.Context, adapter abstractions.RequestAdapter, pageUrl string) (*deltaSyncPage, error) {
+	page := &deltaSyncPage{}
+	if err := getGraphJSON(ctx, adapter, pageUrl, page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// isDeltaLinkExpired reports whether a delta query failed because its delta
+// link has expired, after which the objects must be synced again in full.
+func isDeltaLinkExpired(err error) bool {
+	var oDataError *odataerrors.ODataError
+	if !errors.As(err, &oDataError) {
+		return false
+	}
+	if oDataError.ResponseStatusCode == http.StatusGone {
+		return true
+	}
+	if terr := oDataError.GetErrorEscaped(); terr != nil && terr.GetCode() != nil {
+		code := strings.ToLower(*terr.GetCode())
+		return strings.Contains(code, "syncstate") || strings.Contains(code, "resync") || strings.Contains(code, "expiredpagetoken")
+	}
+	return false
+}
+
+// parseDeltaSyncObject parses the JSON properties of a stored object into a Graph model.
+func parseDeltaSyncObject[T serialization.Parsable](object *deltaSyncObject, constructor serialization.ParsableFactory) (T, error) {
+	var result T
+
+	content, err := json.Marshal(object.Object)
+	if err != nil {
+		return result, err
+	}
+	parseNode, err := serialization.DefaultParseNodeFactoryInstance.GetRootParseNode("application/json", content)
+	if err != nil {
+		return result, err
+	}
+	value, err := parseNode.GetObjectValue(constructor)
+	if err != nil {
+		return result, err
+	}
+
+	result, ok := value.(T)
+	if !ok {
+		return result, fmt.Errorf("unexpected delta sync object type %T", value)
+	}
+	return result, nil
+}
+
+func loadDeltaSyncState(path string) (*deltaSyncState, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state := &deltaSyncState{}
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, err
+	}
+	if state.Objects == nil {
+		state.Objects = map[string]*deltaSyncObject{}
+	}
+	return state, nil
+}
+
+// saveDeltaSyncState writes the state to a temporary file which replaces the
+// store, so a store is never left half written. The store holds directory
+// data, so it is only readable by the user.
+func saveDeltaSyncState(path string, state *deltaSyncState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,127 @@
+package azuread
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestValidateDeltaSync(t *testing.T) {
+	if err := validateDeltaSync(azureADConfig{DeltaSyncTables: []string{"azuread_user", "azuread_group"}}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	err := validateDeltaSync(azureADConfig{DeltaSyncTables: []string{"azuread_domain", "azuread_user"}})
+	if err == nil || !strings.Contains(err.Error(), "unsupported tables: azuread_domain") {
+		t.Errorf("expected an unsupported table error, got %v", err)
+	}
+}
+
+func TestDeltaSyncStateApply(t *testing.T) {
+	first := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+
+	state := newDeltaSyncState("https://graph.microsoft.com/v1.0/groups/delta")
+	state.apply(map[string]interface{}{"id": "g1", "displayName": "Finance", "mail": "finance@example.com"}, first)
+	state.apply(map[string]interface{}{"id": "g2", "displayName": "Sales"}, first)
+	state.apply(map[string]interface{}{"displayName": "No id"}, first)
+
+	state.apply(map[string]interface{}{"id": "g1", "displayName": "Finance EMEA", "members@delta": []interface{}{}}, second)
+	state.apply(map[string]interface{}{"id": "g2", "@removed": map[string]interface{}{"reason": "changed"}}, second)
+
+	if len(state.Objects) != 1 {
+		t.Fatalf("expected 1 object, got %v", state.Objects)
+	}
+	g1 := state.Objects["g1"]
+	if g1.Object["displayName"] != "Finance EMEA" || g1.Object["mail"] != "finance@example.com" {
+		t.Errorf("expected the changes to be merged, got %v", g1.Object)
+	}
+	if _, ok := g1.Object["members@delta"]; ok {
+		t.Errorf("expected no relationship changes, got %v", g1.Object)
+	}
+	if !g1.LastChangeTime.Equal(second) {
+		t.Errorf("unexpected last change time %v", g1.LastChangeTime)
+	}
+}
+
+func TestDeltaSync(t *testing.T) {
+	// Start without a store, in memory or on disk
+	storePath := filepath.Join(testDeltaSyncDir, testDeltaConnectionName, "azuread_group.json")
+	deltaSyncStores.Delete(storePath)
+	os.Remove(storePath)
+
+	standIn.reset()
+	standIn.setCollection("groups",
+		map[string]interface{}{"id": "g1", "displayName": "Finance"},
+		map[string]interface{}{"id": "g2", "displayName": "Sales"},
+		map[string]interface{}{"id": "g3", "displayName": "Legal"},
+	)
+
+	query := func() map[string]string {
+		t.Helper()
+		rows, err := executeConnectionQuery(t, testDeltaConnectionName, "azuread_group", []string{"id", "display_name", "last_change_time"}, nil)
+		if err != nil {
+			t.Fatalf("list failed: %v", err)
+		}
+		names := map[string]string{}
+		for _, row := range rows {
+			if row["last_change_time"].GetTimestampValue() == nil {
+				t.Errorf("expected a last_change_time for %v", row)
+			}
+			names[row["id"].GetStringValue()] = row["display_name"].GetStringValue()
+		}
+		return names
+	}
+
+	// The first sync requests every group, over several pages
+	names := query()
+	if len(names) != 3 || names["g1"] != "Finance" {
+		t.Fatalf("unexpected groups %v", names)
+	}
+	requests := standIn.requestsFor("groups/delta")
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 delta requests, got %d", len(requests))
+	}
+	if got := requests[0].URL.Query().Get("$select"); !strings.Contains(got, "displayName") || requests[0].URL.Query().Has("$deltatoken") {
+		t.Errorf("unexpected first delta request %v", requests[0].URL)
+	}
+	if _, err := os.Stat(storePath); err != nil {
+		t.Errorf("expected the store to be saved: %v", err)
+	}
+
+	// Later syncs only apply the changes returned for the delta link
+	standIn.setDelta("groups",
+		map[string]interface{}{"id": "g1", "displayName": "Finance EMEA"},
+		map[string]interface{}{"id": "g2", "@removed": map[string]interface{}{"reason": "deleted"}},
+		map[string]interface{}{"id": "g4", "displayName": "Marketing"},
+	)
+	names = query()
+	want := map[string]string{"g1": "Finance EMEA", "g3": "Legal", "g4": "Marketing"}
+	if len(names) != len(want) || names["g1"] != want["g1"] || names["g3"] != want["g3"] || names["g4"] != want["g4"] {
+		t.Errorf("expected %v, got %v", want, names)
+	}
+	requests = standIn.requestsFor("groups/delta")
+	if len(requests) != 4 || !requests[2].URL.Query().Has("$deltatoken") {
+		t.Errorf("expected the delta link to be followed, got %v", requests)
+	}
+
+	// An expired delta link syncs every group again
+	standIn.setTransientError("groups/delta", 410, "SyncStateNotFound", "The sync state generation is not found.", 1)
+	names = query()
+	if len(names) != 3 || names["g2"] != "Sales" {
+		t.Errorf("expected a full sync, got %v", names)
+	}
+
+	// Filters can't be applied to the stored groups, so they are sent to Graph
+	quals := map[string]*proto.Quals{"filter": {Quals: []*proto.Qual{stringQual("filter", "=", "startswith(displayName, 'F')")}}}
+	if _, err := executeConnectionQuery(t, testDeltaConnectionName, "azuread_group", []string{"id", "filter"}, quals); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	requests = standIn.requestsFor("groups")
+	if len(requests) == 0 || requests[0].URL.Query().Get("$filter") != "startswith(displayName, 'F')" {
+		t.Errorf("expected the filter to be sent to Graph, got %v", requests)
+	}
+}
@@ -2,49 +2,162 @@ package azuread
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"net/http"
 	"strings"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 )
 
+// ErrorCategory classifies the errors returned by Microsoft Graph and the credentials.
+type ErrorCategory string
+
+const (
+	ErrorCategoryUnknown           ErrorCategory = ""
+	ErrorCategoryAuthentication    ErrorCategory = "authentication_failure"
+	ErrorCategoryMissingPermission ErrorCategory = "missing_permission"
+	ErrorCategoryLicenseRequired   ErrorCategory = "license_required"
+	ErrorCategoryThrottled         ErrorCategory = "throttled"
+	ErrorCategoryNotFound          ErrorCategory = "not_found"
+	ErrorCategoryInvalidFilter     ErrorCategory = "invalid_filter"
+	ErrorCategoryUnsupportedQuery  ErrorCategory = "unsupported_query"
+)
+
 type RequestError struct {
-	Code    string
-	Message string
+	Code       string
+	Message    string
+	StatusCode int
+	Category   ErrorCategory
+	// Hint tells the user how to resolve the error, e.g. which permission to grant
+	Hint string
 }
 
 func (m *RequestError) Error() string {
-	errStr, err := json.Marshal(m)
-	if err != nil {
-		return ""
+	msg := m.Message
+	if m.Code != "" {
+		msg = m.Code + ": " + msg
 	}
-	return string(errStr)
+	if m.Category != ErrorCategoryUnknown {
+		msg = strings.ReplaceAll(string(m.Category), "_", " ") + ": " + msg
+	}
+	if m.Hint != "" {
+		msg = strings.TrimSuffix(msg, ".") + ". " + m.Hint
+	}
+	return msg
 }
 
-func getErrorObject(err error) *RequestError {
-	if oDataError, ok := err.(*odataerrors.ODataError); ok {
-		terr := oDataError.GetErrorEscaped()
-		if terr != nil {
-			return &RequestError{
-				Code:    *terr.GetCode(),
-				Message: *terr.GetMessage(),
+// getErrorObject converts an error returned by the Graph client into a
+// RequestError, classifying it and adding a hint for the queried table.
+func getErrorObject(d *plugin.QueryData, err error) *RequestError {
+	var requestErr *RequestError
+	if errors.As(err, &requestErr) {
+		return requestErr
+	}
+
+	requestErr = &RequestError{Message: err.Error()}
+
+	var oDataError *odataerrors.ODataError
+	if errors.As(err, &oDataError) {
+		requestErr.StatusCode = oDataError.ResponseStatusCode
+		if terr := oDataError.GetErrorEscaped(); terr != nil {
+			if terr.GetCode() != nil {
+				requestErr.Code = *terr.GetCode()
+			}
+			if terr.GetMessage() != nil {
+				requestErr.Message = *terr.GetMessage()
 			}
 		}
 	}
 
-	return &RequestError{Message: err.Error()}
+	// Credential errors, e.g. when the Azure CLI is not logged in, are not retriable
+	var authenticationErr *azidentity.AuthenticationFailedError
+	var credentialErr interface{ NonRetriable() }
+	if errors.As(err, &authenticationErr) || errors.As(err, &credentialErr) {
+		requestErr.Category = ErrorCategoryAuthentication
+	} else {
+		requestErr.Category = classifyError(requestErr.StatusCode, requestErr.Code, requestErr.Message)
+	}
+
+	var table string
+	if d != nil && d.Table != nil {
+		table = d.Table.Name
+	}
+	requestErr.Hint = errorHint(requestErr.Category, table)
+
+	return requestErr
+}
+
+// classifyError returns the category of a Graph error from its status code, error code and message.
+func classifyError(statusCode int, code string, message string) ErrorCategory {
+	lowerMessage := strings.ToLower(message)
+
+	switch {
+	// e.g. Authentication_RequestFromNonPremiumTenantOrB2CTenant for sign-in logs. The
+	// message is not matched, since other errors may mention licenses too
+	case strings.Contains(code, "NonPremium"):
+		return ErrorCategoryLicenseRequired
+	// e.g. Authentication_MSGraphPermissionMissing
+	case statusCode == http.StatusForbidden ||
+		strings.Contains(code, "PermissionMissing") ||
+		code == "Authorization_RequestDenied" ||
+		code == "Forbidden" ||
+		code == "accessDenied":
+		return ErrorCategoryMissingPermission
+	case statusCode == http.StatusUnauthorized ||
+		code == "InvalidAuthenticationToken" ||
+		strings.HasPrefix(code, "Authentication_"):
+		return ErrorCategoryAuthentication
+	case statusCode == http.StatusTooManyRequests || code == "TooManyRequests" || code == "activityLimitReached":
+		return ErrorCategoryThrottled
+	case statusCode == http.StatusNotFound ||
+		code == "Request_ResourceNotFound" ||
+		code == "ResourceNotFound" ||
+		strings.Contains(message, "Invalid object identifier"):
+		return ErrorCategoryNotFound
+	case strings.Contains(lowerMessage, "invalid filter clause"):
+		return ErrorCategoryInvalidFilter
+	case code == "Request_UnsupportedQuery" ||
+		strings.Contains(lowerMessage, "unsupported query"):
+		return ErrorCategoryUnsupportedQuery
+	}
+
+	return ErrorCategoryUnknown
 }
 
-func isIgnorableErrorPredicate(ignoreErrorCodes []string) plugin.ErrorPredicateWithContext {
+// errorHint returns a message telling the user how to resolve an error of the given category.
+func errorHint(category ErrorCategory, table string) string {
+	switch category {
+	case ErrorCategoryAuthentication:
+		return "Check the credentials in the connection config, or run az login to use Azure CLI authentication."
+	case ErrorCategoryMissingPermission:
+		return permissionHint(table)
+	case ErrorCategoryLicenseRequired:
+		return licenseHint(table)
+	case ErrorCategoryThrottled:
+		return "Lower max_requests_per_second or max_concurrent_requests, or raise max_error_retry_attempts in the connection config."
+	case ErrorCategoryInvalidFilter:
+		return "Microsoft Graph does not support this filter for the table, remove it from the query."
+	case ErrorCategoryUnsupportedQuery:
+		return "Microsoft Graph does not support this query for the table, remove its filter, search or ordering from the query."
+	}
+	return ""
+}
+
+// isIgnorableErrorCategoryPredicate returns a predicate which ignores errors of the given categories.
+func isIgnorableErrorCategoryPredicate(categories ...ErrorCategory) plugin.ErrorPredicateWithContext {
 	return func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData, err error) bool {
-		if err != nil {
-			if terr, ok := err.(*RequestError); ok {
-				for _, item := range ignoreErrorCodes {
-					if terr != nil && (terr.Code == item || strings.Contains(terr.Message, item)) {
-						return true
-					}
-				}
+		if err == nil {
+			return false
+		}
+		var terr *RequestError
+		if !errors.As(err, &terr) {
+			return false
+		}
+		for _, category := range categories {
+			if terr.Category == category {
+				return true
 			}
 		}
 		return false
@@ -0,0 +1,86 @@
+package azuread
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		statusCode int
+		code       string
+		message    string
+		want       ErrorCategory
+	}{
+		{http.StatusUnauthorized, "InvalidAuthenticationToken", "Access token has expired or is not yet valid.", ErrorCategoryAuthentication},
+		{http.StatusForbidden, "Authorization_RequestDenied", "Insufficient privileges to complete the operation.", ErrorCategoryMissingPermission},
+		{http.StatusForbidden, "Authentication_MSGraphPermissionMissing", "Calling principal does not have required MSGraph permissions AuditLog.Read.All", ErrorCategoryMissingPermission},
+		{http.StatusForbidden, "Authentication_RequestFromNonPremiumTenantOrB2CTenant", "Neither tenant is B2C or tenant doesn't have premium license", ErrorCategoryLicenseRequired},
+		{http.StatusForbidden, "Authorization_RequestDenied", "Insufficient privileges to read the license details.", ErrorCategoryMissingPermission},
+		{http.StatusTooManyRequests, "TooManyRequests", "Too many requests.", ErrorCategoryThrottled},
+		{http.StatusNotFound, "Request_ResourceNotFound", "Resource 'x' does not exist.", ErrorCategoryNotFound},
+		{http.StatusBadRequest, "Request_BadRequest", "Invalid object identifier 'x'.", ErrorCategoryNotFound},
+		{http.StatusBadRequest, "Request_UnsupportedQuery", "Unsupported Query.", ErrorCategoryUnsupportedQuery},
+		{http.StatusBadRequest, "BadRequest", "Invalid filter clause", ErrorCategoryInvalidFilter},
+		{http.StatusInternalServerError, "UnknownError", "", ErrorCategoryUnknown},
+	}
+
+	for _, tc := range cases {
+		if got := classifyError(tc.statusCode, tc.code, tc.message); got != tc.want {
+			t.Errorf("%d %s: expected %q, got %q", tc.statusCode, tc.code, tc.want, got)
+		}
+	}
+}
+
+func newTestODataError(statusCode int, code, message string) *odataerrors.ODataError {
+	mainError := odataerrors.NewMainError()
+	mainError.SetCode(&code)
+	mainError.SetMessage(&message)
+
+	err := odataerrors.NewODataError()
+	err.SetErrorEscaped(mainError)
+	err.ResponseStatusCode = statusCode
+	return err
+}
+
+func TestGetErrorObjectPermissionHint(t *testing.T) {
+	d := &plugin.QueryData{Table: &plugin.Table{Name: "azuread_sign_in_report"}}
+
+	err := getErrorObject(d, newTestODataError(http.StatusForbidden, "Authorization_RequestDenied", "Insufficient privileges to complete the operation."))
+	if err.Category != ErrorCategoryMissingPermission {
+		t.Errorf("expected %q, got %q", ErrorCategoryMissingPermission, err.Category)
+	}
+	if !strings.Contains(err.Error(), "AuditLog.Read.All") {
+		t.Errorf("expected the error to name AuditLog.Read.All, got %q", err.Error())
+	}
+
+	err = getErrorObject(d, newTestODataError(http.StatusForbidden, "Authentication_RequestFromNonPremiumTenantOrB2CTenant", "Neither tenant is B2C or tenant doesn't have premium license"))
+	if err.Category != ErrorCategoryLicenseRequired || !strings.Contains(err.Error(), licenseAzureADPremium) {
+		t.Errorf("expected a license error naming %q, got %q", licenseAzureADPremium, err.Error())
+	}
+}
+
+func TestGetErrorObjectUnwraps(t *testing.T) {
+	inner := getErrorObject(nil, newTestODataError(http.StatusNotFound, "Request_ResourceNotFound", "Resource 'x' does not exist."))
+	if got := getErrorObject(nil, inner); got != inner {
+		t.Errorf("expected the request error to be returned as is")
+	}
+
+	err := getErrorObject(nil, errors.New("connection reset by peer"))
+	if err.Category != ErrorCategoryUnknown || err.Error() != "connection reset by peer" {
+		t.Errorf("unexpected error %q with category %q", err.Error(), err.Category)
+	}
+}
+
+func TestTablesHavePermissionRequirements(t *testing.T) {
+	for table := range Plugin(nil).TableMap {
+		if permissionHint(table) == "" {
+			t.Errorf("table %s has no permission requirements", table)
+		}
+	}
+}
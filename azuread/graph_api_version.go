@@ -0,0 +1,107 @@
+package azuread
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// Microsoft Graph API versions which can be selected with graph_api_version.
+const (
+	graphApiVersionV1   = "v1.0"
+	graphApiVersionBeta = "beta"
+)
+
+// betaColumnDescription marks the description of a column whose data is only
+// returned by the Microsoft Graph beta endpoint.
+func betaColumnDescription(description string) string {
+	return description + " Requires the Microsoft Graph beta endpoint, see graph_api_version and beta_tables in the connection config; null otherwise."
+}
+
+// validateGraphApiVersion returns an error if graph_api_version is not a
+// known API version or beta_tables names a table the plugin does not have.
+func validateGraphApiVersion(config azureADConfig, tables map[string]*plugin.Table) error {
+	if config.GraphApiVersion != nil {
+		switch *config.GraphApiVersion {
+		case graphApiVersionV1, graphApiVersionBeta:
+		default:
+			return fmt.Errorf("invalid graph_api_version %q, must be one of: %s, %s", *config.GraphApiVersion, graphApiVersionV1, graphApiVersionBeta)
+		}
+	}
+
+	var unknown []string
+	for _, name := range config.BetaTables {
+		if _, ok := tables[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("beta_tables contains unknown tables: %s", strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+// getGraphApiVersion returns the Microsoft Graph API version a table is
+// queried with: beta if the connection uses it for every table or the table
+// is listed in beta_tables, otherwise v1.0.
+func getGraphApiVersion(config azureADConfig, tableName string) string {
+	if config.GraphApiVersion != nil && *config.GraphApiVersion == graphApiVersionBeta {
+		return graphApiVersionBeta
+	}
+	for _, name := range config.BetaTables {
+		if name == tableName {
+			return graphApiVersionBeta
+		}
+	}
+	return graphApiVersionV1
+}
+
+// usesGraphBeta reports whether the table of the query is queried with the beta endpoint.
+func usesGraphBeta(d *plugin.QueryData) bool {
+	return d.Table != nil && getGraphApiVersion(GetConfig(d.Connection), d.Table.Name) == graphApiVersionBeta
+}
+
+// graphBaseUrlForVersion returns a Graph base URL for another API version,
+// e.g. https://graph.microsoft.com/beta for https://graph.microsoft.com/v1.0.
+func graphBaseUrlForVersion(baseUrl string, apiVersion string) string {
+	baseUrl = strings.TrimSuffix(baseUrl, "/")
+	return baseUrl[:strings.LastIndex(baseUrl, "/")+1] + apiVersion
+}
+
+//// HYDRATE FUNCTIONS
+
+// getGraphBetaProperties returns the properties of an object which are not
+// part of the v1.0 models of the Graph SDK, which is where the properties only
+// returned by the beta endpoint are decoded to. Tables queried with v1.0 have
+// none.
+func getGraphBetaProperties(_ context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	if !usesGraphBeta(d) {
+		return nil, nil
+	}
+
+	item, ok := h.Item.(interface {
+		GetAdditionalData() map[string]interface{}
+	})
+	if !ok {
+		return nil, nil
+	}
+
+	properties := map[string]interface{}{}
+	for name, value := range item.GetAdditionalData() {
+		// OData annotations, e.g. @odata.type, are not properties
+		if strings.HasPrefix(name, "@") || strings.Contains(name, "@odata.") {
+			continue
+		}
+		properties[name] = value
+	}
+	if len(properties) == 0 {
+		return nil, nil
+	}
+
+	return properties, nil
+}
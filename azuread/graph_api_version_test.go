@@ -0,0 +1,114 @@
+package azuread
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+func TestGetGraphApiVersion(t *testing.T) {
+	cases := []struct {
+		config azureADConfig
+		table  string
+		want   string
+	}{
+		{azureADConfig{}, "azuread_user", graphApiVersionV1},
+		{azureADConfig{GraphApiVersion: stringPtr("v1.0")}, "azuread_user", graphApiVersionV1},
+		{azureADConfig{GraphApiVersion: stringPtr("beta")}, "azuread_group", graphApiVersionBeta},
+		{azureADConfig{BetaTables: []string{"azuread_user"}}, "azuread_user", graphApiVersionBeta},
+		{azureADConfig{BetaTables: []string{"azuread_user"}}, "azuread_group", graphApiVersionV1},
+	}
+
+	for _, tc := range cases {
+		if got := getGraphApiVersion(tc.config, tc.table); got != tc.want {
+			t.Errorf("%s with %+v: expected %s, got %s", tc.table, tc.config, tc.want, got)
+		}
+	}
+}
+
+func TestValidateGraphApiVersion(t *testing.T) {
+	tables := map[string]*plugin.Table{"azuread_user": {}, "azuread_group": {}}
+
+	if err := validateGraphApiVersion(azureADConfig{GraphApiVersion: stringPtr("beta"), BetaTables: []string{"azuread_user"}}, tables); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	cases := []struct {
+		config azureADConfig
+		want   string
+	}{
+		{azureADConfig{GraphApiVersion: stringPtr("v2.0")}, "invalid graph_api_version"},
+		{azureADConfig{BetaTables: []string{"azuread_users", "azuread_group"}}, "unknown tables: azuread_users"},
+	}
+	for _, tc := range cases {
+		if err := validateGraphApiVersion(tc.config, tables); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("expected an error containing %q, got %v", tc.want, err)
+		}
+	}
+}
+
+func TestBetaTableQueriesBetaEndpoint(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("users", map[string]interface{}{
+		"id":                "u1",
+		"displayName":       "Ada Lovelace",
+		"userPrincipalName": "ada@example.com",
+		"signInActivity": map[string]interface{}{
+			"lastSignInDateTime":               "2026-10-01T08:00:00Z",
+			"lastSuccessfulSignInDateTime":     "2026-10-01T08:00:00Z",
+			"lastSuccessfulSignInRequestId":    "r1",
+			"lastNonInteractiveSignInDateTime": "2026-10-02T08:00:00Z",
+		},
+		"deviceKeys": []interface{}{
+			map[string]interface{}{"deviceId": "d1", "keyType": "NGC"},
+		},
+	})
+
+	columns := []string{"id", "sign_in_activity", "beta_properties"}
+	rows, err := executeConnectionQuery(t, testBetaConnectionName, "azuread_user", columns, nil)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, got %d", len(rows))
+	}
+
+	requests := standIn.requestsFor("users")
+	if len(requests) == 0 || !strings.HasPrefix(requests[0].URL.Path, "/beta/") {
+		t.Fatalf("expected the beta endpoint to be queried, got %v", requests)
+	}
+	if got := requests[0].URL.Query().Get("$select"); got != "id,signInActivity" {
+		t.Errorf("unexpected $select %q", got)
+	}
+
+	var signInActivity map[string]interface{}
+	if err := json.Unmarshal(rows[0]["sign_in_activity"].GetJsonValue(), &signInActivity); err != nil {
+		t.Fatalf("invalid sign_in_activity: %v", err)
+	}
+	if signInActivity["lastSuccessfulSignInRequestId"] != "r1" || signInActivity["lastSignInDateTime"] == nil {
+		t.Errorf("unexpected sign_in_activity %v", signInActivity)
+	}
+	if got := string(rows[0]["beta_properties"].GetJsonValue()); got != `{"deviceKeys":[{"deviceId":"d1","keyType":"NGC"}]}` {
+		t.Errorf("unexpected beta_properties %s", got)
+	}
+
+	// Other connections query the v1.0 endpoint, without the beta-only properties
+	standIn.reset()
+	standIn.setCollection("users", map[string]interface{}{"id": "u1", "deviceKeys": []interface{}{}})
+	rows, err = executeQuery(t, "azuread_user", columns, nil)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	requests = standIn.requestsFor("users")
+	if len(requests) == 0 || !strings.HasPrefix(requests[0].URL.Path, "/v1.0/") {
+		t.Fatalf("expected the v1.0 endpoint to be queried, got %v", requests)
+	}
+	if got := requests[0].URL.Query().Get("$select"); got != "id" {
+		t.Errorf("unexpected $select %q", got)
+	}
+	if len(rows) != 1 || rows[0]["beta_properties"].GetJsonValue() != nil {
+		t.Errorf("expected no beta_properties, got %v", rows)
+	}
+}
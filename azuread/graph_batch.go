@@ -0,0 +1,338 @@
+package azuread
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	"github.com/microsoft/kiota-abstractions-go/serialization"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+const (
+	// graphBatchMaxSteps is the maximum number of requests in a Graph JSON batch
+	graphBatchMaxSteps = 20
+	// graphBatchWindow is how long a request waits for others to share its batch
+	graphBatchWindow = 10 * time.Millisecond
+)
+
+// maxBatchedHydrateConcurrency is the maximum number of concurrent calls for
+// hydrate functions whose per-row requests are sent through the graphBatcher,
+// e.g. getAdGroupMembers. It is enough to fill a batch.
+const maxBatchedHydrateConcurrency = graphBatchMaxSteps
+
+// graphBatcher coalesces the concurrent requests of the per-row hydrate
+// functions of a connection into Graph JSON batches, i.e. POST $batch, of up
+// to graphBatchMaxSteps requests, and splits the responses back out to the
+// callers.
+//
+// A batch is sent through the adapter like any other request, so it is
+// authenticated and retried as a whole. It counts as each of its requests
+// against the rate limits of the connection, which also cap its size, see
+// getGraphBatchMaxSteps. The requests in it are throttled individually by
+// Graph though, so they are retried by get rather than by the retryHandler.
+//
+// When cassettes are recorded or replayed, the requests are sent one by one
+// instead, since the ids of the requests in a batch differ on every run and
+// its response could not be matched to them on replay.
+type graphBatcher struct {
+	adapter *msgraphsdkgo.GraphRequestAdapter
+	retry   *retryHandler
+	// maxSteps is the maximum number of requests in a batch
+	maxSteps int
+	// unbatched is set to send every request on its own
+	unbatched bool
+
+	mu      sync.Mutex
+	pending []*graphBatchStep
+	// timer sends the pending steps when the batch window ends, nil if there are none
+	timer *time.Timer
+}
+
+// graphBatchStep is a request waiting for its response in a batch.
+type graphBatchStep struct {
+	ctx     context.Context
+	request *abstractions.RequestInformation
+	// done receives the response of the request, or the error sending its batch
+	done chan graphBatchResult
+}
+
+type graphBatchResult struct {
+	item msgraphcore.BatchItem
+	err  error
+}
+
+func newGraphBatcher(adapter *msgraphsdkgo.GraphRequestAdapter, retry *retryHandler, maxSteps int, unbatched bool) *graphBatcher {
+	return &graphBatcher{
+		adapter:   adapter,
+		retry:     retry,
+		maxSteps:  maxSteps,
+		unbatched: unbatched,
+	}
+}
+
+// getGraphBatchMaxSteps returns the maximum number of requests in a batch of
+// the connection, so that a batch never counts as more requests than
+// max_requests_per_second allows at once or than max_concurrent_requests.
+func getGraphBatchMaxSteps(config azureADConfig) int {
+	maxSteps := graphBatchMaxSteps
+
+	requestsPerSecond, maxConcurrentRequests := getRateLimitHandlerConfig(config)
+	if requestsPerSecond > 0 {
+		maxSteps = min(maxSteps, rateLimitBurst(requestsPerSecond))
+	}
+	if maxConcurrentRequests > 0 {
+		maxSteps = min(maxSteps, maxConcurrentRequests)
+	}
+	return maxSteps
+}
+
+// batchGet sends a GET request of a per-row hydrate function in a batch with
+// the concurrent requests of the other rows, see graphBatcher.get.
+func batchGet[T serialization.Parsable](ctx context.Context, d *plugin.QueryData, request *abstractions.RequestInformation, constructor serialization.ParsableFactory) (T, error) {
+	var result T
+
+	batcher, err := getGraphBatcher(ctx, d)
+	if err != nil {
+		return result, err
+	}
+
+	value, err := batcher.get(ctx, request, constructor)
+	if err != nil {
+		return result, err
+	}
+	if value == nil {
+		return result, errors.New("empty batch response")
+	}
+
+	result, ok := value.(T)
+	if !ok {
+		return result, fmt.Errorf("unexpected batch response type %T", value)
+	}
+	return result, nil
+}
+
+// get sends a request as a step of the next batch and returns its response
+// parsed with the constructor. A throttled or unavailable request is sent
+// again in a later batch after the delay the retryHandler would wait, and a
+// failed request returns an *odataerrors.ODataError like the Graph client.
+func (b *graphBatcher) get(ctx context.Context, request *abstractions.RequestInformation, constructor serialization.ParsableFactory) (value serialization.Parsable, err error) {
+	if b.unbatched {
+		// Sent like any other request, so it is traced and retried by the middlewares
+		return b.adapter.Send(ctx, request, constructor, abstractions.ErrorMappings{
+			"XXX": odataerrors.CreateODataErrorFromDiscriminatorValue,
+		})
+	}
+
+	path := request.UrlTemplate
+	if uri, err := request.GetUri(); err == nil {
+		path = uri.Path
+	}
+	telemetry := getGraphTelemetry(ctx)
+	ctx, span := telemetry.startRequestSpan(ctx, request.Method.String(), path, true)
+	status, retries := 0, 0
+	defer func() {
+		endRequestSpan(span, status, retries, err)
+	}()
+
+	for attempt := 1; ; attempt++ {
+		step := b.add(ctx, request)
+
+		var result graphBatchResult
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case result = <-step.done:
+		}
+		if result.err != nil {
+			return nil, result.err
+		}
+
+		status = batchItemStatus(result.item)
+		if !isRetryableStatusCode(status) || attempt > b.retry.maxRetries {
+			return b.parse(result.item, constructor)
+		}
+
+		delay := b.retry.retryDelay(batchItemHeader(result.item, "Retry-After"), attempt)
+		plugin.Logger(ctx).Warn("graphBatcher.get", "status_code", status, "url", batchItemUrl(step), "attempt", attempt, "delay", delay.String())
+		telemetry.recordRetry(ctx, status, attempt, delay)
+		retries = attempt
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// add queues a request for the next batch, which is sent when it is full or
+// when the batch window ends.
+func (b *graphBatcher) add(ctx context.Context, request *abstractions.RequestInformation) *graphBatchStep {
+	step := &graphBatchStep{
+		ctx:     ctx,
+		request: request,
+		done:    make(chan graphBatchResult, 1),
+	}
+
+	b.mu.Lock()
+	b.pending = append(b.pending, step)
+	var steps []*graphBatchStep
+	if len(b.pending) >= b.maxSteps {
+		steps = b.takePending()
+	} else if b.timer == nil {
+		b.timer = time.AfterFunc(graphBatchWindow, b.flush)
+	}
+	b.mu.Unlock()
+
+	if steps != nil {
+		go b.send(steps)
+	}
+	return step
+}
+
+// flush sends the pending steps at the end of the batch window.
+func (b *graphBatcher) flush() {
+	b.mu.Lock()
+	steps := b.takePending()
+	b.mu.Unlock()
+
+	if len(steps) > 0 {
+		b.send(steps)
+	}
+}
+
+// takePending returns the pending steps and starts a new batch. The caller must hold b.mu.
+func (b *graphBatcher) takePending() []*graphBatchStep {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	steps := b.pending
+	b.pending = nil
+	return steps
+}
+
+// send sends the steps as a batch and delivers the response of each of them.
+func (b *graphBatcher) send(steps []*graphBatchStep) {
+	batch := msgraphcore.NewBatchRequest(b.adapter)
+	stepsById := map[string]*graphBatchStep{}
+	for _, step := range steps {
+		// The caller has already given up on the request
+		if step.ctx.Err() != nil {
+			continue
+		}
+		item, err := batch.AddBatchRequestStep(*step.request)
+		if err != nil {
+			step.done <- graphBatchResult{err: err}
+			continue
+		}
+		stepsById[*item.GetId()] = step
+	}
+	if len(stepsById) == 0 {
+		return
+	}
+
+	// The batch is shared by several callers, so it is not canceled with the
+	// first of them, whose context is only used for its values, e.g. the logger,
+	// and is not attributed to its hydrate call
+	var ctx context.Context
+	for _, step := range stepsById {
+		ctx = withoutGraphTelemetry(context.WithoutCancel(step.ctx))
+		break
+	}
+	ctx = withGraphRequestWeight(ctx, len(stepsById))
+
+	plugin.Logger(ctx).Debug("graphBatcher.send", "requests", len(stepsById))
+	response, err := batch.Send(ctx, b.adapter)
+	for id, step := range stepsById {
+		if err != nil {
+			step.done <- graphBatchResult{err: err}
+			continue
+		}
+		item := response.GetResponseById(id)
+		if item == nil || item.GetStatus() == nil {
+			step.done <- graphBatchResult{err: fmt.Errorf("no response for request %s of the batch", id)}
+			continue
+		}
+		step.done <- graphBatchResult{item: item}
+	}
+}
+
+// parse returns the body of a response in a batch parsed with the
+// constructor, or the error of a failed response.
+func (b *graphBatcher) parse(item msgraphcore.BatchItem, constructor serialization.ParsableFactory) (serialization.Parsable, error) {
+	status := batchItemStatus(item)
+	if item.GetBody() == nil {
+		if status >= http.StatusBadRequest {
+			return nil, &abstractions.ApiError{
+				Message:            fmt.Sprintf("the batch request failed with status code %d and no response body", status),
+				ResponseStatusCode: status,
+			}
+		}
+		return nil, nil
+	}
+
+	// The response bodies are returned as JSON objects within the batch response,
+	// and parsed with the factories registered by the Graph request adapter
+	content, err := json.Marshal(item.GetBody())
+	if err != nil {
+		return nil, err
+	}
+	parseNode, err := serialization.DefaultParseNodeFactoryInstance.GetRootParseNode("application/json", content)
+	if err != nil {
+		return nil, err
+	}
+
+	if status >= http.StatusBadRequest {
+		value, err := parseNode.GetObjectValue(odataerrors.CreateODataErrorFromDiscriminatorValue)
+		if err != nil {
+			return nil, err
+		}
+		oDataError, ok := value.(*odataerrors.ODataError)
+		if !ok {
+			return nil, errors.New("invalid error response in the batch")
+		}
+		oDataError.ResponseStatusCode = status
+		return nil, oDataError
+	}
+
+	return parseNode.GetObjectValue(constructor)
+}
+
+func batchItemStatus(item msgraphcore.BatchItem) int {
+	if item.GetStatus() == nil {
+		return 0
+	}
+	return int(*item.GetStatus())
+}
+
+// batchItemHeader returns a header of a response in a batch, whose names are case-insensitive.
+func batchItemHeader(item msgraphcore.BatchItem, name string) string {
+	for k, v := range item.GetHeaders() {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+// batchItemUrl returns the URL of the request of a step for logging.
+func batchItemUrl(step *graphBatchStep) string {
+	uri, err := step.request.GetUri()
+	if err != nil {
+		return step.request.UrlTemplate
+	}
+	return uri.Redacted()
+}
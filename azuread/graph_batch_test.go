@@ -0,0 +1,205 @@
+package azuread
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestBatchedHydrates(t *testing.T) {
+	standIn.reset()
+	standIn.setPageSize(100)
+
+	var groups []map[string]interface{}
+	for i := 1; i <= 25; i++ {
+		id := fmt.Sprintf("g%d", i)
+		groups = append(groups, map[string]interface{}{"id": id})
+		standIn.setCollection("groups/"+id+"/members", map[string]interface{}{"@odata.type": "#microsoft.graph.user", "id": "u" + id})
+	}
+	standIn.setCollection("groups", groups...)
+
+	rows, err := executeQuery(t, "azuread_group", []string{"id", "member_ids"}, nil)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(rows) != 25 {
+		t.Fatalf("expected 25 rows, got %d", len(rows))
+	}
+	for _, row := range rows {
+		id := row["id"].GetStringValue()
+		if got := string(row["member_ids"].GetJsonValue()); got != fmt.Sprintf(`["u%s"]`, id) {
+			t.Errorf("%s: unexpected member_ids %s", id, got)
+		}
+		if got := len(standIn.requestsFor("groups/" + id + "/members")); got != 1 {
+			t.Errorf("%s: expected 1 members request, got %d", id, got)
+		}
+	}
+
+	// 25 requests need at least 2 batches, the rows may be split over a few more
+	if got := len(standIn.requestsFor("$batch")); got < 2 || got > 4 {
+		t.Errorf("expected 2 to 4 batches, got %d", got)
+	}
+}
+
+func TestBatchRetriesThrottledRequests(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("groups", map[string]interface{}{"id": "g1"})
+	standIn.setCollection("groups/g1/members", map[string]interface{}{"@odata.type": "#microsoft.graph.user", "id": "u1"})
+	standIn.setThrottled("groups/g1/members", 2, "0")
+
+	rows, err := executeQuery(t, "azuread_group", []string{"id", "member_ids"}, nil)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(rows) != 1 || string(rows[0]["member_ids"].GetJsonValue()) != `["u1"]` {
+		t.Fatalf("unexpected rows %v", rows)
+	}
+	if got := len(standIn.requestsFor("groups/g1/members")); got != 3 {
+		t.Errorf("expected 3 members requests, got %d", got)
+	}
+	if got := len(standIn.requestsFor("$batch")); got != 3 {
+		t.Errorf("expected 3 batches, got %d", got)
+	}
+}
+
+func TestBatchRequestError(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("applications", map[string]interface{}{"id": "a1"}, map[string]interface{}{"id": "a2"})
+	standIn.setCollection("applications/a1/owners")
+	standIn.setError("applications/a2/owners", 403, "Authorization_RequestDenied", "Insufficient privileges to complete the operation.")
+
+	_, err := executeQuery(t, "azuread_application", []string{"id", "owner_ids"}, nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"missing permission", "Authorization_RequestDenied", "Insufficient privileges"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got %q", want, err.Error())
+		}
+	}
+}
+
+func TestBatchedHydratesRecordAndReplay(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("groups", map[string]interface{}{"id": "g1"}, map[string]interface{}{"id": "g2"})
+	standIn.setCollection("groups/g1/members", map[string]interface{}{"@odata.type": "#microsoft.graph.user", "id": "u1"})
+	standIn.setCollection("groups/g2/members", map[string]interface{}{"@odata.type": "#microsoft.graph.user", "id": "u2"})
+
+	dir := t.TempDir()
+	var connections []*proto.ConnectionConfig
+	for _, mode := range []string{cassetteModeRecord, cassetteModeReplay} {
+		connections = append(connections, &proto.ConnectionConfig{
+			Connection: "azuread_batch_" + mode,
+			Plugin:     pluginName,
+			Config:     fmt.Sprintf("tenant_id = %q\ncassette_mode = %q\ncassette_dir = %q", testTenantID, mode, dir),
+		})
+	}
+	if _, err := pluginServer.UpdateConnectionConfigs(&proto.UpdateConnectionConfigsRequest{Added: connections}); err != nil {
+		t.Fatalf("adding the connections failed: %v", err)
+	}
+	defer pluginServer.UpdateConnectionConfigs(&proto.UpdateConnectionConfigsRequest{Deleted: connections})
+
+	memberIds := func(connection string) map[string]string {
+		t.Helper()
+		rows, err := executeConnectionQuery(t, connection, "azuread_group", []string{"id", "member_ids"}, nil)
+		if err != nil {
+			t.Fatalf("%s: list failed: %v", connection, err)
+		}
+		ids := map[string]string{}
+		for _, row := range rows {
+			ids[row["id"].GetStringValue()] = string(row["member_ids"].GetJsonValue())
+		}
+		return ids
+	}
+
+	recorded := memberIds(connections[0].Connection)
+	if recorded["g1"] != `["u1"]` || recorded["g2"] != `["u2"]` {
+		t.Fatalf("unexpected recorded member_ids %v", recorded)
+	}
+	if got := len(standIn.requestsFor("$batch")); got != 0 {
+		t.Errorf("expected no batches while recording, got %d", got)
+	}
+
+	// Replay with nothing served by the stand-in server
+	standIn.reset()
+	if replayed := memberIds(connections[1].Connection); replayed["g1"] != recorded["g1"] || replayed["g2"] != recorded["g2"] {
+		t.Errorf("expected the recorded member_ids %v, got %v", recorded, replayed)
+	}
+	if got := len(standIn.requestsFor("groups")) + len(standIn.requestsFor("groups/g1/members")) + len(standIn.requestsFor("groups/g2/members")); got != 0 {
+		t.Errorf("expected no requests in replay mode, got %d", got)
+	}
+}
+
+func TestBatchCountsAgainstRateLimits(t *testing.T) {
+	standIn.reset()
+	standIn.setPageSize(100)
+
+	var groups []map[string]interface{}
+	for i := 1; i <= 12; i++ {
+		id := fmt.Sprintf("g%d", i)
+		groups = append(groups, map[string]interface{}{"id": id})
+		standIn.setCollection("groups/"+id+"/members", map[string]interface{}{"@odata.type": "#microsoft.graph.user", "id": "u" + id})
+	}
+	standIn.setCollection("groups", groups...)
+
+	connection := &proto.ConnectionConfig{
+		Connection: "azuread_batch_limited",
+		Plugin:     pluginName,
+		Config:     fmt.Sprintf("tenant_id = %q\nmax_concurrent_requests = 5", testTenantID),
+	}
+	if _, err := pluginServer.UpdateConnectionConfigs(&proto.UpdateConnectionConfigsRequest{Added: []*proto.ConnectionConfig{connection}}); err != nil {
+		t.Fatalf("adding the connection failed: %v", err)
+	}
+	defer pluginServer.UpdateConnectionConfigs(&proto.UpdateConnectionConfigsRequest{Deleted: []*proto.ConnectionConfig{connection}})
+
+	// A batch holds no more requests than may be sent concurrently
+	rows, err := executeConnectionQuery(t, connection.Connection, "azuread_group", []string{"id", "member_ids"}, nil)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(rows) != 12 {
+		t.Fatalf("expected 12 rows, got %d", len(rows))
+	}
+	if got := len(standIn.requestsFor("$batch")); got < 3 {
+		t.Errorf("expected at least 3 batches, got %d", got)
+	}
+
+	requestsPerSecond, maxConcurrentRequests := 2.5, 10
+	if got := getGraphBatchMaxSteps(azureADConfig{MaxRequestsPerSecond: &requestsPerSecond, MaxConcurrentRequests: &maxConcurrentRequests}); got != 3 {
+		t.Errorf("expected batches of 3 requests, got %d", got)
+	}
+	if got := getGraphBatchMaxSteps(azureADConfig{}); got != graphBatchMaxSteps {
+		t.Errorf("expected batches of %d requests, got %d", graphBatchMaxSteps, got)
+	}
+
+	// A batch takes a token of the rate and a concurrency slot for each of its requests
+	handler := newRateLimitHandler(10, 5)
+	req, err := http.NewRequestWithContext(withGraphRequestWeight(context.Background(), 4), http.MethodPost, standIn.baseUrl()+"/$batch", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var inFlight int
+	if _, err := handler.Intercept(testPipeline(func(*http.Request) (*http.Response, error) {
+		inFlight = len(handler.inFlight)
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}), 0, req); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if inFlight != 4 || len(handler.inFlight) != 0 {
+		t.Errorf("expected 4 concurrency slots taken and released, got %d and %d", inFlight, len(handler.inFlight))
+	}
+	if tokens := handler.limiter.Tokens(); tokens < 5.5 || tokens > 6.5 {
+		t.Errorf("expected 6 of the 10 tokens to be left, got %.2f", tokens)
+	}
+}
+
+// testPipeline is a khttp.Pipeline which sends requests with a function.
+type testPipeline func(req *http.Request) (*http.Response, error)
+
+func (p testPipeline) Next(req *http.Request, _ int) (*http.Response, error) {
+	return p(req)
+}
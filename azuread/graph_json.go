@@ -0,0 +1,41 @@
+package azuread
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+)
+
+// getGraphJSON sends a GET request for a Graph URL through the adapter and
+// decodes the JSON response into v, for responses which are kept as returned
+// rather than parsed into the models of the Graph SDK. Failed requests return
+// an *odataerrors.ODataError like the Graph client.
+func getGraphJSON(ctx context.Context, adapter abstractions.RequestAdapter, rawUrl string, v interface{}) error {
+	uri, err := url.Parse(rawUrl)
+	if err != nil {
+		return err
+	}
+
+	requestInfo := abstractions.NewRequestInformation()
+	requestInfo.Method = abstractions.GET
+	requestInfo.SetUri(*uri)
+	requestInfo.Headers.Add("Accept", "application/json")
+
+	errorMapping := abstractions.ErrorMappings{
+		"XXX": odataerrors.CreateODataErrorFromDiscriminatorValue,
+	}
+	response, err := adapter.SendPrimitive(ctx, requestInfo, "[]byte", errorMapping)
+	if err != nil {
+		return err
+	}
+	content, _ := response.([]byte)
+
+	if err := json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("invalid response from %s: %v", uri.Redacted(), err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package azuread
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// graphSelectProperties maps the columns of a table which are not read with a
+// Get method of the Graph model, e.g. title or tags, to the Graph properties
+// they are built from. Columns mapped to no properties, e.g. a column copied
+// from a key column qual, need none.
+//
+// Each table which selects properties declares its columns in a
+// <table>SelectProperties variable next to its definition, e.g.
+// userSelectProperties, and passes it to buildSelect or selectProperties.
+// Columns read with a Get method or with their own hydrate are worked out
+// from the column definitions and are not listed.
+type graphSelectProperties map[string][]string
+
+// buildSelect returns the $select of the list and get requests of a table for
+// the columns of the query, see selectProperties. The properties are not
+// restricted when the beta properties are requested from the beta endpoint,
+// since they are whatever it returns by default.
+func buildSelect(d *plugin.QueryData, properties graphSelectProperties) []string {
+	if usesGraphBeta(d) && helpers.StringSliceContains(d.QueryContext.Columns, "beta_properties") {
+		return nil
+	}
+	return selectProperties(d.Table.Columns, d.QueryContext.Columns, properties)
+}
+
+// selectProperties returns the Graph properties the query columns are built
+// from: the properties given for the column, or the property read by a
+// transform.FromMethod("GetX") column, i.e. x. Columns with their own hydrate
+// need no property but the id of the object they are fetched for.
+//
+// It returns nil, i.e. no $select and so the default properties, if a column
+// is built from properties which can't be worked out.
+func selectProperties(columns []*plugin.Column, queryColumns []string, properties graphSelectProperties) []string {
+	columnMap := map[string]*plugin.Column{}
+	for _, c := range columns {
+		columnMap[c.Name] = c
+	}
+
+	var selectColumns []string
+	add := func(names ...string) {
+		for _, name := range names {
+			if !helpers.StringSliceContains(selectColumns, name) {
+				selectColumns = append(selectColumns, name)
+			}
+		}
+	}
+
+	for _, columnName := range queryColumns {
+		if names, ok := properties[columnName]; ok {
+			add(names...)
+			continue
+		}
+
+		column := columnMap[columnName]
+		if column == nil {
+			continue
+		}
+		if column.Hydrate != nil {
+			add("id")
+			continue
+		}
+
+		name, ok := columnProperty(column)
+		if !ok {
+			return nil
+		}
+		if name != "" {
+			add(name)
+		}
+	}
+
+	return selectColumns
+}
+
+// columnProperty returns the Graph property a column is read from, "" if it
+// is read from a qual, or false if it can't be worked out.
+func columnProperty(column *plugin.Column) (string, bool) {
+	if column.Transform == nil || len(column.Transform.Transforms) == 0 {
+		return "", false
+	}
+	call := column.Transform.Transforms[0]
+
+	switch reflect.ValueOf(call.Transform).Pointer() {
+	case reflect.ValueOf(transform.QualValue).Pointer():
+		return "", true
+	case reflect.ValueOf(transform.MethodValue).Pointer():
+		method, _ := call.Param.(string)
+		name, ok := strings.CutPrefix(method, "Get")
+		if !ok || name == "" {
+			return "", false
+		}
+		// Graph properties are the getter names with a lower case first letter,
+		// e.g. GetAllowedToUseSSPR reads allowedToUseSSPR
+		r, size := utf8.DecodeRuneInString(name)
+		return string(unicode.ToLower(r)) + name[size:], true
+	}
+
+	return "", false
+}
@@ -0,0 +1,104 @@
+package azuread
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func TestSelectProperties(t *testing.T) {
+	columns := commonColumns([]*plugin.Column{
+		{Name: "id", Type: proto.ColumnType_STRING, Transform: transform.FromMethod("GetId")},
+		{Name: "display_name", Type: proto.ColumnType_STRING, Transform: transform.FromMethod("GetDisplayName")},
+		{Name: "allowed_to_use_sspr", Type: proto.ColumnType_BOOL, Transform: transform.FromMethod("GetAllowedToUseSSPR")},
+		{Name: "filter", Type: proto.ColumnType_STRING, Transform: transform.FromQual("filter")},
+		{Name: "owner_ids", Type: proto.ColumnType_JSON, Hydrate: getAdGroupOwners, Transform: transform.FromValue()},
+		{Name: "api", Type: proto.ColumnType_JSON, Transform: transform.FromMethod("ApplicationAPI")},
+		{Name: "client_id", Type: proto.ColumnType_STRING},
+		{Name: "title", Type: proto.ColumnType_STRING, Transform: transform.From(adGroupTitle)},
+	})
+	properties := graphSelectProperties{
+		"api":   {"api"},
+		"title": {"displayName", "id"},
+	}
+
+	cases := []struct {
+		columns []string
+		want    []string
+	}{
+		{[]string{"display_name", "filter", "tenant_id"}, []string{"displayName", "id"}},
+		{[]string{"allowed_to_use_sspr"}, []string{"allowedToUseSSPR"}},
+		{[]string{"display_name", "title", "api"}, []string{"displayName", "id", "api"}},
+		{[]string{"owner_ids"}, []string{"id"}},
+		// The property of client_id can't be worked out, so every property is returned
+		{[]string{"id", "client_id"}, nil},
+		{[]string{"id", "title"}, []string{"id", "displayName"}},
+	}
+
+	for _, tc := range cases {
+		if got := selectProperties(columns, tc.columns, properties); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%v: expected %v, got %v", tc.columns, tc.want, got)
+		}
+	}
+}
+
+// Every column of the tables projected with $select must be either read with
+// a Get method or mapped to its properties, otherwise select * queries fall
+// back to the default properties.
+func TestSelectPropertiesCoverColumns(t *testing.T) {
+	tables := []struct {
+		table      *plugin.Table
+		properties graphSelectProperties
+	}{
+		{tableAzureAdApplication(context.Background()), applicationSelectProperties},
+		{tableAzureAdApplicationAppRoleAssignment(context.Background()), applicationAppRoleAssignedToSelectProperties},
+		{tableAzureAdConditionalAccessPolicy(context.Background()), conditionalAccessPolicySelectProperties},
+		{tableAzureAdDevice(context.Background()), deviceSelectProperties},
+		{tableAzureAdDirectoryAuditReport(context.Background()), directoryAuditSelectProperties},
+		{tableAzureAdDirectoryRole(context.Background()), directoryRoleSelectProperties},
+		{tableAzureAdDomain(context.Background()), nil},
+		{tableAzureAdGroup(context.Background()), groupSelectProperties},
+		{tableAzureAdGroupAppRoleAssignment(context.Background()), nil},
+		{tableAzureAdServicePrincipal(context.Background()), servicePrincipalSelectProperties},
+		{tableAzureAdServicePrincipalAppRoleAssignedTo(context.Background()), nil},
+		{tableAzureAdServicePrincipalAppRoleAssignment(context.Background()), nil},
+		{tableAzureAdSignInReport(context.Background()), signInReportSelectProperties},
+		{tableAzureAdUser(context.Background()), userSelectProperties},
+		{tableAzureAdUserAppRoleAssignment(context.Background()), userAppRoleAssignmentSelectProperties},
+	}
+
+	for _, tc := range tables {
+		var columns []string
+		for _, c := range tc.table.Columns {
+			columns = append(columns, c.Name)
+		}
+		if got := selectProperties(tc.table.Columns, columns, tc.properties); len(got) == 0 {
+			t.Errorf("%s: expected every column to be projected", tc.table.Name)
+		}
+	}
+}
+
+func TestSelectPushdown(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("groups", map[string]interface{}{"id": "g1", "displayName": "Finance"})
+
+	rows, err := executeQuery(t, "azuread_group", []string{"display_name", "title", "tags"}, nil)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(rows) != 1 || rows[0]["title"].GetStringValue() != "Finance" {
+		t.Fatalf("unexpected rows %v", rows)
+	}
+
+	requests := standIn.requestsFor("groups")
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+	if got := requests[0].URL.Query().Get("$select"); got != "displayName,id,assignedLabels" {
+		t.Errorf("unexpected $select %q", got)
+	}
+}
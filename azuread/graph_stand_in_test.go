@@ -0,0 +1,328 @@
+package azuread
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// graphStandIn is an in-process stand-in for the Microsoft Graph API. The
+// v1.0 and beta endpoints serve the same data.
+//
+// It serves:
+//   - collections, paged with $top and @odata.nextLink, e.g. "users" or "groups/<id>/members"
+//   - members of a collection by id, e.g. "users/<id>"
+//   - single objects, e.g. "policies/authorizationPolicy"
+//   - OData error bodies for paths registered with setError
+//   - JSON batches posted to "$batch", whose requests are served like any other
+//   - delta queries of collections, e.g. "groups/delta", which return the
+//     collection, and the changes registered with setDelta for a delta link
+//
+// Every request is recorded so tests can assert on the query parameters sent by the plugin.
+type graphStandIn struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	pageSize    int
+	collections map[string][]map[string]interface{}
+	objects     map[string]map[string]interface{}
+	deltas      map[string][]map[string]interface{}
+	errors      map[string]*graphStandInError
+	requests    []*http.Request
+}
+
+type graphStandInError struct {
+	status     int
+	code       string
+	message    string
+	retryAfter string
+	// remaining is the number of times the error is returned before the path
+	// is served normally again, or 0 if the error is permanent
+	remaining int
+}
+
+const graphStandInDefaultPageSize = 2
+
+func newGraphStandIn() *graphStandIn {
+	s := &graphStandIn{}
+	s.reset()
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// baseUrl returns the URL to use as the base URL of the Graph request adapter.
+func (s *graphStandIn) baseUrl() string {
+	return s.URL + "/v1.0"
+}
+
+// reset removes all data, errors and recorded requests.
+func (s *graphStandIn) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pageSize = graphStandInDefaultPageSize
+	s.collections = map[string][]map[string]interface{}{}
+	s.objects = map[string]map[string]interface{}{}
+	s.deltas = map[string][]map[string]interface{}{}
+	s.errors = map[string]*graphStandInError{}
+	s.requests = nil
+}
+
+func (s *graphStandIn) setCollection(path string, items ...map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.collections[path] = items
+}
+
+func (s *graphStandIn) setObject(path string, object map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[path] = object
+}
+
+// setDelta sets the changes of a collection returned for its delta links.
+func (s *graphStandIn) setDelta(path string, changes ...map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deltas[path] = changes
+}
+
+func (s *graphStandIn) setError(path string, status int, code, message string) {
+	s.setTransientError(path, status, code, message, 0)
+}
+
+// setTransientError makes the path return an error the given number of times, or always if 0.
+func (s *graphStandIn) setTransientError(path string, status int, code, message string, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[path] = &graphStandInError{status: status, code: code, message: message, remaining: times}
+}
+
+// setThrottled makes the path return 429 Too Many Requests the given number of times.
+func (s *graphStandIn) setThrottled(path string, times int, retryAfter string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[path] = &graphStandInError{
+		status:     http.StatusTooManyRequests,
+		code:       "TooManyRequests",
+		message:    "Too many requests.",
+		retryAfter: retryAfter,
+		remaining:  times,
+	}
+}
+
+func (s *graphStandIn) setPageSize(pageSize int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pageSize = pageSize
+}
+
+// requestsFor returns the recorded requests for the given path.
+func (s *graphStandIn) requestsFor(path string) []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var requests []*http.Request
+	for _, r := range s.requests {
+		if graphStandInPath(r) == path {
+			requests = append(requests, r)
+		}
+	}
+	return requests
+}
+
+// graphStandInPath returns the path of a request without the API version.
+func graphStandInPath(r *http.Request) string {
+	for _, apiVersion := range []string{graphApiVersionV1, graphApiVersionBeta} {
+		if path, ok := strings.CutPrefix(r.URL.Path, "/"+apiVersion+"/"); ok {
+			return path
+		}
+	}
+	return strings.TrimPrefix(r.URL.Path, "/")
+}
+
+func (s *graphStandIn) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r)
+	if r.Method == http.MethodPost && graphStandInPath(r) == "$batch" {
+		s.serveBatch(w, r)
+		return
+	}
+	s.serve(w, r)
+}
+
+// serve writes the response for a request. The caller must hold s.mu.
+func (s *graphStandIn) serve(w http.ResponseWriter, r *http.Request) {
+	path := graphStandInPath(r)
+
+	if e, ok := s.errors[path]; ok {
+		if e.remaining > 0 {
+			e.remaining--
+			if e.remaining == 0 {
+				delete(s.errors, path)
+			}
+		}
+		writeGraphStandInError(w, *e)
+		return
+	}
+
+	if items, ok := s.collections[path]; ok {
+		s.writePage(w, r, path, items)
+		return
+	}
+
+	if collection, ok := strings.CutSuffix(path, "/delta"); ok {
+		if items, ok := s.collections[collection]; ok {
+			if r.URL.Query().Has("$deltatoken") {
+				items = s.deltas[collection]
+			}
+			s.writePage(w, r, path, items)
+			return
+		}
+	}
+
+	if object, ok := s.objects[path]; ok {
+		writeGraphStandInJSON(w, http.StatusOK, object)
+		return
+	}
+
+	// Look up a member of a collection by id
+	if i := strings.LastIndex(path, "/"); i > 0 {
+		for _, item := range s.collections[path[:i]] {
+			if item["id"] == path[i+1:] {
+				writeGraphStandInJSON(w, http.StatusOK, item)
+				return
+			}
+		}
+	}
+
+	writeGraphStandInError(w, graphStandInError{
+		status:  http.StatusNotFound,
+		code:    "Request_ResourceNotFound",
+		message: fmt.Sprintf("Resource '%s' does not exist or one of its queried reference-property objects are not present.", path),
+	})
+}
+
+// serveBatch serves the requests of a JSON batch, recording each of them, and
+// writes their responses. The caller must hold s.mu.
+func (s *graphStandIn) serveBatch(w http.ResponseWriter, r *http.Request) {
+	var batch struct {
+		Requests []struct {
+			Id      string            `json:"id"`
+			Method  string            `json:"method"`
+			Url     string            `json:"url"`
+			Headers map[string]string `json:"headers"`
+		} `json:"requests"`
+	}
+	// The Graph client compresses request bodies
+	body := io.Reader(r.Body)
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			writeGraphStandInError(w, graphStandInError{status: http.StatusBadRequest, code: "BadRequest", message: err.Error()})
+			return
+		}
+		defer gz.Close()
+		body = gz
+	}
+	if err := json.NewDecoder(body).Decode(&batch); err != nil {
+		writeGraphStandInError(w, graphStandInError{status: http.StatusBadRequest, code: "BadRequest", message: err.Error()})
+		return
+	}
+
+	// The request URLs are relative to the endpoint of the batch, e.g. /v1.0/
+	base := s.URL + strings.TrimSuffix(r.URL.Path, "$batch")
+
+	responses := []map[string]interface{}{}
+	for _, step := range batch.Requests {
+		req, err := http.NewRequest(step.Method, base+strings.TrimPrefix(step.Url, "/"), nil)
+		if err != nil {
+			writeGraphStandInError(w, graphStandInError{status: http.StatusBadRequest, code: "BadRequest", message: err.Error()})
+			return
+		}
+		for k, v := range step.Headers {
+			req.Header.Set(k, v)
+		}
+		s.requests = append(s.requests, req)
+
+		recorder := httptest.NewRecorder()
+		s.serve(recorder, req)
+
+		headers := map[string]string{}
+		for k := range recorder.Header() {
+			headers[k] = recorder.Header().Get(k)
+		}
+		var body interface{}
+		_ = json.Unmarshal(recorder.Body.Bytes(), &body)
+		responses = append(responses, map[string]interface{}{
+			"id":      step.Id,
+			"status":  recorder.Code,
+			"headers": headers,
+			"body":    body,
+		})
+	}
+
+	writeGraphStandInJSON(w, http.StatusOK, map[string]interface{}{"responses": responses})
+}
+
+func (s *graphStandIn) writePage(w http.ResponseWriter, r *http.Request, path string, items []map[string]interface{}) {
+	query := r.URL.Query()
+
+	pageSize := s.pageSize
+	if top, err := strconv.Atoi(query.Get("$top")); err == nil && top > 0 && top < pageSize {
+		pageSize = top
+	}
+
+	skip, _ := strconv.Atoi(query.Get("$skiptoken"))
+	end := min(skip+pageSize, len(items))
+	if skip > end {
+		skip = end
+	}
+
+	body := map[string]interface{}{
+		"@odata.context": fmt.Sprintf("%s/v1.0/$metadata#%s", s.URL, path),
+		"value":          items[skip:end],
+	}
+	if end < len(items) {
+		next := url.Values{}
+		for k, v := range query {
+			next[k] = v
+		}
+		next.Set("$skiptoken", strconv.Itoa(end))
+		body["@odata.nextLink"] = fmt.Sprintf("%s%s?%s", s.URL, r.URL.EscapedPath(), next.Encode())
+	} else if strings.HasSuffix(path, "/delta") {
+		body["@odata.deltaLink"] = fmt.Sprintf("%s%s?$deltatoken=%d", s.URL, r.URL.EscapedPath(), len(s.requests))
+	}
+
+	writeGraphStandInJSON(w, http.StatusOK, body)
+}
+
+func writeGraphStandInError(w http.ResponseWriter, e graphStandInError) {
+	if e.retryAfter != "" {
+		w.Header().Set("Retry-After", e.retryAfter)
+	}
+	writeGraphStandInJSON(w, e.status, map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    e.code,
+			"message": e.message,
+			"innerError": map[string]interface{}{
+				"request-id": "00000000-0000-0000-0000-000000000000",
+			},
+		},
+	})
+}
+
+func writeGraphStandInJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
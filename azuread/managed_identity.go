@@ -0,0 +1,55 @@
+package azuread
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// newManagedIdentityCredential creates a managed identity credential for the
+// system-assigned identity, or for the user-assigned identity with the given
+// resource ID or client ID. The resource ID takes precedence, since the client
+// ID may come from AZURE_CLIENT_ID set for another credential.
+//
+// If endpoint is set, token requests are sent to it rather than to the Azure
+// Instance Metadata Service (IMDS) endpoint.
+func newManagedIdentityCredential(clientID, resourceID, endpoint string, clientOptions policy.ClientOptions) (azcore.TokenCredential, error) {
+	options := &azidentity.ManagedIdentityCredentialOptions{ClientOptions: clientOptions}
+
+	if resourceID != "" {
+		options.ID = azidentity.ResourceID(resourceID)
+	} else if clientID != "" {
+		options.ID = azidentity.ClientID(clientID)
+	}
+
+	if endpoint != "" {
+		endpointUrl, err := url.Parse(endpoint)
+		if err != nil || endpointUrl.Scheme == "" || endpointUrl.Host == "" {
+			return nil, fmt.Errorf("invalid msi_endpoint %q, must be an absolute URL", endpoint)
+		}
+		options.PerCallPolicies = append(options.PerCallPolicies, msiEndpointPolicy{endpointUrl})
+	}
+
+	return azidentity.NewManagedIdentityCredential(options)
+}
+
+// msiEndpointPolicy sends the token requests of a managed identity credential
+// to a custom endpoint. The credential always requests tokens from the IMDS
+// endpoint unless an identity environment variable such as IDENTITY_ENDPOINT
+// is set, so the request is redirected here while keeping its query.
+type msiEndpointPolicy struct {
+	endpoint *url.URL
+}
+
+func (p msiEndpointPolicy) Do(req *policy.Request) (*http.Response, error) {
+	raw := req.Raw()
+	raw.URL.Scheme = p.endpoint.Scheme
+	raw.URL.Host = p.endpoint.Host
+	raw.URL.Path = p.endpoint.Path
+	raw.Host = p.endpoint.Host
+	return req.Next()
+}
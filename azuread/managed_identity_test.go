@@ -0,0 +1,63 @@
+package azuread
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+func TestManagedIdentityCredential(t *testing.T) {
+	var queries []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/msi/token" || r.Header.Get("Metadata") != "true" {
+			t.Errorf("unexpected token request %s %v", r.URL.Path, r.Header)
+		}
+		queries = append(queries, r.URL.Query())
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"msi-token","expires_in":"3599","token_type":"Bearer"}`))
+	}))
+	defer server.Close()
+
+	resourceID := "/subscriptions/0/resourceGroups/rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/steampipe"
+	cases := []struct {
+		clientID   string
+		resourceID string
+		param      string
+		want       string
+	}{
+		{"", "", "client_id", ""},
+		{"app-1", "", "client_id", "app-1"},
+		{"app-1", resourceID, "mi_res_id", resourceID},
+	}
+
+	opts := policy.TokenRequestOptions{Scopes: []string{"https://graph.microsoft.com/.default"}}
+	for _, tc := range cases {
+		queries = nil
+		cred, err := newManagedIdentityCredential(tc.clientID, tc.resourceID, server.URL+"/msi/token", policy.ClientOptions{})
+		if err != nil {
+			t.Fatalf("create credential failed: %v", err)
+		}
+		token, err := cred.GetToken(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("get token failed: %v", err)
+		}
+		if token.Token != "msi-token" || len(queries) != 1 {
+			t.Fatalf("unexpected token %q after %d requests", token.Token, len(queries))
+		}
+		if got := queries[0].Get(tc.param); got != tc.want {
+			t.Errorf("expected %s %q, got %q", tc.param, tc.want, got)
+		}
+		if tc.resourceID != "" && queries[0].Get("client_id") != "" {
+			t.Errorf("expected no client_id with a resource ID, got %q", queries[0].Get("client_id"))
+		}
+	}
+
+	if _, err := newManagedIdentityCredential("", "", "169.254.169.254", policy.ClientOptions{}); err == nil {
+		t.Error("expected an error for a relative msi_endpoint")
+	}
+}
@@ -0,0 +1,127 @@
+package azuread
+
+import (
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetHttpTransportDefault(t *testing.T) {
+	transport, err := getHttpTransport(azureADConfig{})
+	if err != nil || transport != nil {
+		t.Fatalf("expected no transport, got %v (%v)", transport, err)
+	}
+}
+
+func TestGetHttpTransportProxy(t *testing.T) {
+	var proxied []*http.Request
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	proxyUrl := strings.Replace(proxy.URL, "http://", "http://auditor:s3cret@", 1)
+	transport, err := getHttpTransport(azureADConfig{
+		ProxyUrl: &proxyUrl,
+		NoProxy:  []string{".internal.example.com"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Get("http://graph.example.com/v1.0/users")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(proxied) != 1 || proxied[0].URL.Host != "graph.example.com" {
+		t.Fatalf("expected the request to go through the proxy, got %v", proxied)
+	}
+	wantAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("auditor:s3cret"))
+	if got := proxied[0].Header.Get("Proxy-Authorization"); got != wantAuth {
+		t.Errorf("unexpected Proxy-Authorization %q", got)
+	}
+
+	for _, target := range []string{"http://login.internal.example.com/token", "http://169.254.169.254/metadata/identity/oauth2/token"} {
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		if u, err := transport.Proxy(req); err != nil || u != nil {
+			t.Errorf("expected %s to bypass the proxy, got %v (%v)", target, u, err)
+		}
+	}
+
+	invalid := "proxy.example.com:8080"
+	if _, err := getHttpTransport(azureADConfig{ProxyUrl: &invalid}); err == nil {
+		t.Error("expected an error for a proxy URL without a scheme")
+	}
+}
+
+func TestGetHttpTransportNoProxyWithEnvironmentProxy(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "http://proxy.example.com:8080")
+	t.Setenv("NO_PROXY", "localhost")
+
+	transport, err := getHttpTransport(azureADConfig{NoProxy: []string{".internal.example.com"}})
+	if err != nil || transport == nil {
+		t.Fatalf("expected a transport, got %v (%v)", transport, err)
+	}
+
+	// no_proxy adds to NO_PROXY for the proxy of the environment
+	cases := map[string]string{
+		"https://graph.microsoft.com/v1.0/users":                "http://proxy.example.com:8080",
+		"https://login.internal.example.com/token":              "",
+		"https://localhost/token":                               "",
+		"http://169.254.169.254/metadata/identity/oauth2/token": "",
+	}
+	for target, want := range cases {
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		u, err := transport.Proxy(req)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", target, err)
+		}
+		var got string
+		if u != nil {
+			got = u.String()
+		}
+		if got != want {
+			t.Errorf("%s: expected proxy %q, got %q", target, want, got)
+		}
+	}
+}
+
+func TestGetHttpTransportCaCertificateFiles(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "root-ca.pem")
+	caPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	transport, err := getHttpTransport(azureADConfig{CaCertificateFiles: []string{caFile}})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the private CA to be trusted, got %v", err)
+	}
+	resp.Body.Close()
+
+	notPem := filepath.Join(dir, "not-a-cert.pem")
+	if err := os.WriteFile(notPem, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := getHttpTransport(azureADConfig{CaCertificateFiles: []string{notPem}}); err == nil {
+		t.Error("expected an error for a file without certificates")
+	}
+}
@@ -0,0 +1,342 @@
+package azuread
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/iancoleman/strcase"
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/quals"
+)
+
+// odataType is how the values of a property are written in an OData filter.
+type odataType int
+
+const (
+	// odataString values are quoted, e.g. displayName eq 'Ada'
+	odataString odataType = iota
+	// odataGuid values are not quoted, e.g. resourceId eq 00000000-0000-0000-0000-000000000000
+	odataGuid
+	odataBool
+	odataTimestamp
+)
+
+// Operators which can be pushed down for each type of property.
+var (
+	odataStringOperators    = []string{quals.QualOperatorEqual, quals.QualOperatorLike}
+	odataBoolOperators      = []string{quals.QualOperatorEqual, quals.QualOperatorNotEqual}
+	odataTimestampOperators = []string{quals.QualOperatorEqual, quals.QualOperatorLess, quals.QualOperatorLessOrEqual, quals.QualOperatorGreater, quals.QualOperatorGreaterOrEqual}
+	// odataAdvancedOperators are pushed down for advanced columns, see odataFilterColumn.Advanced
+	odataAdvancedOperators = []string{quals.QualOperatorNotEqual, quals.QualOperatorIsNull, quals.QualOperatorIsNotNull}
+)
+
+// odataFilterColumn maps a column to the Microsoft Graph property its quals
+// are pushed down to in the $filter of list requests.
+type odataFilterColumn struct {
+	Column string
+	// Property is the Graph property, defaults to the column name in lower camel case
+	Property string
+	Type     odataType
+	// Operators are the pushed down operators, defaults to "=".
+	Operators []string
+	// In is set for properties which support the in operator, so an "=" qual
+	// with a list of values, i.e. IN, is pushed down as an in filter. Many
+	// endpoints only support eq, e.g. those of conditional access.
+	In bool
+	// Advanced is set for properties of directory objects, e.g. users, which
+	// support ne, null and endswith filters as advanced queries. Their quals
+	// with odataAdvancedOperators are pushed down too, and LIKE patterns with
+	// a leading wildcard are pushed down as endswith on the suffix.
+	Advanced bool
+}
+
+// odataFilterColumns are the columns of a table whose quals are pushed down.
+//
+// Postgres checks every qual again on the rows returned, so a qual only has
+// to be pushed down as a filter which returns at least the matching rows:
+// Graph compares strings case-insensitively, LIKE patterns are pushed down as
+// startswith on the prefix before the first wildcard, and quals which can't
+// be written as a filter are left out of it.
+type odataFilterColumns []odataFilterColumn
+
+func (c odataFilterColumn) property() string {
+	if c.Property != "" {
+		return c.Property
+	}
+	return strcase.ToLowerCamel(c.Column)
+}
+
+func (c odataFilterColumn) operators() []string {
+	operators := c.Operators
+	if len(operators) == 0 {
+		operators = []string{quals.QualOperatorEqual}
+	}
+	if c.Advanced {
+		for _, operator := range odataAdvancedOperators {
+			if !helpers.StringSliceContains(operators, operator) {
+				operators = append(operators, operator)
+			}
+		}
+	}
+	return operators
+}
+
+// keyColumns returns the optional key columns which pass the quals of the columns to the list function.
+func (columns odataFilterColumns) keyColumns() plugin.KeyColumnSlice {
+	keyColumns := plugin.KeyColumnSlice{}
+	for _, c := range columns {
+		keyColumns = append(keyColumns, &plugin.KeyColumn{Name: c.Column, Require: plugin.Optional, Operators: c.operators()})
+	}
+	return keyColumns
+}
+
+// filter returns the $filter for the quals of the columns, or "" if none of
+// them can be pushed down, and whether it is an advanced query.
+func (columns odataFilterColumns) filter(keyQuals plugin.KeyColumnQualMap) (string, bool) {
+	var filters []string
+	advanced := false
+	for _, c := range columns {
+		if keyQuals[c.Column] == nil {
+			continue
+		}
+		for _, q := range keyQuals[c.Column].Quals {
+			if filter, isAdvanced, ok := c.qualFilter(q.Operator, q.Value); ok {
+				filters = append(filters, filter)
+				advanced = advanced || isAdvanced
+			}
+		}
+	}
+	return strings.Join(filters, " and "), advanced
+}
+
+// buildODataFilter returns the $filter of a list request: the OData query of
+// the filter column if given, otherwise the filter for the quals of the columns.
+func buildODataFilter(d *plugin.QueryData, columns odataFilterColumns) string {
+	if d.EqualsQuals["filter"] != nil {
+		return d.EqualsQuals["filter"].GetStringValue()
+	}
+	filter, _ := columns.filter(d.Quals)
+	return filter
+}
+
+// odataQuery is the $filter and $search of a list request of directory objects.
+type odataQuery struct {
+	Filter string
+	Search string
+	// Advanced is set if the query needs the advanced query capabilities of
+	// Graph, which are only used with $count and ConsistencyLevel: eventual
+	Advanced bool
+}
+
+// buildODataQuery returns the query of a list request of directory objects:
+// the $filter of buildODataFilter and the $search of the search column. Graph
+// only supports $search, and ne, null and endswith filters, as advanced
+// queries, so they are switched to when any of them is given.
+func buildODataQuery(d *plugin.QueryData, columns odataFilterColumns) odataQuery {
+	var query odataQuery
+	if d.EqualsQuals["filter"] != nil {
+		query.Filter = d.EqualsQuals["filter"].GetStringValue()
+		query.Advanced = isAdvancedFilter(query.Filter)
+	} else {
+		query.Filter, query.Advanced = columns.filter(d.Quals)
+	}
+
+	if d.EqualsQuals["search"] != nil {
+		query.Search = odataSearch(d.EqualsQuals["search"].GetStringValue())
+		query.Advanced = query.Search != "" || query.Advanced
+	}
+
+	return query
+}
+
+// headers returns the request headers of the query, with ConsistencyLevel: eventual for advanced queries.
+func (q odataQuery) headers() *abstractions.RequestHeaders {
+	headers := &abstractions.RequestHeaders{}
+	if q.Advanced {
+		headers.Add("ConsistencyLevel", "eventual")
+	}
+	return headers
+}
+
+// count returns the $count of the query, which Graph requires to be true for advanced queries.
+func (q odataQuery) count() *bool {
+	if q.Advanced {
+		return Bool(true)
+	}
+	return nil
+}
+
+// advancedFilterRegex matches the operators and functions Graph only supports
+// on directory objects as advanced queries: ne, not, endswith and null
+// comparisons. It may match inside a string literal, which at worst makes an
+// ordinary filter an advanced query.
+var advancedFilterRegex = regexp.MustCompile(`(?i)\b(ne|not|endswith)\b|\beq null\b`)
+
+// isAdvancedFilter reports whether a filter is an advanced query.
+func isAdvancedFilter(filter string) bool {
+	return advancedFilterRegex.MatchString(filter)
+}
+
+// odataSearch returns the $search for the value of the search column: a search
+// of the display name for a search term, e.g. Ada is "displayName:Ada", or the
+// value as is if it is already a search expression, e.g. "mail:ada@" OR
+// "displayName:Ada", i.e. starts with a double quote.
+func odataSearch(search string) string {
+	search = strings.TrimSpace(search)
+	if search == "" || strings.HasPrefix(search, `"`) {
+		return search
+	}
+	// Double quotes and backslashes in a search term are escaped with a backslash
+	search = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(search)
+	return `"displayName:` + search + `"`
+}
+
+// qualFilter returns the filter for a qual of the column and whether it is an
+// advanced query, or false if it can't be pushed down.
+func (c odataFilterColumn) qualFilter(operator string, value *proto.QualValue) (string, bool, bool) {
+	property := c.property()
+
+	switch operator {
+	case quals.QualOperatorIsNull:
+		return property + " eq null", true, true
+	case quals.QualOperatorIsNotNull:
+		return property + " ne null", true, true
+	}
+
+	if list := value.GetListValue(); list != nil {
+		if operator != quals.QualOperatorEqual || !c.In {
+			return "", false, false
+		}
+		var values []string
+		for _, v := range list.Values {
+			literal, ok := c.literal(v)
+			if !ok {
+				return "", false, false
+			}
+			values = append(values, literal)
+		}
+		if len(values) == 0 {
+			return "", false, false
+		}
+		return fmt.Sprintf("%s in (%s)", property, strings.Join(values, ", ")), false, true
+	}
+
+	if operator == quals.QualOperatorLike {
+		if c.Type != odataString {
+			return "", false, false
+		}
+		pattern := value.GetStringValue()
+		prefix, exact := likePrefix(pattern)
+		if prefix != "" {
+			if exact {
+				return fmt.Sprintf("%s eq %s", property, odataQuote(prefix)), false, true
+			}
+			return fmt.Sprintf("startswith(%s, %s)", property, odataQuote(prefix)), false, true
+		}
+		// A pattern which only has a leading wildcard, e.g. %@example.com
+		if suffix, ok := strings.CutPrefix(pattern, "%"); ok && c.Advanced {
+			if suffix, exact := likePrefix(suffix); exact && suffix != "" {
+				return fmt.Sprintf("endswith(%s, %s)", property, odataQuote(suffix)), true, true
+			}
+		}
+		return "", false, false
+	}
+
+	// Not equal on a boolean is equal to the opposite value, which Graph
+	// supports on more properties than ne
+	if operator == quals.QualOperatorNotEqual && c.Type == odataBool {
+		return fmt.Sprintf("%s eq %t", property, !value.GetBoolValue()), false, true
+	}
+
+	literal, ok := c.literal(value)
+	if !ok {
+		return "", false, false
+	}
+
+	var odataOperator string
+	switch operator {
+	case quals.QualOperatorEqual:
+		odataOperator = "eq"
+	case quals.QualOperatorNotEqual:
+		odataOperator = "ne"
+	case quals.QualOperatorLessOrEqual:
+		odataOperator = "le"
+	case quals.QualOperatorGreaterOrEqual:
+		odataOperator = "ge"
+	case quals.QualOperatorLess:
+		// Graph only supports ge and le on most timestamps, e.g. those of audit logs
+		odataOperator = "lt"
+		if c.Type == odataTimestamp {
+			odataOperator = "le"
+		}
+	case quals.QualOperatorGreater:
+		odataOperator = "gt"
+		if c.Type == odataTimestamp {
+			odataOperator = "ge"
+		}
+	default:
+		return "", false, false
+	}
+
+	return fmt.Sprintf("%s %s %s", property, odataOperator, literal), odataOperator == "ne", true
+}
+
+var guidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// literal returns the value written as an OData literal of the type of the column.
+func (c odataFilterColumn) literal(value *proto.QualValue) (string, bool) {
+	switch c.Type {
+	case odataBool:
+		if _, ok := value.GetValue().(*proto.QualValue_BoolValue); !ok {
+			return "", false
+		}
+		return fmt.Sprintf("%t", value.GetBoolValue()), true
+	case odataTimestamp:
+		if value.GetTimestampValue() == nil {
+			return "", false
+		}
+		return value.GetTimestampValue().AsTime().UTC().Format(time.RFC3339Nano), true
+	case odataGuid:
+		// A value which is not a GUID can't match, and must not be written unquoted
+		if !guidRegex.MatchString(value.GetStringValue()) {
+			return "", false
+		}
+		return value.GetStringValue(), true
+	default:
+		if _, ok := value.GetValue().(*proto.QualValue_StringValue); !ok {
+			return "", false
+		}
+		return odataQuote(value.GetStringValue()), true
+	}
+}
+
+// odataQuote returns a string literal, with single quotes escaped by doubling them.
+func odataQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// likePrefix returns the literal prefix of a LIKE pattern before its first
+// wildcard, and whether the pattern has no wildcards at all.
+func likePrefix(pattern string) (string, bool) {
+	var prefix strings.Builder
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			prefix.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%' || r == '_':
+			return prefix.String(), false
+		default:
+			prefix.WriteRune(r)
+		}
+	}
+	return prefix.String(), true
+}
@@ -0,0 +1,258 @@
+package azuread
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/quals"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestODataFilter(t *testing.T) {
+	columns := odataFilterColumns{
+		{Column: "display_name", Operators: odataStringOperators, In: true},
+		{Column: "name", Property: "displayName"},
+		{Column: "resource_id", Type: odataGuid},
+		{Column: "account_enabled", Type: odataBool, Operators: odataBoolOperators},
+		{Column: "created_date_time", Type: odataTimestamp, Operators: odataTimestampOperators},
+		{Column: "user_type", Operators: []string{"=", "<>", "is null", "is not null"}},
+	}
+
+	stringValue := func(v string) *proto.QualValue {
+		return &proto.QualValue{Value: &proto.QualValue_StringValue{StringValue: v}}
+	}
+	listValue := func(values ...string) *proto.QualValue {
+		list := &proto.QualValueList{}
+		for _, v := range values {
+			list.Values = append(list.Values, stringValue(v))
+		}
+		return &proto.QualValue{Value: &proto.QualValue_ListValue{ListValue: list}}
+	}
+	timestampValue := func(v time.Time) *proto.QualValue {
+		return &proto.QualValue{Value: &proto.QualValue_TimestampValue{TimestampValue: timestamppb.New(v)}}
+	}
+	since := time.Date(2026, 10, 1, 8, 30, 0, 500000000, time.FixedZone("CEST", 2*60*60))
+
+	cases := []struct {
+		column   string
+		operator string
+		value    *proto.QualValue
+		want     string
+	}{
+		{"display_name", "=", stringValue("Ada"), "displayName eq 'Ada'"},
+		{"display_name", "=", stringValue("O'Brien' or 1 eq 1"), "displayName eq 'O''Brien'' or 1 eq 1'"},
+		{"display_name", "=", listValue("Ada", "O'Brien"), "displayName in ('Ada', 'O''Brien')"},
+		{"display_name", "~~", stringValue("Ada%"), "startswith(displayName, 'Ada')"},
+		{"display_name", "~~", stringValue("Ad_%"), "startswith(displayName, 'Ad')"},
+		{"display_name", "~~", stringValue(`100\%%`), "startswith(displayName, '100%')"},
+		{"display_name", "~~", stringValue("Ada"), "displayName eq 'Ada'"},
+		{"display_name", "~~", stringValue("%Ada"), ""},
+		{"name", "=", stringValue("Google"), "displayName eq 'Google'"},
+		{"name", "=", listValue("Google", "Facebook"), ""},
+		{"resource_id", "=", stringValue("00000000-0000-0000-0000-000000000001"), "resourceId eq 00000000-0000-0000-0000-000000000001"},
+		{"resource_id", "=", stringValue("1 or true"), ""},
+		{"account_enabled", "=", &proto.QualValue{Value: &proto.QualValue_BoolValue{BoolValue: true}}, "accountEnabled eq true"},
+		{"account_enabled", "<>", &proto.QualValue{Value: &proto.QualValue_BoolValue{BoolValue: true}}, "accountEnabled eq false"},
+		{"created_date_time", "=", timestampValue(since), "createdDateTime eq 2026-10-01T06:30:00.5Z"},
+		{"created_date_time", ">=", timestampValue(since), "createdDateTime ge 2026-10-01T06:30:00.5Z"},
+		{"created_date_time", ">", timestampValue(since), "createdDateTime ge 2026-10-01T06:30:00.5Z"},
+		{"created_date_time", "<", timestampValue(since), "createdDateTime le 2026-10-01T06:30:00.5Z"},
+		{"user_type", "<>", stringValue("Guest"), "userType ne 'Guest'"},
+		{"user_type", "is null", nil, "userType eq null"},
+		{"user_type", "is not null", nil, "userType ne null"},
+	}
+
+	for _, tc := range cases {
+		keyQuals := plugin.KeyColumnQualMap{
+			tc.column: &plugin.KeyColumnQuals{Name: tc.column, Quals: quals.QualSlice{{Column: tc.column, Operator: tc.operator, Value: tc.value}}},
+		}
+		if got, _ := columns.filter(keyQuals); got != tc.want {
+			t.Errorf("%s %s %v: expected %q, got %q", tc.column, tc.operator, tc.value, tc.want, got)
+		}
+	}
+}
+
+func TestODataFilterInOnlyWhereSupported(t *testing.T) {
+	list := &proto.QualValue{Value: &proto.QualValue_ListValue{ListValue: &proto.QualValueList{Values: []*proto.QualValue{
+		{Value: &proto.QualValue_StringValue{StringValue: "Head office"}},
+		{Value: &proto.QualValue_StringValue{StringValue: "Branch office"}},
+	}}}}
+	keyQuals := plugin.KeyColumnQualMap{
+		"display_name": &plugin.KeyColumnQuals{Name: "display_name", Quals: quals.QualSlice{{Column: "display_name", Operator: "=", Value: list}}},
+	}
+
+	if got, _ := groupFilterColumns.filter(keyQuals); got != "displayName in ('Head office', 'Branch office')" {
+		t.Errorf("expected an in filter for groups, got %q", got)
+	}
+	// Conditional access only supports eq, so the IN list is checked by Postgres
+	if got, _ := conditionalAccessNamedLocationFilterColumns.filter(keyQuals); got != "" {
+		t.Errorf("expected no filter for named locations, got %q", got)
+	}
+}
+
+func TestODataFilterKeyColumns(t *testing.T) {
+	columns := odataFilterColumns{
+		{Column: "display_name", Operators: odataStringOperators},
+		{Column: "state"},
+	}
+
+	keyColumns := columns.keyColumns()
+	if len(keyColumns) != 2 {
+		t.Fatalf("expected 2 key columns, got %d", len(keyColumns))
+	}
+	for _, k := range keyColumns {
+		if k.Require != plugin.Optional {
+			t.Errorf("expected %s to be optional", k.Name)
+		}
+	}
+	if got := keyColumns[0].Operators; len(got) != 2 || got[1] != "~~" {
+		t.Errorf("unexpected display_name operators %v", got)
+	}
+	if got := keyColumns[1].Operators; len(got) != 1 || got[0] != "=" {
+		t.Errorf("unexpected state operators %v", got)
+	}
+
+	advanced := odataFilterColumns{{Column: "display_name", Operators: odataStringOperators, Advanced: true}}.keyColumns()
+	if got := advanced[0].Operators; !reflect.DeepEqual(got, []string{"=", "~~", "<>", "is null", "is not null"}) {
+		t.Errorf("unexpected advanced display_name operators %v", got)
+	}
+}
+
+func TestODataAdvancedFilter(t *testing.T) {
+	columns := odataFilterColumns{
+		{Column: "display_name", Operators: odataStringOperators, Advanced: true},
+		{Column: "mail", Operators: odataStringOperators},
+		{Column: "security_enabled", Type: odataBool, Operators: odataBoolOperators, Advanced: true},
+	}
+
+	stringValue := func(v string) *proto.QualValue {
+		return &proto.QualValue{Value: &proto.QualValue_StringValue{StringValue: v}}
+	}
+
+	cases := []struct {
+		column       string
+		operator     string
+		value        *proto.QualValue
+		want         string
+		wantAdvanced bool
+	}{
+		{"display_name", "=", stringValue("Ada"), "displayName eq 'Ada'", false},
+		{"display_name", "~~", stringValue("Ada%"), "startswith(displayName, 'Ada')", false},
+		{"display_name", "~~", stringValue("%@example.com"), "endswith(displayName, '@example.com')", true},
+		{"display_name", "~~", stringValue("%Ada%"), "", false},
+		{"display_name", "<>", stringValue("Ada"), "displayName ne 'Ada'", true},
+		{"display_name", "is null", nil, "displayName eq null", true},
+		{"security_enabled", "<>", &proto.QualValue{Value: &proto.QualValue_BoolValue{BoolValue: true}}, "securityEnabled eq false", false},
+		{"security_enabled", "is not null", nil, "securityEnabled ne null", true},
+		// Only advanced columns are pushed down as endswith
+		{"mail", "~~", stringValue("%@example.com"), "", false},
+	}
+
+	for _, tc := range cases {
+		keyQuals := plugin.KeyColumnQualMap{
+			tc.column: &plugin.KeyColumnQuals{Name: tc.column, Quals: quals.QualSlice{{Column: tc.column, Operator: tc.operator, Value: tc.value}}},
+		}
+		got, advanced := columns.filter(keyQuals)
+		if got != tc.want || advanced != tc.wantAdvanced {
+			t.Errorf("%s %s %v: expected %q (advanced %t), got %q (advanced %t)", tc.column, tc.operator, tc.value, tc.want, tc.wantAdvanced, got, advanced)
+		}
+	}
+}
+
+func TestIsAdvancedFilter(t *testing.T) {
+	cases := map[string]bool{
+		"displayName eq 'Ada'":                            false,
+		"startswith(displayName, 'Ada')":                  false,
+		"userType ne 'Guest'":                             true,
+		"not(groupTypes/any(c:c eq 'Unified'))":           true,
+		"endsWith(mail, '@example.com')":                  true,
+		"onPremisesSyncEnabled eq null":                   true,
+		"displayName eq 'Ada' and accountEnabled eq true": false,
+	}
+	for filter, want := range cases {
+		if got := isAdvancedFilter(filter); got != want {
+			t.Errorf("%q: expected %t, got %t", filter, want, got)
+		}
+	}
+}
+
+func TestODataSearch(t *testing.T) {
+	cases := map[string]string{
+		"Ada":                             `"displayName:Ada"`,
+		` Ada "The Countess" `:            `"displayName:Ada \"The Countess\""`,
+		`"displayName:Ada" OR "mail:ada"`: `"displayName:Ada" OR "mail:ada"`,
+		"":                                "",
+	}
+	for search, want := range cases {
+		if got := odataSearch(search); got != want {
+			t.Errorf("%q: expected %q, got %q", search, want, got)
+		}
+	}
+}
+
+func TestODataAdvancedQueryPushdown(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("users")
+
+	quals := map[string]*proto.Quals{
+		"search":              {Quals: []*proto.Qual{stringQual("search", "=", "Ada")}},
+		"user_principal_name": {Quals: []*proto.Qual{stringQual("user_principal_name", "~~", "%@example.com")}},
+	}
+	if _, err := executeQuery(t, "azuread_user", []string{"id", "search"}, quals); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+
+	requests := standIn.requestsFor("users")
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+	query := requests[0].URL.Query()
+	if got := query.Get("$filter"); got != "endswith(userPrincipalName, '@example.com')" {
+		t.Errorf("unexpected $filter %q", got)
+	}
+	if got := query.Get("$search"); got != `"displayName:Ada"` {
+		t.Errorf("unexpected $search %q", got)
+	}
+	if got := query.Get("$count"); got != "true" {
+		t.Errorf("unexpected $count %q", got)
+	}
+	if got := requests[0].Header.Get("ConsistencyLevel"); got != "eventual" {
+		t.Errorf("unexpected ConsistencyLevel %q", got)
+	}
+
+	// Ordinary queries don't need the eventually consistent advanced queries
+	standIn.reset()
+	standIn.setCollection("groups")
+	if _, err := executeQuery(t, "azuread_group", []string{"id"}, nil); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	requests = standIn.requestsFor("groups")
+	if len(requests) != 1 || requests[0].Header.Get("ConsistencyLevel") != "" || requests[0].URL.Query().Has("$count") {
+		t.Errorf("expected an ordinary query, got %v", requests)
+	}
+}
+
+func TestODataFilterPushdown(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("groups")
+
+	quals := map[string]*proto.Quals{
+		"display_name":     {Quals: []*proto.Qual{stringQual("display_name", "~~", "Finance's%")}},
+		"security_enabled": {Quals: []*proto.Qual{boolQual("security_enabled", "=", true)}},
+	}
+	if _, err := executeQuery(t, "azuread_group", []string{"id", "display_name"}, quals); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+
+	requests := standIn.requestsFor("groups")
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+	want := "startswith(displayName, 'Finance''s') and securityEnabled eq true"
+	if got := requests[0].URL.Query().Get("$filter"); got != want {
+		t.Errorf("expected $filter %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,129 @@
+package azuread
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tableRequirement is what a table needs to be queried: the Microsoft Graph
+// application permissions, and the license the tenant must have, if any.
+type tableRequirement struct {
+	Permissions []string
+	License     string
+}
+
+const (
+	licenseAzureADPremium   = "Microsoft Entra ID P1 or P2"
+	licenseAzureADPremiumP2 = "Microsoft Entra ID P2"
+)
+
+// tableRequirements holds the least privileged Microsoft Graph application
+// permissions needed by each table.
+var tableRequirements = map[string]tableRequirement{
+	"azuread_admin_consent_request_policy":                 {Permissions: []string{"Policy.Read.All"}},
+	"azuread_application":                                  {Permissions: []string{"Application.Read.All"}},
+	"azuread_application_app_role_assigned_to":             {Permissions: []string{"Application.Read.All"}},
+	"azuread_authorization_policy":                         {Permissions: []string{"Policy.Read.All"}},
+	"azuread_conditional_access_named_location":            {Permissions: []string{"Policy.Read.All"}, License: licenseAzureADPremium},
+	"azuread_conditional_access_policy":                    {Permissions: []string{"Policy.Read.All"}, License: licenseAzureADPremium},
+	"azuread_device":                                       {Permissions: []string{"Device.Read.All"}},
+	"azuread_directory_audit_report":                       {Permissions: []string{"AuditLog.Read.All"}},
+	"azuread_directory_role":                               {Permissions: []string{"RoleManagement.Read.Directory"}},
+	"azuread_directory_role_assignment":                    {Permissions: []string{"RoleManagement.Read.Directory"}},
+	"azuread_directory_role_assignment_schedule_instance":  {Permissions: []string{"RoleManagement.Read.Directory"}, License: licenseAzureADPremiumP2},
+	"azuread_directory_role_assignment_schedule_request":   {Permissions: []string{"RoleManagement.Read.Directory"}, License: licenseAzureADPremiumP2},
+	"azuread_directory_role_definition":                    {Permissions: []string{"RoleManagement.Read.Directory"}},
+	"azuread_directory_role_eligibility_schedule":          {Permissions: []string{"RoleManagement.Read.Directory"}, License: licenseAzureADPremiumP2},
+	"azuread_directory_role_eligibility_schedule_instance": {Permissions: []string{"RoleManagement.Read.Directory"}, License: licenseAzureADPremiumP2},
+	"azuread_directory_setting":                            {Permissions: []string{"Directory.Read.All"}},
+	"azuread_domain":                                       {Permissions: []string{"Domain.Read.All"}},
+	"azuread_graph_request":                                {}, // Depends on the requested path
+	"azuread_group":                                        {Permissions: []string{"Group.Read.All"}},
+	"azuread_group_app_role_assignment":                    {Permissions: []string{"Directory.Read.All"}},
+	"azuread_group_member":                                 {Permissions: []string{"GroupMember.Read.All"}},
+	"azuread_group_nesting":                                {Permissions: []string{"GroupMember.Read.All"}},
+	"azuread_identity_provider":                            {Permissions: []string{"IdentityProvider.Read.All"}},
+	"azuread_plugin_permission_check":                      {Permissions: []string{"Organization.Read.All"}},
+	"azuread_security_defaults_policy":                     {Permissions: []string{"Policy.Read.All"}},
+	"azuread_service_principal":                            {Permissions: []string{"Application.Read.All"}},
+	"azuread_service_principal_app_role_assigned_to":       {Permissions: []string{"Application.Read.All"}},
+	"azuread_service_principal_app_role_assignment":        {Permissions: []string{"Application.Read.All"}},
+	"azuread_sign_in_report":                               {Permissions: []string{"AuditLog.Read.All", "Directory.Read.All"}, License: licenseAzureADPremium},
+	"azuread_user":                                         {Permissions: []string{"User.Read.All"}},
+	"azuread_user_app_role_assignment":                     {Permissions: []string{"Directory.Read.All"}},
+	"azuread_user_transitive_member_of":                    {Permissions: []string{"Directory.Read.All"}},
+}
+
+// licenseServicePlans holds the service plans which provide each license.
+var licenseServicePlans = map[string][]string{
+	licenseAzureADPremium:   {"AAD_PREMIUM", "AAD_PREMIUM_P2"},
+	licenseAzureADPremiumP2: {"AAD_PREMIUM_P2"},
+}
+
+// directoryPermissions grant read access to most directory objects, so they
+// also satisfy the narrower permissions required by those tables.
+var directoryPermissions = []string{"Directory.Read.All", "Directory.ReadWrite.All", "Directory.AccessAsUser.All"}
+
+var directoryReadPermissions = map[string]bool{
+	"Application.Read.All":          true,
+	"Device.Read.All":               true,
+	"Domain.Read.All":               true,
+	"Group.Read.All":                true,
+	"GroupMember.Read.All":          true,
+	"Organization.Read.All":         true,
+	"RoleManagement.Read.Directory": true,
+	"User.Read.All":                 true,
+}
+
+// broaderPermissions holds the permissions which include a narrower one,
+// other than its ReadWrite and directory counterparts.
+var broaderPermissions = map[string][]string{
+	"GroupMember.Read.All": {"Group.Read.All"},
+}
+
+// isPermissionGranted returns true if the granted roles or scopes include the
+// permission, or a broader one which includes it.
+func isPermissionGranted(permission string, granted map[string]bool) bool {
+	if granted[permission] || granted[strings.Replace(permission, ".Read.", ".ReadWrite.", 1)] {
+		return true
+	}
+	for _, p := range broaderPermissions[permission] {
+		if isPermissionGranted(p, granted) {
+			return true
+		}
+	}
+	if directoryReadPermissions[permission] || permission == "Directory.Read.All" {
+		for _, p := range directoryPermissions {
+			if granted[p] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// permissionHint returns a message naming the permissions needed by a table.
+func permissionHint(table string) string {
+	requirement, ok := tableRequirements[table]
+	if !ok {
+		return ""
+	}
+	if len(requirement.Permissions) == 0 {
+		return fmt.Sprintf("The %s table requires the Microsoft Graph application permissions of the requested path, with admin consent.", table)
+	}
+
+	noun := "permission"
+	if len(requirement.Permissions) > 1 {
+		noun = "permissions"
+	}
+	return fmt.Sprintf("The %s table requires the %s Microsoft Graph application %s, with admin consent.", table, strings.Join(requirement.Permissions, " and "), noun)
+}
+
+// licenseHint returns a message naming the license needed by a table.
+func licenseHint(table string) string {
+	requirement, ok := tableRequirements[table]
+	if !ok || requirement.License == "" {
+		return ""
+	}
+	return fmt.Sprintf("The %s table requires a %s license for the tenant.", table, requirement.License)
+}
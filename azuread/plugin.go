@@ -22,34 +22,46 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 		},
 		DefaultGetConfig: &plugin.GetConfig{
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_ResourceNotFound"}),
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
 			},
 		},
 		ConnectionConfigSchema: &plugin.ConnectionConfigSchema{
 			NewInstance: ConfigInstance,
 		},
+		ConnectionConfigChangedFunc: connectionConfigChanged,
 		TableMap: map[string]*plugin.Table{
-			"azuread_admin_consent_request_policy":           tableAzureAdAdminConsentRequestPolicy(ctx),
-			"azuread_application":                            tableAzureAdApplication(ctx),
-			"azuread_application_app_role_assigned_to":       tableAzureAdApplicationAppRoleAssignment(ctx),
-			"azuread_authorization_policy":                   tableAzureAdAuthorizationPolicy(ctx),
-			"azuread_conditional_access_named_location":      tableAzureAdConditionalAccessNamedLocation(ctx),
-			"azuread_conditional_access_policy":              tableAzureAdConditionalAccessPolicy(ctx),
-			"azuread_device":                                 tableAzureAdDevice(ctx),
-			"azuread_directory_audit_report":                 tableAzureAdDirectoryAuditReport(ctx),
-			"azuread_directory_role":                         tableAzureAdDirectoryRole(ctx),
-			"azuread_directory_setting":                      tableAzureAdDirectorySetting(ctx),
-			"azuread_domain":                                 tableAzureAdDomain(ctx),
-			"azuread_group":                                  tableAzureAdGroup(ctx),
-			"azuread_group_app_role_assignment":              tableAzureAdGroupAppRoleAssignment(ctx),
-			"azuread_identity_provider":                      tableAzureAdIdentityProvider(ctx),
-			"azuread_security_defaults_policy":               tableAzureAdSecurityDefaultsPolicy(ctx),
-			"azuread_service_principal":                      tableAzureAdServicePrincipal(ctx),
-			"azuread_service_principal_app_role_assigned_to": tableAzureAdServicePrincipalAppRoleAssignedTo(ctx),
-			"azuread_service_principal_app_role_assignment":  tableAzureAdServicePrincipalAppRoleAssignment(ctx),
-			"azuread_sign_in_report":                         tableAzureAdSignInReport(ctx),
-			"azuread_user":                                   tableAzureAdUser(ctx),
-			"azuread_user_app_role_assignment":               tableAzureAdUserAppRoleAssignment(ctx),
+			"azuread_admin_consent_request_policy":                 tableAzureAdAdminConsentRequestPolicy(ctx),
+			"azuread_application":                                  tableAzureAdApplication(ctx),
+			"azuread_application_app_role_assigned_to":             tableAzureAdApplicationAppRoleAssignment(ctx),
+			"azuread_authorization_policy":                         tableAzureAdAuthorizationPolicy(ctx),
+			"azuread_conditional_access_named_location":            tableAzureAdConditionalAccessNamedLocation(ctx),
+			"azuread_conditional_access_policy":                    tableAzureAdConditionalAccessPolicy(ctx),
+			"azuread_device":                                       tableAzureAdDevice(ctx),
+			"azuread_directory_audit_report":                       tableAzureAdDirectoryAuditReport(ctx),
+			"azuread_directory_role":                               tableAzureAdDirectoryRole(ctx),
+			"azuread_directory_role_assignment":                    tableAzureAdDirectoryRoleAssignment(ctx),
+			"azuread_directory_role_assignment_schedule_instance":  tableAzureAdDirectoryRoleAssignmentScheduleInstance(ctx),
+			"azuread_directory_role_assignment_schedule_request":   tableAzureAdDirectoryRoleAssignmentScheduleRequest(ctx),
+			"azuread_directory_role_definition":                    tableAzureAdDirectoryRoleDefinition(ctx),
+			"azuread_directory_role_eligibility_schedule":          tableAzureAdDirectoryRoleEligibilitySchedule(ctx),
+			"azuread_directory_role_eligibility_schedule_instance": tableAzureAdDirectoryRoleEligibilityScheduleInstance(ctx),
+			"azuread_directory_setting":                            tableAzureAdDirectorySetting(ctx),
+			"azuread_domain":                                       tableAzureAdDomain(ctx),
+			"azuread_graph_request":                                tableAzureAdGraphRequest(ctx),
+			"azuread_group":                                        tableAzureAdGroup(ctx),
+			"azuread_group_app_role_assignment":                    tableAzureAdGroupAppRoleAssignment(ctx),
+			"azuread_group_member":                                 tableAzureAdGroupMember(ctx),
+			"azuread_group_nesting":                                tableAzureAdGroupNesting(ctx),
+			"azuread_identity_provider":                            tableAzureAdIdentityProvider(ctx),
+			"azuread_plugin_permission_check":                      tableAzureAdPluginPermissionCheck(ctx),
+			"azuread_security_defaults_policy":                     tableAzureAdSecurityDefaultsPolicy(ctx),
+			"azuread_service_principal":                            tableAzureAdServicePrincipal(ctx),
+			"azuread_service_principal_app_role_assigned_to":       tableAzureAdServicePrincipalAppRoleAssignedTo(ctx),
+			"azuread_service_principal_app_role_assignment":        tableAzureAdServicePrincipalAppRoleAssignment(ctx),
+			"azuread_sign_in_report":                               tableAzureAdSignInReport(ctx),
+			"azuread_user":                                         tableAzureAdUser(ctx),
+			"azuread_user_app_role_assignment":                     tableAzureAdUserAppRoleAssignment(ctx),
+			"azuread_user_transitive_member_of":                    tableAzureAdUserTransitiveMemberOf(ctx),
 		},
 	}
 
@@ -0,0 +1,6 @@
+//go:build race
+
+package azuread
+
+// raceDetector is whether the tests are built with -race, see executeConnectionQuery.
+const raceDetector = true
@@ -0,0 +1,126 @@
+package azuread
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+
+	khttp "github.com/microsoft/kiota-http-go"
+	"golang.org/x/time/rate"
+)
+
+// maxPerRowHydrateConcurrency is the default maximum number of concurrent calls
+// for hydrate functions which make a Graph request for every row, e.g. getAdGroupMembers.
+const maxPerRowHydrateConcurrency = 10
+
+// rateLimitHandler is a middleware for the Graph HTTP client which limits the
+// rate and the number of concurrent requests of a connection. The limits are
+// shared by every request sent through the adapter, including retries and
+// follow-up page requests. A request which carries several Graph requests,
+// i.e. a JSON batch, counts as each of them, see withGraphRequestWeight.
+type rateLimitHandler struct {
+	// limiter is nil if the request rate is not limited
+	limiter *rate.Limiter
+	// inFlight is nil if the number of concurrent requests is not limited
+	inFlight chan struct{}
+	// acquireMu is held while a request takes its slots of inFlight, so that
+	// requests which need several slots cannot block each other holding some
+	acquireMu sync.Mutex
+}
+
+// graphRequestWeightKey is the context key of the number of Graph requests an
+// HTTP request counts as.
+type graphRequestWeightKey struct{}
+
+// withGraphRequestWeight returns a context whose HTTP requests count as weight
+// requests against the rate limits of the connection.
+func withGraphRequestWeight(ctx context.Context, weight int) context.Context {
+	return context.WithValue(ctx, graphRequestWeightKey{}, weight)
+}
+
+// graphRequestWeight returns the number of Graph requests an HTTP request with
+// the context counts as.
+func graphRequestWeight(ctx context.Context) int {
+	if weight, ok := ctx.Value(graphRequestWeightKey{}).(int); ok && weight > 1 {
+		return weight
+	}
+	return 1
+}
+
+func newRateLimitHandler(requestsPerSecond float64, maxConcurrentRequests int) *rateLimitHandler {
+	h := &rateLimitHandler{}
+	if requestsPerSecond > 0 {
+		h.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), rateLimitBurst(requestsPerSecond))
+	}
+	if maxConcurrentRequests > 0 {
+		h.inFlight = make(chan struct{}, maxConcurrentRequests)
+	}
+	return h
+}
+
+// Intercept implements the khttp.Middleware interface.
+func (h *rateLimitHandler) Intercept(pipeline khttp.Pipeline, middlewareIndex int, req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	weight := graphRequestWeight(ctx)
+
+	if h.inFlight != nil {
+		slots, err := h.acquire(ctx, min(weight, cap(h.inFlight)))
+		defer h.release(slots)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if h.limiter != nil {
+		if err := h.limiter.WaitN(ctx, min(weight, h.limiter.Burst())); err != nil {
+			return nil, err
+		}
+	}
+
+	return pipeline.Next(req, middlewareIndex)
+}
+
+// acquire takes n slots of inFlight, and returns how many it took before the
+// context was canceled.
+func (h *rateLimitHandler) acquire(ctx context.Context, n int) (int, error) {
+	h.acquireMu.Lock()
+	defer h.acquireMu.Unlock()
+
+	for i := 0; i < n; i++ {
+		select {
+		case h.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return i, ctx.Err()
+		}
+	}
+	return n, nil
+}
+
+func (h *rateLimitHandler) release(n int) {
+	for i := 0; i < n; i++ {
+		<-h.inFlight
+	}
+}
+
+// rateLimitBurst returns the number of requests which may be sent at once
+// without exceeding requestsPerSecond.
+func rateLimitBurst(requestsPerSecond float64) int {
+	return int(math.Max(1, math.Ceil(requestsPerSecond)))
+}
+
+// getRateLimitHandlerConfig returns the rate limit settings of the connection.
+// Zero values mean no limit.
+func getRateLimitHandlerConfig(config azureADConfig) (float64, int) {
+	var requestsPerSecond float64
+	if config.MaxRequestsPerSecond != nil {
+		requestsPerSecond = *config.MaxRequestsPerSecond
+	}
+
+	var maxConcurrentRequests int
+	if config.MaxConcurrentRequests != nil {
+		maxConcurrentRequests = *config.MaxConcurrentRequests
+	}
+
+	return requestsPerSecond, maxConcurrentRequests
+}
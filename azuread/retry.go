@@ -0,0 +1,161 @@
+package azuread
+
+import (
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	khttp "github.com/microsoft/kiota-http-go"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+const (
+	defaultMaxErrorRetryAttempts = 5
+	defaultMaxErrorRetryDelay    = 60 * time.Second
+	minErrorRetryDelay           = 1 * time.Second
+)
+
+// retryHandler is a middleware for the Graph HTTP client which retries
+// throttled (429) and unavailable (503, 504) responses. It waits for the
+// duration given in the Retry-After header if present, otherwise it uses
+// exponential backoff with full jitter.
+//
+// It replaces the default kiota retry handler, so it is applied to every
+// request sent through the adapter, including the follow-up page requests
+// made by msgraphcore.PageIterator.
+type retryHandler struct {
+	maxRetries int
+	maxDelay   time.Duration
+}
+
+func newRetryHandler(maxRetries int, maxDelay time.Duration) *retryHandler {
+	return &retryHandler{
+		maxRetries: maxRetries,
+		maxDelay:   maxDelay,
+	}
+}
+
+// Intercept implements the khttp.Middleware interface.
+func (h *retryHandler) Intercept(pipeline khttp.Pipeline, middlewareIndex int, req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	resp, err := pipeline.Next(req, middlewareIndex)
+	for attempt := 1; attempt <= h.maxRetries; attempt++ {
+		if err != nil || !isRetryableStatusCode(resp.StatusCode) || !isReplayableRequest(req) {
+			return resp, err
+		}
+
+		delay := h.retryDelay(resp.Header.Get("Retry-After"), attempt)
+		plugin.Logger(ctx).Warn("retryHandler", "status_code", resp.StatusCode, "url", req.URL.Redacted(), "attempt", attempt, "delay", delay.String())
+		getGraphTelemetry(ctx).recordRetry(ctx, resp.StatusCode, attempt, delay)
+
+		// Drain the body so the underlying connection can be reused
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		req.Header.Set("Retry-Attempt", strconv.Itoa(attempt))
+
+		resp, err = pipeline.Next(req, middlewareIndex)
+	}
+
+	return resp, err
+}
+
+// retryDelay returns how long to wait before the given retry attempt of a
+// response with the given Retry-After header, capped at maxDelay.
+func (h *retryHandler) retryDelay(retryAfter string, attempt int) time.Duration {
+	if delay, ok := parseRetryAfter(retryAfter); ok {
+		return min(delay, h.maxDelay)
+	}
+
+	backoff := float64(minErrorRetryDelay) * math.Pow(2, float64(attempt-1))
+	ceiling := time.Duration(math.Min(backoff, float64(h.maxDelay)))
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(ceiling)) + 1)
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+func isRetryableStatusCode(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests ||
+		statusCode == http.StatusServiceUnavailable ||
+		statusCode == http.StatusGatewayTimeout
+}
+
+// isReplayableRequest reports whether the request can be sent again, i.e. it has
+// no body or its body can be recreated.
+func isReplayableRequest(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// getRetryHandlerConfig returns the retry settings of the connection, applying defaults.
+func getRetryHandlerConfig(config azureADConfig) (int, time.Duration) {
+	maxRetries := defaultMaxErrorRetryAttempts
+	if config.MaxErrorRetryAttempts != nil {
+		maxRetries = max(*config.MaxErrorRetryAttempts, 0)
+	}
+
+	maxDelay := defaultMaxErrorRetryDelay
+	if config.MaxErrorRetryDelay != nil {
+		maxDelay = time.Duration(max(*config.MaxErrorRetryDelay, 0)) * time.Second
+	}
+
+	return maxRetries, maxDelay
+}
+
+// getGraphMiddlewares returns the telemetryHandler, followed by the default
+// Graph client middlewares with the kiota retry handler replaced by
+// retryHandler, and the rateLimitHandler. The rate limiter comes after the
+// retry handler so that retries are limited too.
+func getGraphMiddlewares(config azureADConfig) []khttp.Middleware {
+	clientOptions := msgraphsdkgo.GetDefaultClientOptions()
+
+	middlewares := []khttp.Middleware{newTelemetryHandler()}
+	for _, m := range msgraphcore.GetDefaultMiddlewaresWithOptions(&clientOptions) {
+		if _, ok := m.(*khttp.RetryHandler); ok {
+			continue
+		}
+		middlewares = append(middlewares, m)
+	}
+
+	return append(middlewares,
+		newRetryHandler(getRetryHandlerConfig(config)),
+		newRateLimitHandler(getRateLimitHandlerConfig(config)),
+	)
+}
@@ -3,198 +3,295 @@ package azuread
 import (
 	"bytes"
 	"context"
-	"crypto"
-	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"runtime"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	a "github.com/microsoft/kiota-authentication-azure-go"
+	khttp "github.com/microsoft/kiota-http-go"
 	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 )
 
-/*
-GetGraphClient creates a graph service client configured from (~/.steampipe/config, environment variables and CLI) in the order:
-1. Client secret
-2. Client certificate
-3. MSI
-4. CLI
-*/
-func GetGraphClient(ctx context.Context, d *plugin.QueryData) (*msgraphsdkgo.GraphServiceClient, *msgraphsdkgo.GraphRequestAdapter, error) {
-	logger := plugin.Logger(ctx)
+// Tests point the Graph client at an in-process stand-in server by setting
+// these. They must not be set outside of tests.
+var (
+	testGraphEndpoint   string
+	testGraphCredential azcore.TokenCredential
+)
 
-	// Disable caching since it only saves ~.25ms and results in an SDK error
-	// when running consecutive queries for the sign_in_report and service_principal
-	// tables:
-	// Error: rpc error: code = Internal desc = hydrate function listAdSignInReports failed with panic runtime error: invalid memory address or nil pointer dereference (SQLSTATE HV000)
-	// Have we already created and cached the session?
-	// sessionCacheKey := "GetGraphClient"
-	// if cachedData, ok := d.ConnectionManager.Cache.Get(sessionCacheKey); ok {
-	// 	return cachedData.(*msgraphsdkgo.GraphServiceClient), nil, nil
-	// }
+// graphSession holds the Graph clients and request adapters shared by every
+// hydrate call of a connection.
+type graphSession struct {
+	client  *msgraphsdkgo.GraphServiceClient
+	adapter *msgraphsdkgo.GraphRequestAdapter
+	// betaClient and betaAdapter are used for the tables queried with the beta
+	// endpoint, and share the HTTP client, and so the retry and rate limits, of
+	// the v1.0 adapter
+	betaClient  *msgraphsdkgo.GraphServiceClient
+	betaAdapter *msgraphsdkgo.GraphRequestAdapter
+	// batcher and betaBatcher coalesce the per-row requests sent with the
+	// v1.0 and beta adapters into JSON batches
+	batcher     *graphBatcher
+	betaBatcher *graphBatcher
+	// cred and scopes are what the adapter authenticates with
+	cred   azcore.TokenCredential
+	scopes []string
+}
 
-	var tenantID, environment, clientID, clientSecret, certificatePath, certificatePassword string
+// graphSessions holds the session of each connection, by connection name. It
+// is not kept in the connection cache, whose entries expire, since the session
+// holds the state which must be shared by every query of the connection, e.g.
+// its rate limits and cached access tokens.
+var (
+	graphSessionsMu sync.Mutex
+	graphSessions   = map[string]*graphSessionEntry{}
+)
 
-	azureADConfig := GetConfig(d.Connection)
-	if azureADConfig.TenantID != nil {
-		tenantID = *azureADConfig.TenantID
-	} else {
-		tenantID = os.Getenv("AZURE_TENANT_ID")
+// graphSessionEntry holds the session of a connection once it is created.
+// Concurrent callers wait for the first one to create it rather than each
+// creating their own.
+type graphSessionEntry struct {
+	mu      sync.Mutex
+	session *graphSession
+}
+
+// getGraphSession returns the session of the connection, creating it on first use.
+func getGraphSession(ctx context.Context, d *plugin.QueryData) (*graphSession, error) {
+	graphSessionsMu.Lock()
+	entry, ok := graphSessions[d.Connection.Name]
+	if !ok {
+		entry = &graphSessionEntry{}
+		graphSessions[d.Connection.Name] = entry
 	}
+	graphSessionsMu.Unlock()
 
-	if azureADConfig.Environment != nil {
-		environment = *azureADConfig.Environment
-	} else {
-		environment = os.Getenv("AZURE_ENVIRONMENT")
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	// A session which failed to be created is created again on the next call
+	if entry.session == nil {
+		session, err := newGraphSession(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+		entry.session = session
 	}
+	return entry.session, nil
+}
 
-	var enableMsi bool
-	if azureADConfig.EnableMsi != nil {
-		enableMsi = *azureADConfig.EnableMsi
+// invalidateGraphSession removes the session of a connection, so it is
+// created again with the new config of the connection on next use.
+func invalidateGraphSession(connectionName string) {
+	graphSessionsMu.Lock()
+	defer graphSessionsMu.Unlock()
+	delete(graphSessions, connectionName)
+}
+
+// connectionConfigChanged invalidates the session of the connection, as well
+// as its connection and query caches like the default callback of the SDK,
+// and reports an invalid new config rather than waiting for the next query.
+func connectionConfigChanged(ctx context.Context, p *plugin.Plugin, _ *plugin.Connection, new *plugin.Connection) error {
+	invalidateGraphSession(new.Name)
+
+	if err := p.ClearConnectionCache(ctx, new.Name); err != nil {
+		return err
+	}
+	if err := p.ClearQueryCache(ctx, new.Name); err != nil {
+		return err
 	}
 
-	// 1. Client secret credentials
-	if azureADConfig.ClientID != nil {
-		clientID = *azureADConfig.ClientID
-	} else {
-		clientID = os.Getenv("AZURE_CLIENT_ID")
+	// The SDK ignores the error of this callback, so it is logged too
+	if err := validateConnectionConfig(GetConfig(new), p.TableMap); err != nil {
+		plugin.Logger(ctx).Error("connectionConfigChanged", "connection", new.Name, "config_error", err)
+		return fmt.Errorf("invalid config for connection %s: %v", new.Name, err)
 	}
+	return nil
+}
 
-	if azureADConfig.ClientSecret != nil {
-		clientSecret = *azureADConfig.ClientSecret
-	} else {
-		clientSecret = os.Getenv("AZURE_CLIENT_SECRET")
+// validateConnectionConfig returns an error if the connection config is
+// invalid: an unknown cloud, API version, beta or delta sync table, an invalid
+// proxy, CA certificate or cassette setting, or an auth_mode, or implicit
+// authentication mode, whose settings are missing.
+func validateConnectionConfig(config azureADConfig, tables map[string]*plugin.Table) error {
+	cloudEndpoints, err := getGraphCloud(config)
+	if err != nil {
+		return err
+	}
+	if err := validateGraphApiVersion(config, tables); err != nil {
+		return err
+	}
+	if err := validateDeltaSync(config); err != nil {
+		return err
+	}
+	if _, err := getHttpTransport(config); err != nil {
+		return err
+	}
+	cassetteMode, _, err := getCassetteConfig(config)
+	if err != nil {
+		return err
 	}
 
-	// 2. Client certificate credentials
-	if azureADConfig.CertificatePath != nil {
-		certificatePath = *azureADConfig.CertificatePath
-	} else {
-		certificatePath = os.Getenv("AZURE_CERTIFICATE_PATH")
+	// Neither replaying recorded responses nor the Graph stand-in server of the tests needs credentials
+	if cassetteMode == cassetteModeReplay || testGraphCredential != nil {
+		return nil
 	}
+	_, err = getAuthSettings(config, cloudEndpoints.Configuration(), nil).authMode()
+	return err
+}
 
-	if azureADConfig.CertificatePassword != nil {
-		certificatePassword = *azureADConfig.CertificatePassword
-	} else {
-		certificatePassword = os.Getenv("AZURE_CERTIFICATE_PASSWORD")
+/*
+GetGraphClient returns the graph service client and request adapter for the connection.
+The session is created once per connection and reused by all subsequent calls.
+Tables listed in beta_tables, or every table if graph_api_version is "beta",
+get a client for the beta endpoint.
+*/
+func GetGraphClient(ctx context.Context, d *plugin.QueryData) (*msgraphsdkgo.GraphServiceClient, *msgraphsdkgo.GraphRequestAdapter, error) {
+	// Both the client and the adapter are cached together. Caching only the
+	// client (and returning a nil adapter) caused a nil pointer dereference in
+	// the page iterators of tables such as azuread_sign_in_report.
+	session, err := getGraphSession(ctx, d)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	var cloudConfiguration cloud.Configuration
-	switch environment {
-	case "AZURECHINACLOUD":
-		cloudConfiguration = cloud.AzureChina
-	case "AZUREUSGOVERNMENTCLOUD":
-		cloudConfiguration = cloud.AzureGovernment
-	default:
-		cloudConfiguration = cloud.AzurePublic
+	if usesGraphBeta(d) {
+		return session.betaClient, session.betaAdapter, nil
 	}
+	return session.client, session.adapter, nil
+}
 
-	var cred azcore.TokenCredential
-	var err error
-	if tenantID == "" { // CLI authentication
-		cred, err = azidentity.NewAzureCLICredential(
-			&azidentity.AzureCLICredentialOptions{},
-		)
-		if err != nil {
-			logger.Error("GetGraphClient", "cli_credential_error", err)
-			return nil, nil, err
-		}
-	} else if tenantID != "" && clientID != "" && clientSecret != "" { // Client secret authentication
-		cred, err = azidentity.NewClientSecretCredential(
-			tenantID,
-			clientID,
-			clientSecret,
-			&azidentity.ClientSecretCredentialOptions{
-				ClientOptions: policy.ClientOptions{
-					Cloud: cloudConfiguration,
-				},
-			},
-		)
-		if err != nil {
-			logger.Error("GetGraphClient", "client_secret_credential_error", err)
-			return nil, nil, err
-		}
-	} else if tenantID != "" && clientID != "" && certificatePath != "" { // Client certificate authentication
-		// Load certificate from given path
-		loadFile, err := os.ReadFile(certificatePath)
-		if err != nil {
-			return nil, nil, fmt.Errorf("error reading certificate from %s: %v", certificatePath, err)
-		}
+// getGraphBatcher returns the graphBatcher for the Graph endpoint of the table, see GetGraphClient.
+func getGraphBatcher(ctx context.Context, d *plugin.QueryData) (*graphBatcher, error) {
+	session, err := getGraphSession(ctx, d)
+	if err != nil {
+		return nil, err
+	}
 
-		var certs []*x509.Certificate
-		var key crypto.PrivateKey
-		if certificatePassword == "" {
-			certs, key, err = azidentity.ParseCertificates(loadFile, nil)
-		} else {
-			certs, key, err = azidentity.ParseCertificates(loadFile, []byte(certificatePassword))
-		}
+	if usesGraphBeta(d) {
+		return session.betaBatcher, nil
+	}
+	return session.batcher, nil
+}
 
-		if err != nil {
-			return nil, nil, fmt.Errorf("error parsing certificate from %s: %v", certificatePath, err)
-		}
+// getGraphAccessToken returns the access token the Graph client of the connection authenticates with.
+func getGraphAccessToken(ctx context.Context, d *plugin.QueryData) (string, error) {
+	session, err := getGraphSession(ctx, d)
+	if err != nil {
+		return "", err
+	}
 
-		cred, err = azidentity.NewClientCertificateCredential(
-			tenantID,
-			clientID,
-			certs,
-			key,
-			&azidentity.ClientCertificateCredentialOptions{
-				ClientOptions: policy.ClientOptions{
-					Cloud: cloudConfiguration,
-				},
-			},
-		)
-		if err != nil {
-			logger.Error("GetGraphClient", "client_certificate_credential_error", err)
-			return nil, nil, err
-		}
-	} else if enableMsi { // Managed identity authentication
-		cred, err = azidentity.NewManagedIdentityCredential(
-			&azidentity.ManagedIdentityCredentialOptions{},
-		)
-		if err != nil {
-			logger.Error("GetGraphClient", "managed_identity_credential_error", err)
-			return nil, nil, err
-		}
+	token, err := session.cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: session.scopes})
+	if err != nil {
+		return "", err
 	}
+	return token.Token, nil
+}
 
-	// update the Authentication provider scope if env is china cloud
-	var auth *a.AzureIdentityAuthenticationProvider
-	if environment == "AZURECHINACLOUD" {
-		auth, err = a.NewAzureIdentityAuthenticationProviderWithScopes(cred, []string{
-			"https://microsoftgraph.chinacloudapi.cn/.default",
-		})
-	} else {
-		auth, err = a.NewAzureIdentityAuthenticationProvider(cred)
+/*
+newGraphSession creates a graph service client configured from (~/.steampipe/config, environment variables and CLI)
+using the auth_mode of the connection, or if it is not set, the first of:
+1. Client secret
+2. Client certificate
+3. Workload identity (federated token file)
+4. MSI
+5. CLI
+*/
+func newGraphSession(ctx context.Context, d *plugin.QueryData) (*graphSession, error) {
+	logger := plugin.Logger(ctx)
+
+	azureADConfig := GetConfig(d.Connection)
+
+	var tables map[string]*plugin.Table
+	if d.Table != nil {
+		tables = d.Table.Plugin.TableMap
+	}
+	if err := validateConnectionConfig(azureADConfig, tables); err != nil {
+		return nil, err
+	}
+
+	cloudEndpoints, err := getGraphCloud(azureADConfig)
+	if err != nil {
+		return nil, err
+	}
+	if testGraphEndpoint != "" {
+		cloudEndpoints.GraphEndpoint = testGraphEndpoint
 	}
+
+	transport, err := getHttpTransport(azureADConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	cassetteMode, cassetteDir, err := getCassetteConfig(azureADConfig)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error creating authentication provider: %v", err)
+		return nil, err
+	}
+
+	var cred azcore.TokenCredential
+	if testGraphCredential != nil { // Graph stand-in server used by tests
+		cred = testGraphCredential
+	} else if cassetteMode == cassetteModeReplay { // Recorded responses are replayed without authentication
+		cred = cassetteTokenCredential{dir: cassetteDir}
+	} else {
+		cred, err = getGraphCredential(logger, getAuthSettings(azureADConfig, cloudEndpoints.Configuration(), transport))
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	adapter, err := msgraphsdkgo.NewGraphRequestAdapter(auth)
+	// Reuse access tokens across requests until they are about to expire
+	cred = newCachedTokenCredential(cred)
+
+	// Access tokens are requested for the Graph endpoint of the cloud
+	scopes := []string{cloudEndpoints.TokenScope()}
+	auth, err := a.NewAzureIdentityAuthenticationProviderWithScopes(cred, scopes)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error creating graph adapter: %v", err)
+		return nil, fmt.Errorf("error creating authentication provider: %v", err)
 	}
 
-	// update the baseurl if env is china cloud
-	if environment == "AZURECHINACLOUD" {
-		adapter.SetBaseUrl("https://microsoftgraph.chinacloudapi.cn/v1.0")
+	// Use our own HTTP client so throttled requests are retried according to the connection config
+	middlewares := getGraphMiddlewares(azureADConfig)
+	if cassetteMode != "" {
+		// Innermost, so what is recorded or replayed is what goes over the network
+		middlewares = append(middlewares, newCassetteHandler(cassetteMode, cassetteDir))
 	}
+	clientOptions := msgraphsdkgo.GetDefaultClientOptions()
+	httpClient := msgraphcore.GetDefaultClient(&clientOptions, middlewares...)
+	if transport != nil {
+		httpClient.Transport = khttp.NewCustomTransportWithParentTransport(transport, middlewares...)
+	}
+
+	session := &graphSession{cred: cred, scopes: scopes}
+	// Requests in a batch are retried with the same settings as the batch itself
+	batchRetry := newRetryHandler(getRetryHandlerConfig(azureADConfig))
+	batchMaxSteps := getGraphBatchMaxSteps(azureADConfig)
+	for _, apiVersion := range []string{graphApiVersionV1, graphApiVersionBeta} {
+		adapter, err := msgraphsdkgo.NewGraphRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClient(auth, nil, nil, httpClient)
+		if err != nil {
+			return nil, fmt.Errorf("error creating graph adapter: %v", err)
+		}
+		adapter.SetBaseUrl(cloudEndpoints.BaseUrl(apiVersion))
+		client := msgraphsdkgo.NewGraphServiceClient(adapter)
 
-	client := msgraphsdkgo.NewGraphServiceClient(adapter)
+		batcher := newGraphBatcher(adapter, batchRetry, batchMaxSteps, cassetteMode != "")
 
-	// See comment above as to why caching is disabled
-	// Save session into cache
-	// d.ConnectionManager.Cache.Set(sessionCacheKey, client)
+		if apiVersion == graphApiVersionBeta {
+			session.betaClient, session.betaAdapter, session.betaBatcher = client, adapter, batcher
+		} else {
+			session.client, session.adapter, session.batcher = client, adapter, batcher
+		}
+	}
 
-	return client, adapter, nil
+	return session, nil
 }
 
 // https://github.com/Azure/go-autorest/blob/3fb5326fea196cd5af02cf105ca246a0fba59021/autorest/azure/cli/token.go#L126
@@ -244,3 +341,47 @@ func getTenantFromCLI() (string, error) {
 
 	return tokenResponse.Tenant, nil
 }
+
+// tokenExpiryWindow is how long before expiry a cached access token is refreshed.
+const tokenExpiryWindow = 5 * time.Minute
+
+// cachedTokenCredential wraps a credential and reuses its access tokens until
+// they are about to expire. Some credentials, e.g. Azure CLI, have no cache of
+// their own and would otherwise be invoked for every Graph request.
+type cachedTokenCredential struct {
+	cred   azcore.TokenCredential
+	mu     sync.Mutex
+	tokens map[string]azcore.AccessToken
+}
+
+func newCachedTokenCredential(cred azcore.TokenCredential) *cachedTokenCredential {
+	return &cachedTokenCredential{
+		cred:   cred,
+		tokens: map[string]azcore.AccessToken{},
+	}
+}
+
+func (c *cachedTokenCredential) GetToken(ctx context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	// Claims challenges always require a fresh token
+	if opts.Claims != "" {
+		return c.cred.GetToken(ctx, opts)
+	}
+
+	key := fmt.Sprintf("%s|%s|%t", opts.TenantID, strings.Join(opts.Scopes, " "), opts.EnableCAE)
+
+	// The lock is held while fetching so concurrent callers share a single token request
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if token, ok := c.tokens[key]; ok && time.Until(token.ExpiresOn) > tokenExpiryWindow {
+		return token, nil
+	}
+
+	token, err := c.cred.GetToken(ctx, opts)
+	if err != nil {
+		return azcore.AccessToken{}, err
+	}
+	c.tokens[key] = token
+
+	return token, nil
+}
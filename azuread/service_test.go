@@ -0,0 +1,107 @@
+package azuread
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func testGraphSession(name string) *graphSession {
+	graphSessionsMu.Lock()
+	defer graphSessionsMu.Unlock()
+
+	if entry, ok := graphSessions[name]; ok {
+		return entry.session
+	}
+	return nil
+}
+
+func TestGraphSessionPerConnection(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("users", map[string]interface{}{"id": "u1"})
+
+	connection := &proto.ConnectionConfig{
+		Connection: "azuread_session_test",
+		Plugin:     pluginName,
+		Config:     fmt.Sprintf("tenant_id = %q", testTenantID),
+	}
+	if _, err := pluginServer.UpdateConnectionConfigs(&proto.UpdateConnectionConfigsRequest{Added: []*proto.ConnectionConfig{connection}}); err != nil {
+		t.Fatalf("adding the connection failed: %v", err)
+	}
+	defer pluginServer.UpdateConnectionConfigs(&proto.UpdateConnectionConfigsRequest{Deleted: []*proto.ConnectionConfig{connection}})
+
+	query := func() *graphSession {
+		t.Helper()
+		if _, err := executeConnectionQuery(t, connection.Connection, "azuread_user", []string{"id"}, nil); err != nil {
+			t.Fatalf("list failed: %v", err)
+		}
+		return testGraphSession(connection.Connection)
+	}
+
+	// The session is reused by every query of the connection
+	session := query()
+	if session == nil {
+		t.Fatal("expected a session for the connection")
+	}
+	if query() != session {
+		t.Error("expected the session to be reused")
+	}
+	if session == testGraphSession(testConnectionName) {
+		t.Error("expected each connection to have its own session")
+	}
+
+	// A change of the config of the connection creates a new session
+	connection.Config = fmt.Sprintf("tenant_id = %q\nmax_requests_per_second = 100", testTenantID)
+	if _, err := pluginServer.UpdateConnectionConfigs(&proto.UpdateConnectionConfigsRequest{Changed: []*proto.ConnectionConfig{connection}}); err != nil {
+		t.Fatalf("changing the connection failed: %v", err)
+	}
+	if testGraphSession(connection.Connection) != nil {
+		t.Error("expected the session to be invalidated")
+	}
+	if changed := query(); changed == nil || changed == session {
+		t.Error("expected a new session for the changed connection")
+	}
+}
+
+func TestValidateConnectionConfig(t *testing.T) {
+	// Validate the credentials, which are not needed with the Graph stand-in server
+	credential := testGraphCredential
+	testGraphCredential = nil
+	defer func() { testGraphCredential = credential }()
+	for _, name := range []string{"AZURE_TENANT_ID", "AZURE_CLIENT_ID", "AZURE_CLIENT_SECRET", "AZURE_CERTIFICATE_PATH", "AZURE_FEDERATED_TOKEN_FILE"} {
+		t.Setenv(name, "")
+	}
+
+	str := func(s string) *string { return &s }
+	tables := Plugin(context.Background()).TableMap
+	cases := []struct {
+		config  azureADConfig
+		wantErr string
+	}{
+		{config: azureADConfig{}},
+		{config: azureADConfig{TenantID: str(testTenantID), ClientID: str("app-1"), ClientSecret: str("secret")}},
+		{config: azureADConfig{AuthMode: str("password")}, wantErr: "invalid auth_mode"},
+		{config: azureADConfig{AuthMode: str(authModeClientSecret), TenantID: str(testTenantID), ClientID: str("app-1")}, wantErr: "requires client_secret"},
+		{config: azureADConfig{TenantID: str(testTenantID)}, wantErr: "no credentials were found"},
+		{config: azureADConfig{Environment: str("moon")}, wantErr: "environment"},
+		{config: azureADConfig{GraphApiVersion: str("v2.0")}, wantErr: "invalid graph_api_version"},
+		{config: azureADConfig{BetaTables: []string{"azuread_users"}}, wantErr: "unknown tables"},
+		{config: azureADConfig{ProxyUrl: str("proxy:8080")}, wantErr: "invalid proxy_url"},
+		{config: azureADConfig{CassetteMode: str("rewind")}, wantErr: "invalid cassette_mode"},
+		// Recorded responses are replayed without credentials
+		{config: azureADConfig{TenantID: str(testTenantID), CassetteMode: str(cassetteModeReplay), CassetteDir: str(t.TempDir())}},
+	}
+
+	for i, tc := range cases {
+		err := validateConnectionConfig(tc.config, tables)
+		if tc.wantErr == "" && err != nil {
+			t.Errorf("%d: unexpected error %v", i, err)
+		}
+		if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+			t.Errorf("%d: expected an error containing %q, got %v", i, tc.wantErr, err)
+		}
+	}
+}
@@ -39,6 +39,8 @@ func tableAzureAdAdminConsentRequestPolicy(_ context.Context) *plugin.Table {
 //// LIST FUNCTION
 
 func listAdAdminConsentRequestPolicies(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdAdminConsentRequestPolicies")
+
 	// Create client
 	client, _, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -48,7 +50,7 @@ func listAdAdminConsentRequestPolicies(ctx context.Context, d *plugin.QueryData,
 
 	result, err := client.Policies().AdminConsentRequestPolicy().Get(ctx, nil)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("listAdAdminConsentRequestPolicies", "list_application_error", errObj)
 		return nil, errObj
 	}
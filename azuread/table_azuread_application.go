@@ -2,10 +2,7 @@ package azuread
 
 import (
 	"context"
-	"fmt"
-	"strings"
 
-	"github.com/iancoleman/strcase"
 	abstractions "github.com/microsoft/kiota-abstractions-go"
 	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
 	"github.com/microsoftgraph/msgraph-sdk-go/applications"
@@ -25,23 +22,24 @@ func tableAzureAdApplication(_ context.Context) *plugin.Table {
 		Get: &plugin.GetConfig{
 			Hydrate: getAdApplication,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_ResourceNotFound", "Invalid object identifier"}),
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
 			},
 			KeyColumns: plugin.SingleColumn("id"),
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listAdApplications,
-			KeyColumns: plugin.KeyColumnSlice{
-				// Key fields
-				{Name: "app_id", Require: plugin.Optional},
-				{Name: "display_name", Require: plugin.Optional},
-				{Name: "publisher_domain", Require: plugin.Optional},
-			},
+			KeyColumns: append(applicationFilterColumns.keyColumns(),
+				&plugin.KeyColumn{Name: "search", Require: plugin.Optional},
+			),
+		},
+		HydrateConfig: []plugin.HydrateConfig{
+			{Func: getAdApplicationOwners, MaxConcurrency: maxBatchedHydrateConcurrency},
 		},
 
 		Columns: commonColumns([]*plugin.Column{
 			{Name: "display_name", Type: proto.ColumnType_STRING, Description: "The display name for the application.", Transform: transform.FromMethod("GetDisplayName")},
 			{Name: "id", Type: proto.ColumnType_STRING, Description: "The unique identifier for the application.", Transform: transform.FromMethod("GetId")},
+			{Name: "search", Type: proto.ColumnType_STRING, Transform: transform.FromQual("search"), Description: "Term to search for in the display name of the application, or an OData $search expression such as \"displayName:Ada\"."},
 			{Name: "app_id", Type: proto.ColumnType_STRING, Description: "The unique identifier for the application that is assigned to an application by Azure AD.", Transform: transform.FromMethod("GetAppId")},
 
 			// Other fields
@@ -64,6 +62,9 @@ func tableAzureAdApplication(_ context.Context) *plugin.Table {
 			{Name: "tags_src", Type: proto.ColumnType_JSON, Description: "Custom strings that can be used to categorize and identify the application.", Transform: transform.FromMethod("GetTags")},
 			{Name: "web", Type: proto.ColumnType_JSON, Description: "Specifies settings for a web application.", Transform: transform.FromMethod("ApplicationWeb")},
 
+			// Beta fields
+			{Name: "beta_properties", Type: proto.ColumnType_JSON, Description: betaColumnDescription("Properties of the application only returned by the beta endpoint."), Hydrate: getGraphBetaProperties, Transform: transform.FromValue()},
+
 			// Standard columns
 			{Name: "tags", Type: proto.ColumnType_JSON, Description: ColumnDescriptionTags, Transform: transform.From(adApplicationTags)},
 			{Name: "title", Type: proto.ColumnType_STRING, Description: ColumnDescriptionTitle, Transform: transform.From(adApplicationTitle)},
@@ -71,9 +72,32 @@ func tableAzureAdApplication(_ context.Context) *plugin.Table {
 	}
 }
 
+// applicationFilterColumns are the columns whose quals are pushed down to the $filter of list requests.
+var applicationFilterColumns = odataFilterColumns{
+	{Column: "app_id", In: true, Advanced: true},
+	{Column: "display_name", Operators: odataStringOperators, In: true, Advanced: true},
+	{Column: "publisher_domain", In: true, Advanced: true},
+}
+
+var applicationSelectProperties = graphSelectProperties{
+	"api":  {"api"},
+	"info": {"info"},
+	// Read from the additional data when returned, it can't be selected
+	"is_authorization_service_enabled": nil,
+	"key_credentials":                  {"keyCredentials"},
+	"parental_control_settings":        {"parentalControlSettings"},
+	"password_credentials":             {"passwordCredentials"},
+	"spa":                              {"spa"},
+	"tags":                             {"tags"},
+	"title":                            {"displayName", "id"},
+	"web":                              {"web"},
+}
+
 //// LIST FUNCTION
 
 func listAdApplications(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdApplications")
+
 	// Create client
 	client, adapter, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -96,28 +120,26 @@ func listAdApplications(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydr
 		}
 	}
 
-	var queryFilter string
-	equalQuals := d.EqualsQuals
-	filter := buildApplicationQueryFilter(equalQuals)
-
-	if equalQuals["filter"] != nil {
-		queryFilter = equalQuals["filter"].GetStringValue()
+	query := buildODataQuery(d, applicationFilterColumns)
+	if query.Filter != "" {
+		input.Filter = &query.Filter
 	}
-
-	if queryFilter != "" {
-		input.Filter = &queryFilter
-	} else if len(filter) > 0 {
-		joinStr := strings.Join(filter, " and ")
-		input.Filter = &joinStr
+	if query.Search != "" {
+		input.Search = &query.Search
 	}
+	input.Count = query.count()
+
+	// Request only the properties of the queried columns
+	input.Select = buildSelect(d, applicationSelectProperties)
 
 	options := &applications.ApplicationsRequestBuilderGetRequestConfiguration{
+		Headers:         query.headers(),
 		QueryParameters: input,
 	}
 
 	result, err := client.Applications().Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("listAdApplications", "list_application_error", errObj)
 		return nil, errObj
 	}
@@ -137,8 +159,9 @@ func listAdApplications(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydr
 		return d.RowsRemaining(ctx) != 0
 	})
 	if err != nil {
-		plugin.Logger(ctx).Error("listAdApplications", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdApplications", "paging_error", errObj)
+		return nil, errObj
 	}
 
 	return nil, nil
@@ -147,6 +170,7 @@ func listAdApplications(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydr
 //// HYDRATE FUNCTIONS
 
 func getAdApplication(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdApplication")
 
 	applicationId := d.EqualsQuals["id"].GetStringValue()
 	if applicationId == "" {
@@ -160,9 +184,15 @@ func getAdApplication(ctx context.Context, d *plugin.QueryData, h *plugin.Hydrat
 		return nil, err
 	}
 
-	application, err := client.Applications().ByApplicationId(applicationId).Get(ctx, nil)
+	options := &applications.ApplicationItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &applications.ApplicationItemRequestBuilderGetQueryParameters{
+			Select: buildSelect(d, applicationSelectProperties),
+		},
+	}
+
+	application, err := client.Applications().ByApplicationId(applicationId).Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getAdApplication", "get_application_error", errObj)
 		return nil, errObj
 	}
@@ -172,6 +202,8 @@ func getAdApplication(ctx context.Context, d *plugin.QueryData, h *plugin.Hydrat
 }
 
 func getAdApplicationOwners(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdApplicationOwners")
+
 	// Create client
 	client, adapter, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -195,9 +227,16 @@ func getAdApplicationOwners(ctx context.Context, d *plugin.QueryData, h *plugin.
 	}
 
 	ownerIds := []*string{}
-	owners, err := client.Applications().ByApplicationId(*applicationID).Owners().Get(ctx, config)
+	requestInfo, err := client.Applications().ByApplicationId(*applicationID).Owners().ToGetRequestInformation(ctx, config)
 	if err != nil {
-		errObj := getErrorObject(err)
+		plugin.Logger(ctx).Error("getAdApplicationOwners", "request_information_error", err)
+		return nil, err
+	}
+
+	// The first page is requested in a batch with those of the other rows
+	owners, err := batchGet[models.DirectoryObjectCollectionResponseable](ctx, d, requestInfo, models.CreateDirectoryObjectCollectionResponseFromDiscriminatorValue)
+	if err != nil {
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getAdApplicationOwners", "get_application_owners_error", errObj)
 		return nil, errObj
 	}
@@ -241,21 +280,3 @@ func adApplicationTitle(_ context.Context, d *transform.TransformData) (interfac
 
 	return title, nil
 }
-
-func buildApplicationQueryFilter(equalQuals plugin.KeyColumnEqualsQualMap) []string {
-	filters := []string{}
-
-	filterQuals := map[string]string{
-		"display_name":     "string",
-		"app_id":           "string",
-		"publisher_domain": "string",
-	}
-
-	for qual := range filterQuals {
-		if equalQuals[qual] != nil {
-			filters = append(filters, fmt.Sprintf("%s eq '%s'", strcase.ToCamel(qual), equalQuals[qual].GetStringValue()))
-		}
-	}
-
-	return filters
-}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/iancoleman/strcase"
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
@@ -24,7 +23,7 @@ func tableAzureAdApplicationAppRoleAssignment(_ context.Context) *plugin.Table {
 		Get: &plugin.GetConfig{
 			Hydrate: getAdApplicationAppRoleAssignedTo,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_ResourceNotFound", "Invalid object identifier"}),
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
 			},
 			KeyColumns: plugin.KeyColumnSlice{
 				{Name: "app_id", Require: plugin.Required},
@@ -34,16 +33,11 @@ func tableAzureAdApplicationAppRoleAssignment(_ context.Context) *plugin.Table {
 		List: &plugin.ListConfig{
 			Hydrate: listAdApplicationAppRoleAssignedTo,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_UnsupportedQuery"}),
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryInvalidFilter),
 			},
-			KeyColumns: plugin.KeyColumnSlice{
-				// Key fields
+			KeyColumns: append(plugin.KeyColumnSlice{
 				{Name: "app_id", Require: plugin.Required},
-
-				// Other fields for filtering OData
-				{Name: "resource_id", Require: plugin.Optional},
-				{Name: "principal_display_name", Require: plugin.Optional},
-			},
+			}, applicationAppRoleAssignedToFilterColumns.keyColumns()...),
 		},
 
 		Columns: []*plugin.Column{
@@ -66,9 +60,21 @@ func tableAzureAdApplicationAppRoleAssignment(_ context.Context) *plugin.Table {
 	}
 }
 
+// applicationAppRoleAssignedToFilterColumns are the columns whose quals are pushed down to the $filter of list requests.
+var applicationAppRoleAssignedToFilterColumns = odataFilterColumns{
+	{Column: "resource_id", Type: odataGuid},
+	{Column: "principal_display_name"},
+}
+
+var applicationAppRoleAssignedToSelectProperties = graphSelectProperties{
+	// The application of the key column
+	"app_id": nil,
+}
+
 //// LIST FUNCTION
 
 func listAdApplicationAppRoleAssignedTo(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdApplicationAppRoleAssignedTo")
 
 	applicationId := d.EqualsQuals["app_id"].GetStringValue()
 	if applicationId == "" {
@@ -97,13 +103,13 @@ func listAdApplicationAppRoleAssignedTo(ctx context.Context, d *plugin.QueryData
 		}
 	}
 
-	// Apply optional filters
-	filters := buildAdApplicationAppRoleAssignmentQueryFilter(d.Quals)
-	if len(filters) > 0 {
-		filterString := strings.Join(filters, " and ")
-		input.Filter = &filterString
+	if filter := buildODataFilter(d, applicationAppRoleAssignedToFilterColumns); filter != "" {
+		input.Filter = &filter
 	}
 
+	// Request only the properties of the queried columns
+	input.Select = buildSelect(d, applicationAppRoleAssignedToSelectProperties)
+
 	options := &serviceprincipals.ItemAppRoleAssignedToRequestBuilderGetRequestConfiguration{
 		QueryParameters: input,
 	}
@@ -115,7 +121,7 @@ func listAdApplicationAppRoleAssignedTo(ctx context.Context, d *plugin.QueryData
 	url := strings.Replace(uri.String(), "/servicePrincipals/placeholder/", fmt.Sprintf("/servicePrincipals('appId=%v')/", applicationId), 1)
 	result, err := client.ServicePrincipals().ByServicePrincipalId("placeholder").AppRoleAssignedTo().WithUrl(url).Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("listAdApplicationAppRoleAssignedTo", "list_service_principal_app_role_assigned_to_error", errObj)
 		return nil, errObj
 	}
@@ -133,8 +139,9 @@ func listAdApplicationAppRoleAssignedTo(ctx context.Context, d *plugin.QueryData
 		return d.RowsRemaining(ctx) != 0
 	})
 	if err != nil {
-		plugin.Logger(ctx).Error("listAdApplicationAppRoleAssignedTo", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdApplicationAppRoleAssignedTo", "paging_error", errObj)
+		return nil, errObj
 	}
 
 	return nil, nil
@@ -143,6 +150,7 @@ func listAdApplicationAppRoleAssignedTo(ctx context.Context, d *plugin.QueryData
 //// HYDRATE FUNCTIONS
 
 func getAdApplicationAppRoleAssignedTo(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdApplicationAppRoleAssignedTo")
 
 	applicationId := d.EqualsQuals["app_id"].GetStringValue()
 	appRoleId := d.EqualsQuals["id"].GetStringValue()
@@ -159,13 +167,18 @@ func getAdApplicationAppRoleAssignedTo(ctx context.Context, d *plugin.QueryData,
 
 	// NOTE: Generated ServicePrincipalsWithAppId builder does not yet support AppRoleAssignedTo-requests
 	//       Therefore, target url is manually changed from ../servicePrincipals/<id>/.. to ../servicePrincipals(appId='<id>')/..
-	requestInfo, _ := client.ServicePrincipals().ByServicePrincipalId("placeholder").AppRoleAssignedTo().ByAppRoleAssignmentId(appRoleId).ToGetRequestInformation(ctx, nil)
+	options := &serviceprincipals.ItemAppRoleAssignedToAppRoleAssignmentItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &serviceprincipals.ItemAppRoleAssignedToAppRoleAssignmentItemRequestBuilderGetQueryParameters{
+			Select: buildSelect(d, applicationAppRoleAssignedToSelectProperties),
+		},
+	}
+	requestInfo, _ := client.ServicePrincipals().ByServicePrincipalId("placeholder").AppRoleAssignedTo().ByAppRoleAssignmentId(appRoleId).ToGetRequestInformation(ctx, options)
 	uri, _ := requestInfo.GetUri()
 	url := strings.Replace(uri.String(), "/servicePrincipals/placeholder/", fmt.Sprintf("/servicePrincipals('appId=%v')/", applicationId), 1)
 
-	appRoleAssignment, err := client.ServicePrincipals().ByServicePrincipalId("placeholder").AppRoleAssignedTo().ByAppRoleAssignmentId(appRoleId).WithUrl(url).Get(ctx, nil)
+	appRoleAssignment, err := client.ServicePrincipals().ByServicePrincipalId("placeholder").AppRoleAssignedTo().ByAppRoleAssignmentId(appRoleId).WithUrl(url).Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getAdApplicationAppRoleAssignedTo", "get_service_principal_app_role_assigned_to_error", errObj)
 		return nil, errObj
 	}
@@ -183,27 +196,3 @@ func adApplicationAppRoleAssignmentApplicationId(_ context.Context, d *transform
 
 	return data.ApplicationId, nil
 }
-
-func buildAdApplicationAppRoleAssignmentQueryFilter(quals plugin.KeyColumnQualMap) []string {
-	filters := []string{}
-
-	filterTypes := map[string]string{
-		"resource_id":            "guid",
-		"principal_display_name": "string",
-	}
-
-	for k, v := range quals {
-		if filterType, ok := filterTypes[k]; ok {
-			for _, q := range v.Quals {
-				switch filterType {
-				case "guid":
-					filters = append(filters, fmt.Sprintf("%s eq %s", strcase.ToCamel(k), q.Value.GetStringValue()))
-				case "string":
-					filters = append(filters, fmt.Sprintf("%s eq '%s'", strcase.ToCamel(k), q.Value.GetStringValue()))
-				}
-			}
-		}
-	}
-
-	return filters
-}
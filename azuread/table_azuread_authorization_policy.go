@@ -43,6 +43,8 @@ func tableAzureAdAuthorizationPolicy(_ context.Context) *plugin.Table {
 //// LIST FUNCTION
 
 func listAdAuthorizationPolicies(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdAuthorizationPolicies")
+
 	// Create client
 	client, _, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -52,7 +54,7 @@ func listAdAuthorizationPolicies(ctx context.Context, d *plugin.QueryData, _ *pl
 
 	result, err := client.Policies().AuthorizationPolicy().Get(ctx, nil)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("listAdAuthorizationPolicies", "list_application_error", errObj)
 		return nil, errObj
 	}
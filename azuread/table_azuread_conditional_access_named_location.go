@@ -2,10 +2,7 @@ package azuread
 
 import (
 	"context"
-	"fmt"
-	"strings"
 
-	"github.com/iancoleman/strcase"
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 
@@ -25,20 +22,18 @@ func tableAzureAdConditionalAccessNamedLocation(_ context.Context) *plugin.Table
 		Get: &plugin.GetConfig{
 			Hydrate: getAdConditionalAccessNamedLocation,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_ResourceNotFound", "Invalid object identifier"}),
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
 			},
 			KeyColumns: plugin.SingleColumn("id"),
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listAdConditionalAccessNamedLocations,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_UnsupportedQuery"}),
-			},
-			KeyColumns: []*plugin.KeyColumn{
-				{Name: "display_name", Require: plugin.Optional},
-				{Name: "id", Require: plugin.Optional},
-				{Name: "location_type", Require: plugin.Optional},
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryInvalidFilter),
 			},
+			KeyColumns: append(conditionalAccessNamedLocationFilterColumns.keyColumns(),
+				&plugin.KeyColumn{Name: "location_type", Require: plugin.Optional},
+			),
 		},
 
 		Columns: commonColumns([]*plugin.Column{
@@ -55,9 +50,17 @@ func tableAzureAdConditionalAccessNamedLocation(_ context.Context) *plugin.Table
 	}
 }
 
+// conditionalAccessNamedLocationFilterColumns are the columns whose quals are pushed down to the $filter of list requests.
+var conditionalAccessNamedLocationFilterColumns = odataFilterColumns{
+	{Column: "display_name"},
+	{Column: "id"},
+}
+
 //// LIST FUNCTION
 
 func listAdConditionalAccessNamedLocations(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdConditionalAccessNamedLocations")
+
 	// Create client
 	client, adapter, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -78,12 +81,8 @@ func listAdConditionalAccessNamedLocations(ctx context.Context, d *plugin.QueryD
 		}
 	}
 
-	equalQuals := d.EqualsQuals
-	filter := buildConditionalAccessNamedLocationQueryFilter(equalQuals)
-
-	if len(filter) > 0 {
-		joinStr := strings.Join(filter, " and ")
-		input.Filter = &joinStr
+	if filter := buildODataFilter(d, conditionalAccessNamedLocationFilterColumns); filter != "" {
+		input.Filter = &filter
 	}
 
 	options := &identity.ConditionalAccessNamedLocationsRequestBuilderGetRequestConfiguration{
@@ -92,7 +91,7 @@ func listAdConditionalAccessNamedLocations(ctx context.Context, d *plugin.QueryD
 
 	result, err := client.Identity().ConditionalAccess().NamedLocations().Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("azuread_conditional_access_named_location.listAdConditionalAccessNamedLocations", "list_conditional_access_named_location_error", errObj)
 		return nil, errObj
 	}
@@ -113,8 +112,9 @@ func listAdConditionalAccessNamedLocations(ctx context.Context, d *plugin.QueryD
 		return d.RowsRemaining(ctx) != 0
 	})
 	if err != nil {
-		plugin.Logger(ctx).Error("azuread_conditional_access_named_location.listAdConditionalAccessNamedLocations", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("azuread_conditional_access_named_location.listAdConditionalAccessNamedLocations", "paging_error", errObj)
+		return nil, errObj
 	}
 
 	return nil, nil
@@ -123,6 +123,7 @@ func listAdConditionalAccessNamedLocations(ctx context.Context, d *plugin.QueryD
 //// HYDRATE FUNCTIONS
 
 func getAdConditionalAccessNamedLocation(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdConditionalAccessNamedLocation")
 
 	conditionalAccessNamedLocationId := d.EqualsQuals["id"].GetStringValue()
 	if conditionalAccessNamedLocationId == "" {
@@ -138,7 +139,7 @@ func getAdConditionalAccessNamedLocation(ctx context.Context, d *plugin.QueryDat
 
 	location, err := client.Identity().ConditionalAccess().NamedLocations().ByNamedLocationId(conditionalAccessNamedLocationId).Get(ctx, nil)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("azuread_conditional_access_named_location.getAdConditionalAccessNamedLocation", "get_conditional_access_location_error", errObj)
 		return nil, errObj
 	}
@@ -149,30 +150,6 @@ func getAdConditionalAccessNamedLocation(ctx context.Context, d *plugin.QueryDat
 	}, nil
 }
 
-func buildConditionalAccessNamedLocationQueryFilter(equalQuals plugin.KeyColumnEqualsQualMap) []string {
-	filters := []string{}
-
-	filterQuals := map[string]string{
-		"display_name": "string",
-		"id":           "string",
-	}
-
-	for qual, qualType := range filterQuals {
-		switch qualType {
-		case "string":
-			if equalQuals[qual] != nil {
-				if qual == "location_type" {
-					filters = append(filters, fmt.Sprintf("type eq '%s'", equalQuals[qual].GetStringValue()))
-				} else {
-					filters = append(filters, fmt.Sprintf("%s eq '%s'", strcase.ToLowerCamel(qual), equalQuals[qual].GetStringValue()))
-				}
-			}
-		}
-	}
-
-	return filters
-}
-
 /// UTILITY FUNCTION
 
 func getNamedLocationDetails(i interface{}) models.NamedLocationable {
@@ -233,7 +210,9 @@ func CountryGetLocationInfo(countryLocationInfo *ADCountryNamedLocationInfo) map
 	locationInfoJSON := map[string]interface{}{}
 	locationInfoJSON["Countries_and_Regions"] = countryLocationInfo.GetCountriesAndRegions()
 	locationInfoJSON["Get_Unknown_Countries_and_Regions"] = countryLocationInfo.GetIncludeUnknownCountriesAndRegions()
-	locationInfoJSON["Lookup_Method"] = countryLocationInfo.GetCountryLookupMethod().String()
+	if countryLocationInfo.GetCountryLookupMethod() != nil {
+		locationInfoJSON["Lookup_Method"] = countryLocationInfo.GetCountryLookupMethod().String()
+	}
 	return locationInfoJSON
 }
 
@@ -2,10 +2,7 @@ package azuread
 
 import (
 	"context"
-	"fmt"
-	"strings"
 
-	"github.com/iancoleman/strcase"
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 
@@ -25,19 +22,16 @@ func tableAzureAdConditionalAccessPolicy(_ context.Context) *plugin.Table {
 		Get: &plugin.GetConfig{
 			Hydrate: getAdConditionalAccessPolicy,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_ResourceNotFound", "Invalid object identifier"}),
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
 			},
 			KeyColumns: plugin.SingleColumn("id"),
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listAdConditionalAccessPolicies,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_UnsupportedQuery"}),
-			},
-			KeyColumns: []*plugin.KeyColumn{
-				{Name: "display_name", Require: plugin.Optional},
-				{Name: "state", Require: plugin.Optional},
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryInvalidFilter),
 			},
+			KeyColumns: conditionalAccessPolicyFilterColumns.keyColumns(),
 		},
 
 		Columns: commonColumns([]*plugin.Column{
@@ -71,9 +65,37 @@ func tableAzureAdConditionalAccessPolicy(_ context.Context) *plugin.Table {
 	}
 }
 
+// conditionalAccessPolicyFilterColumns are the columns whose quals are pushed down to the $filter of list requests.
+var conditionalAccessPolicyFilterColumns = odataFilterColumns{
+	{Column: "display_name"},
+	{Column: "state"},
+}
+
+var conditionalAccessPolicySelectProperties = graphSelectProperties{
+	"applications":                      {"conditions"},
+	"application_enforced_restrictions": {"sessionControls"},
+	"authentication_strength":           {"grantControls"},
+	"built_in_controls":                 {"grantControls"},
+	"client_app_types":                  {"conditions"},
+	"cloud_app_security":                {"sessionControls"},
+	"custom_authentication_factors":     {"grantControls"},
+	"locations":                         {"conditions"},
+	"operator":                          {"grantControls"},
+	"persistent_browser":                {"sessionControls"},
+	"platforms":                         {"conditions"},
+	"sign_in_frequency":                 {"sessionControls"},
+	"sign_in_risk_levels":               {"conditions"},
+	"terms_of_use":                      {"grantControls"},
+	"title":                             {"displayName", "id"},
+	"user_risk_levels":                  {"conditions"},
+	"users":                             {"conditions"},
+}
+
 //// LIST FUNCTION
 
 func listAdConditionalAccessPolicies(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdConditionalAccessPolicies")
+
 	// Create client
 	client, adapter, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -94,21 +116,20 @@ func listAdConditionalAccessPolicies(ctx context.Context, d *plugin.QueryData, _
 		}
 	}
 
-	equalQuals := d.EqualsQuals
-	filter := buildConditionalAccessPolicyQueryFilter(equalQuals)
-
-	if len(filter) > 0 {
-		joinStr := strings.Join(filter, " and ")
-		input.Filter = &joinStr
+	if filter := buildODataFilter(d, conditionalAccessPolicyFilterColumns); filter != "" {
+		input.Filter = &filter
 	}
 
+	// Request only the properties of the queried columns
+	input.Select = buildSelect(d, conditionalAccessPolicySelectProperties)
+
 	options := &identity.ConditionalAccessPoliciesRequestBuilderGetRequestConfiguration{
 		QueryParameters: input,
 	}
 
 	result, err := client.Identity().ConditionalAccess().Policies().Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("listAdConditionalAccessPolicies", "list_conditional_access_policy_error", errObj)
 		return nil, errObj
 	}
@@ -126,8 +147,9 @@ func listAdConditionalAccessPolicies(ctx context.Context, d *plugin.QueryData, _
 		return d.RowsRemaining(ctx) != 0
 	})
 	if err != nil {
-		plugin.Logger(ctx).Error("listAdConditionalAccessPolicies", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdConditionalAccessPolicies", "paging_error", errObj)
+		return nil, errObj
 	}
 
 	return nil, nil
@@ -136,6 +158,7 @@ func listAdConditionalAccessPolicies(ctx context.Context, d *plugin.QueryData, _
 //// HYDRATE FUNCTIONS
 
 func getAdConditionalAccessPolicy(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdConditionalAccessPolicy")
 
 	conditionalAccessPolicyId := d.EqualsQuals["id"].GetStringValue()
 	if conditionalAccessPolicyId == "" {
@@ -149,35 +172,21 @@ func getAdConditionalAccessPolicy(ctx context.Context, d *plugin.QueryData, h *p
 		return nil, err
 	}
 
-	policy, err := client.Identity().ConditionalAccess().Policies().ByConditionalAccessPolicyId(conditionalAccessPolicyId).Get(ctx, nil)
+	options := &identity.ConditionalAccessPoliciesConditionalAccessPolicyItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &identity.ConditionalAccessPoliciesConditionalAccessPolicyItemRequestBuilderGetQueryParameters{
+			Select: buildSelect(d, conditionalAccessPolicySelectProperties),
+		},
+	}
+
+	policy, err := client.Identity().ConditionalAccess().Policies().ByConditionalAccessPolicyId(conditionalAccessPolicyId).Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getAdConditionalAccessPolicy", "get_conditional_access_policy_error", errObj)
 		return nil, errObj
 	}
 	return &ADConditionalAccessPolicyInfo{policy}, nil
 }
 
-func buildConditionalAccessPolicyQueryFilter(equalQuals plugin.KeyColumnEqualsQualMap) []string {
-	filters := []string{}
-
-	filterQuals := map[string]string{
-		"display_name": "string",
-		"state":        "string",
-	}
-
-	for qual, qualType := range filterQuals {
-		switch qualType {
-		case "string":
-			if equalQuals[qual] != nil {
-				filters = append(filters, fmt.Sprintf("%s eq '%s'", strcase.ToCamel(qual), equalQuals[qual].GetStringValue()))
-			}
-		}
-	}
-
-	return filters
-}
-
 //// TRANSFORM FUNCTIONS
 
 func adConditionalAccessPolicyTitle(_ context.Context, d *transform.TransformData) (interface{}, error) {
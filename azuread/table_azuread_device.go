@@ -2,10 +2,7 @@ package azuread
 
 import (
 	"context"
-	"fmt"
-	"strings"
 
-	"github.com/iancoleman/strcase"
 	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
 	"github.com/microsoftgraph/msgraph-sdk-go/devices"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
@@ -27,16 +24,10 @@ func tableAzureAdDevice(_ context.Context) *plugin.Table {
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listAdDevices,
-			KeyColumns: plugin.KeyColumnSlice{
-				// Key fields
-				{Name: "display_name", Require: plugin.Optional},
-				{Name: "account_enabled", Require: plugin.Optional, Operators: []string{"<>", "="}},
-				{Name: "operating_system", Require: plugin.Optional},
-				{Name: "operating_system_version", Require: plugin.Optional},
-				{Name: "profile_type", Require: plugin.Optional},
-				{Name: "trust_type", Require: plugin.Optional},
-				{Name: "filter", Require: plugin.Optional},
-			},
+			KeyColumns: append(deviceFilterColumns.keyColumns(),
+				&plugin.KeyColumn{Name: "filter", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "search", Require: plugin.Optional},
+			),
 		},
 
 		Columns: commonColumns([]*plugin.Column{
@@ -49,6 +40,7 @@ func tableAzureAdDevice(_ context.Context) *plugin.Table {
 
 			// Other fields
 			{Name: "filter", Type: proto.ColumnType_STRING, Transform: transform.FromQual("filter"), Description: "Odata query to search for resources."},
+			{Name: "search", Type: proto.ColumnType_STRING, Transform: transform.FromQual("search"), Description: "Term to search for in the display name of the device, or an OData $search expression such as \"displayName:Ada\"."},
 			{Name: "is_compliant", Type: proto.ColumnType_BOOL, Description: "True if the device is compliant; otherwise, false.", Transform: transform.FromMethod("GetIsCompliant")},
 			{Name: "is_managed", Type: proto.ColumnType_BOOL, Description: "True if the device is managed; otherwise, false.", Transform: transform.FromMethod("GetIsManaged")},
 			{Name: "mdm_app_id", Type: proto.ColumnType_STRING, Description: "Application identifier used to register device into MDM.", Transform: transform.FromMethod("GetMdmAppId")},
@@ -61,15 +53,29 @@ func tableAzureAdDevice(_ context.Context) *plugin.Table {
 			{Name: "extension_attributes", Type: proto.ColumnType_JSON, Description: "Contains extension attributes 1-15 for the device. The individual extension attributes are not selectable. These properties are mastered in cloud and can be set during creation or update of a device object in Azure AD.", Transform: transform.FromMethod("GetExtensions")},
 			{Name: "member_of", Type: proto.ColumnType_JSON, Description: "A list the groups and directory roles that the device is a direct member of.", Transform: transform.FromMethod("DeviceMemberOf")},
 
+			// Beta fields
+			{Name: "beta_properties", Type: proto.ColumnType_JSON, Description: betaColumnDescription("Properties of the device only returned by the beta endpoint."), Hydrate: getGraphBetaProperties, Transform: transform.FromValue()},
+
 			// Standard columns
 			{Name: "title", Type: proto.ColumnType_STRING, Description: ColumnDescriptionTitle, Transform: transform.From(adDeviceTitle)},
 		}),
 	}
 }
 
+// deviceFilterColumns are the columns whose quals are pushed down to the $filter of list requests.
+var deviceFilterColumns = odataFilterColumns{
+	{Column: "display_name", Operators: odataStringOperators, In: true, Advanced: true},
+	{Column: "account_enabled", Type: odataBool, Operators: odataBoolOperators, Advanced: true},
+	{Column: "operating_system", Operators: odataStringOperators, Advanced: true},
+	{Column: "operating_system_version", Operators: odataStringOperators, Advanced: true},
+	{Column: "profile_type", Advanced: true},
+	{Column: "trust_type", Advanced: true},
+}
+
 //// LIST FUNCTION
 
 func listAdDevices(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdDevices")
 
 	// Create client
 	client, adapter, err := GetGraphClient(ctx, d)
@@ -93,38 +99,30 @@ func listAdDevices(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateDa
 		}
 	}
 
-	equalQuals := d.EqualsQuals
-	quals := d.Quals
-
 	givenColumns := d.QueryContext.Columns
-	selectColumns, expandColumns := buildDeviceRequestFields(ctx, givenColumns)
+	selectColumns, expandColumns := buildDeviceRequestFields(d.Table.Columns, givenColumns, usesGraphBeta(d))
 
 	input.Select = selectColumns
 	input.Expand = expandColumns
 
-	var queryFilter string
-	filter := buildDeviceQueryFilter(equalQuals)
-	filter = append(filter, buildDeviceBoolNEFilter(quals)...)
-
-	if equalQuals["filter"] != nil {
-		queryFilter = equalQuals["filter"].GetStringValue()
+	query := buildODataQuery(d, deviceFilterColumns)
+	if query.Filter != "" {
+		input.Filter = &query.Filter
 	}
-
-	if queryFilter != "" {
-		input.Filter = &queryFilter
-	} else if len(filter) > 0 {
-		joinStr := strings.Join(filter, " and ")
-		input.Filter = &joinStr
+	if query.Search != "" {
+		input.Search = &query.Search
 	}
+	input.Count = query.count()
 
 	options := &devices.DevicesRequestBuilderGetRequestConfiguration{
+		Headers:         query.headers(),
 		QueryParameters: input,
 	}
 
 	result, err := client.Devices().Get(ctx, options)
 
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("azuread_device.listAdDevices", "list_device_error", errObj)
 		return nil, errObj
 	}
@@ -143,8 +141,9 @@ func listAdDevices(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateDa
 	})
 
 	if err != nil {
-		plugin.Logger(ctx).Error("azuread_device.listAdDevices", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("azuread_device.listAdDevices", "paging_error", errObj)
+		return nil, errObj
 	}
 
 	return nil, nil
@@ -153,6 +152,7 @@ func listAdDevices(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateDa
 //// HYDRATE FUNCTIONS
 
 func getAdDevice(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdDevice")
 
 	// Create client
 	client, _, err := GetGraphClient(ctx, d)
@@ -168,7 +168,7 @@ func getAdDevice(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData
 
 	// Check for query context and requests only for queried columns
 	givenColumns := d.QueryContext.Columns
-	selectColumns, expandColumns := buildDeviceRequestFields(ctx, givenColumns)
+	selectColumns, expandColumns := buildDeviceRequestFields(d.Table.Columns, givenColumns, usesGraphBeta(d))
 
 	input := &devices.DeviceItemRequestBuilderGetQueryParameters{}
 	input.Select = selectColumns
@@ -180,7 +180,7 @@ func getAdDevice(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData
 
 	device, err := client.Devices().ByDeviceId(deviceId).Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getAdDevice", "get_device_error", errObj)
 		return nil, errObj
 	}
@@ -204,82 +204,22 @@ func adDeviceTitle(_ context.Context, d *transform.TransformData) (interface{},
 	return title, nil
 }
 
-func buildDeviceRequestFields(ctx context.Context, queryColumns []string) ([]string, []string) {
-	var selectColumns, expandColumns []string
-
-	for _, columnName := range queryColumns {
-		if columnName == "filter" || columnName == "tenant_id" {
-			continue
-		}
-
-		if columnName == "member_of" {
-			expandColumns = append(expandColumns, fmt.Sprintf("%s($select=id,displayName)", strcase.ToLowerCamel(columnName)))
-			continue
-		}
-
-		if columnName == "title" {
-			if !helpers.StringSliceContains(queryColumns, "display_name") {
-				selectColumns = append(selectColumns, []string{"displayName"}...)
-			}
-			if !helpers.StringSliceContains(queryColumns, "device_id") {
-				selectColumns = append(selectColumns, []string{"deviceId"}...)
-			}
-			continue
-		}
-
-		selectColumns = append(selectColumns, strcase.ToLowerCamel(columnName))
-	}
-
-	return selectColumns, expandColumns
-}
-
-func buildDeviceQueryFilter(equalQuals plugin.KeyColumnEqualsQualMap) []string {
-	filters := []string{}
-
-	filterQuals := map[string]string{
-		"display_name":             "string",
-		"id":                       "string",
-		"operating_system":         "string",
-		"operating_system_version": "string",
-		"profile_type":             "string",
-		"trust_type":               "string",
-		"account_enabled":          "bool",
-	}
-
-	for qual, qualType := range filterQuals {
-		switch qualType {
-		case "string":
-			if equalQuals[qual] != nil {
-				filters = append(filters, fmt.Sprintf("%s eq '%s'", strcase.ToCamel(qual), equalQuals[qual].GetStringValue()))
-			}
-		case "bool":
-			if equalQuals[qual] != nil {
-				filters = append(filters, fmt.Sprintf("%s eq %t", strcase.ToCamel(qual), equalQuals[qual].GetBoolValue()))
-			}
-		}
-	}
-
-	return filters
+var deviceSelectProperties = graphSelectProperties{
+	// Expanded rather than selected
+	"member_of": nil,
+	"title":     {"displayName", "deviceId"},
 }
 
-func buildDeviceBoolNEFilter(quals plugin.KeyColumnQualMap) []string {
-	filters := []string{}
-
-	filterQuals := []string{
-		"account_enabled",
+func buildDeviceRequestFields(columns []*plugin.Column, queryColumns []string, beta bool) ([]string, []string) {
+	var expandColumns []string
+	if helpers.StringSliceContains(queryColumns, "member_of") {
+		expandColumns = append(expandColumns, "memberOf($select=id,displayName)")
 	}
 
-	for _, qual := range filterQuals {
-		if quals[qual] != nil {
-			for _, q := range quals[qual].Quals {
-				value := q.Value.GetBoolValue()
-				if q.Operator == "<>" {
-					filters = append(filters, fmt.Sprintf("%s eq %t", strcase.ToCamel(qual), !value))
-					break
-				}
-			}
-		}
+	// Beta properties are whatever the beta endpoint returns by default, so the properties are not restricted
+	if beta && helpers.StringSliceContains(queryColumns, "beta_properties") {
+		return nil, expandColumns
 	}
 
-	return filters
+	return selectProperties(columns, queryColumns, deviceSelectProperties), expandColumns
 }
@@ -2,11 +2,7 @@ package azuread
 
 import (
 	"context"
-	"fmt"
-	"strings"
-	"time"
 
-	"github.com/iancoleman/strcase"
 	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
 	"github.com/microsoftgraph/msgraph-sdk-go/auditlogs"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
@@ -25,21 +21,15 @@ func tableAzureAdDirectoryAuditReport(_ context.Context) *plugin.Table {
 		Get: &plugin.GetConfig{
 			Hydrate: getAdDirectoryAuditReport,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_ResourceNotFound", "Invalid object identifier"}),
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
 			},
 			KeyColumns: plugin.SingleColumn("id"),
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listAdDirectoryAuditReports,
-			KeyColumns: plugin.KeyColumnSlice{
-				// Key fields
-				{Name: "activity_date_time", Require: plugin.Optional, Operators: []string{">", ">=", "=", "<", "<="}},
-				{Name: "activity_display_name", Require: plugin.Optional},
-				{Name: "category", Require: plugin.Optional},
-				{Name: "correlation_id", Require: plugin.Optional},
-				{Name: "filter", Require: plugin.Optional},
-				{Name: "result", Require: plugin.Optional},
-			},
+			KeyColumns: append(directoryAuditFilterColumns.keyColumns(),
+				&plugin.KeyColumn{Name: "filter", Require: plugin.Optional},
+			),
 		},
 
 		Columns: commonColumns([]*plugin.Column{
@@ -65,9 +55,27 @@ func tableAzureAdDirectoryAuditReport(_ context.Context) *plugin.Table {
 	}
 }
 
+// directoryAuditFilterColumns are the columns whose quals are pushed down to the $filter of list requests.
+var directoryAuditFilterColumns = odataFilterColumns{
+	{Column: "activity_date_time", Type: odataTimestamp, Operators: odataTimestampOperators},
+	{Column: "activity_display_name"},
+	{Column: "category"},
+	{Column: "correlation_id"},
+	{Column: "result"},
+}
+
+var directoryAuditSelectProperties = graphSelectProperties{
+	"additional_details": {"additionalDetails"},
+	"initiated_by":       {"initiatedBy"},
+	"result":             {"result"},
+	"target_resources":   {"targetResources"},
+}
+
 //// LIST FUNCTION
 
 func listAdDirectoryAuditReports(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdDirectoryAuditReports")
+
 	// Create client
 	client, adapter, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -90,44 +98,12 @@ func listAdDirectoryAuditReports(ctx context.Context, d *plugin.QueryData, _ *pl
 		}
 	}
 
-	equalQuals := d.EqualsQuals
-
-	var queryFilter string
-	filter := buildDirectoryAuditQueryFilter(equalQuals)
-
-	// Filter by activityDateTime
-	if d.Quals["activity_date_time"] != nil {
-		for _, q := range d.Quals["activity_date_time"].Quals {
-			givenTime := q.Value.GetTimestampValue().AsTime()
-			//startTime = q.Value.GetTimestampValue().AsTime().Format(time.RFC3339)
-
-			switch q.Operator {
-			case ">":
-				startTime := givenTime.Add(time.Second * 1).Format(time.RFC3339)
-				filter = append(filter, fmt.Sprintf("activityDateTime ge %s", startTime))
-			case ">=":
-				filter = append(filter, fmt.Sprintf("activityDateTime ge %s", givenTime.Format(time.RFC3339)))
-			case "=":
-				filter = append(filter, fmt.Sprintf("activityDateTime eq %s", givenTime.Format(time.RFC3339)))
-			case "<=":
-				filter = append(filter, fmt.Sprintf("activityDateTime le %s", givenTime.Format(time.RFC3339)))
-			case "<":
-				startTime := givenTime.Add(time.Duration(-1) * time.Second).Format(time.RFC3339)
-				filter = append(filter, fmt.Sprintf("activityDateTime le %s", startTime))
-			}
-		}
-	}
-
-	if equalQuals["filter"] != nil {
-		queryFilter = equalQuals["filter"].GetStringValue()
+	if filter := buildODataFilter(d, directoryAuditFilterColumns); filter != "" {
+		input.Filter = &filter
 	}
 
-	if queryFilter != "" {
-		input.Filter = &queryFilter
-	} else if len(filter) > 0 {
-		joinStr := strings.Join(filter, " and ")
-		input.Filter = &joinStr
-	}
+	// Request only the properties of the queried columns
+	input.Select = buildSelect(d, directoryAuditSelectProperties)
 
 	options := &auditlogs.DirectoryAuditsRequestBuilderGetRequestConfiguration{
 		QueryParameters: input,
@@ -135,7 +111,7 @@ func listAdDirectoryAuditReports(ctx context.Context, d *plugin.QueryData, _ *pl
 
 	result, err := client.AuditLogs().DirectoryAudits().Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("listAdDirectoryAuditReports", "list_directory_audit_report_error", errObj)
 		return nil, errObj
 	}
@@ -157,8 +133,9 @@ func listAdDirectoryAuditReports(ctx context.Context, d *plugin.QueryData, _ *pl
 	})
 
 	if err != nil {
-		plugin.Logger(ctx).Error("listAdDirectoryAuditReports", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdDirectoryAuditReports", "paging_error", errObj)
+		return nil, errObj
 	}
 
 	return nil, nil
@@ -167,6 +144,8 @@ func listAdDirectoryAuditReports(ctx context.Context, d *plugin.QueryData, _ *pl
 //// HYDRATE FUNCTIONS
 
 func getAdDirectoryAuditReport(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdDirectoryAuditReport")
+
 	directoryAuditID := d.EqualsQuals["id"].GetStringValue()
 	if directoryAuditID == "" {
 		return nil, nil
@@ -179,31 +158,18 @@ func getAdDirectoryAuditReport(ctx context.Context, d *plugin.QueryData, h *plug
 		return nil, err
 	}
 
-	directoryAudit, err := client.AuditLogs().DirectoryAudits().ByDirectoryAuditId(directoryAuditID).Get(ctx, nil)
+	options := &auditlogs.DirectoryAuditsDirectoryAuditItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &auditlogs.DirectoryAuditsDirectoryAuditItemRequestBuilderGetQueryParameters{
+			Select: buildSelect(d, directoryAuditSelectProperties),
+		},
+	}
+
+	directoryAudit, err := client.AuditLogs().DirectoryAudits().ByDirectoryAuditId(directoryAuditID).Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getAdDirectoryAuditReport", "get_directory_audit_report_error", errObj)
 		return nil, errObj
 	}
 
 	return &ADDirectoryAuditReportInfo{directoryAudit}, nil
 }
-
-func buildDirectoryAuditQueryFilter(equalQuals plugin.KeyColumnEqualsQualMap) []string {
-	filters := []string{}
-
-	filterQuals := map[string]string{
-		"activity_display_name": "string",
-		"category":              "string",
-		"correlation_id":        "string",
-		"result":                "string",
-	}
-
-	for qual := range filterQuals {
-		if equalQuals[qual] != nil {
-			filters = append(filters, fmt.Sprintf("%s eq '%s'", strcase.ToCamel(qual), equalQuals[qual].GetStringValue()))
-		}
-	}
-
-	return filters
-}
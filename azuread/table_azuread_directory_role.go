@@ -22,13 +22,16 @@ func tableAzureAdDirectoryRole(_ context.Context) *plugin.Table {
 		Get: &plugin.GetConfig{
 			Hydrate: getAdDirectoryRole,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_ResourceNotFound", "Invalid object identifier"}),
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
 			},
 			KeyColumns: plugin.SingleColumn("id"),
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listAdDirectoryRoles,
 		},
+		HydrateConfig: []plugin.HydrateConfig{
+			{Func: getDirectoryRoleMembers, MaxConcurrency: maxBatchedHydrateConcurrency},
+		},
 
 		Columns: commonColumns([]*plugin.Column{
 			{Name: "id", Type: proto.ColumnType_STRING, Description: "The unique identifier for the directory role.", Transform: transform.FromMethod("GetId")},
@@ -51,9 +54,15 @@ type ADDirectoryRoleInfo struct {
 	models.DirectoryRoleable
 }
 
+var directoryRoleSelectProperties = graphSelectProperties{
+	"title": {"displayName", "id"},
+}
+
 //// LIST FUNCTION
 
 func listAdDirectoryRoles(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdDirectoryRoles")
+
 	// Create client
 	client, _, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -61,9 +70,15 @@ func listAdDirectoryRoles(ctx context.Context, d *plugin.QueryData, _ *plugin.Hy
 		return nil, err
 	}
 
-	result, err := client.DirectoryRoles().Get(ctx, nil)
+	options := &directoryroles.DirectoryRolesRequestBuilderGetRequestConfiguration{
+		QueryParameters: &directoryroles.DirectoryRolesRequestBuilderGetQueryParameters{
+			Select: buildSelect(d, directoryRoleSelectProperties),
+		},
+	}
+
+	result, err := client.DirectoryRoles().Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("listAdDirectoryRoles", "list_directory_role_error", errObj)
 		return nil, errObj
 	}
@@ -83,6 +98,8 @@ func listAdDirectoryRoles(ctx context.Context, d *plugin.QueryData, _ *plugin.Hy
 //// HYDRATE FUNCTIONS
 
 func getAdDirectoryRole(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdDirectoryRole")
+
 	directoryRoleId := d.EqualsQuals["id"].GetStringValue()
 	if directoryRoleId == "" {
 		return nil, nil
@@ -95,9 +112,15 @@ func getAdDirectoryRole(ctx context.Context, d *plugin.QueryData, h *plugin.Hydr
 		return nil, err
 	}
 
-	directoryRole, err := client.DirectoryRoles().ByDirectoryRoleId(directoryRoleId).Get(ctx, nil)
+	options := &directoryroles.DirectoryRoleItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &directoryroles.DirectoryRoleItemRequestBuilderGetQueryParameters{
+			Select: buildSelect(d, directoryRoleSelectProperties),
+		},
+	}
+
+	directoryRole, err := client.DirectoryRoles().ByDirectoryRoleId(directoryRoleId).Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getAdDirectoryRole", "get_directory_role_error", errObj)
 		return nil, errObj
 	}
@@ -106,6 +129,8 @@ func getAdDirectoryRole(ctx context.Context, d *plugin.QueryData, h *plugin.Hydr
 }
 
 func getDirectoryRoleMembers(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getDirectoryRoleMembers")
+
 	// Create client
 	client, adapter, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -129,9 +154,16 @@ func getDirectoryRoleMembers(ctx context.Context, d *plugin.QueryData, h *plugin
 	}
 
 	memberIds := []*string{}
-	members, err := client.DirectoryRoles().ByDirectoryRoleId(*directoryRoleID).Members().Get(ctx, config)
+	requestInfo, err := client.DirectoryRoles().ByDirectoryRoleId(*directoryRoleID).Members().ToGetRequestInformation(ctx, config)
 	if err != nil {
-		errObj := getErrorObject(err)
+		plugin.Logger(ctx).Error("getDirectoryRoleMembers", "request_information_error", err)
+		return nil, err
+	}
+
+	// The first page is requested in a batch with those of the other rows
+	members, err := batchGet[models.DirectoryObjectCollectionResponseable](ctx, d, requestInfo, models.CreateDirectoryObjectCollectionResponseFromDiscriminatorValue)
+	if err != nil {
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getDirectoryRoleMembers", "get_directory_role_members_error", errObj)
 		return nil, errObj
 	}
@@ -148,8 +180,9 @@ func getDirectoryRoleMembers(ctx context.Context, d *plugin.QueryData, h *plugin
 		return true
 	})
 	if err != nil {
-		plugin.Logger(ctx).Error("getDirectoryRoleMembers", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("getDirectoryRoleMembers", "paging_error", errObj)
+		return nil, errObj
 	}
 
 	return memberIds, nil
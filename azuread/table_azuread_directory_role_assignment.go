@@ -0,0 +1,225 @@
+package azuread
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/rolemanagement"
+	"github.com/turbot/go-kit/helpers"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAzureAdDirectoryRoleAssignment(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "azuread_directory_role_assignment",
+		Description: "Represents the assignment of an Azure AD role definition to a principal, at the scope of the tenant, an administrative unit, an object or an application.",
+		Get: &plugin.GetConfig{
+			Hydrate: getAdDirectoryRoleAssignment,
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
+			},
+			KeyColumns: plugin.SingleColumn("id"),
+		},
+		List: &plugin.ListConfig{
+			Hydrate: listAdDirectoryRoleAssignments,
+			KeyColumns: append(directoryRoleAssignmentFilterColumns.keyColumns(),
+				&plugin.KeyColumn{Name: "filter", Require: plugin.Optional},
+			),
+		},
+
+		Columns: commonColumns(slices.Concat(
+			[]*plugin.Column{
+				{Name: "id", Type: proto.ColumnType_STRING, Description: "The unique identifier for the role assignment.", Transform: transform.FromMethod("GetId")},
+			},
+			directoryRolePrincipalScopeColumns("assignment"),
+			[]*plugin.Column{
+				// Other fields
+				{Name: "filter", Type: proto.ColumnType_STRING, Transform: transform.FromQual("filter"), Description: "Odata query to search for resources."},
+				{Name: "condition", Type: proto.ColumnType_STRING, Description: "The condition of the role assignment, if any.", Transform: transform.FromMethod("GetCondition")},
+
+				// Standard columns
+				{Name: "title", Type: proto.ColumnType_STRING, Description: ColumnDescriptionTitle, Transform: transform.FromMethod("GetId")},
+			},
+		)),
+	}
+}
+
+// directoryRoleAssignmentFilterColumns are the columns whose quals are pushed down to the $filter of list requests.
+var directoryRoleAssignmentFilterColumns = directoryRoleFilterColumns(true)
+
+var directoryRoleAssignmentSelectProperties = directoryRolePrincipalSelectProperties(nil)
+
+// directoryRoleScheduleFilterColumns are the columns whose quals are pushed
+// down to the $filter of list requests of the PIM assignment and eligibility
+// schedules, instances and requests.
+var directoryRoleScheduleFilterColumns = directoryRoleFilterColumns(false)
+
+// directoryRolePrincipalScopeColumns returns the role definition, principal
+// and scope columns shared by the tables of role assignments, eligibilities
+// and schedules, described for the given kind of object, e.g. eligibility.
+func directoryRolePrincipalScopeColumns(kind string) []*plugin.Column {
+	return []*plugin.Column{
+		{Name: "role_definition_id", Type: proto.ColumnType_STRING, Description: fmt.Sprintf("The identifier of the role definition the %s is for.", kind), Transform: transform.FromMethod("GetRoleDefinitionId")},
+		{Name: "principal_id", Type: proto.ColumnType_STRING, Description: fmt.Sprintf("The identifier of the user, group or service principal the %s is for.", kind), Transform: transform.FromMethod("GetPrincipalId")},
+		{Name: "principal_type", Type: proto.ColumnType_STRING, Description: "The type of the principal, i.e. user, group or servicePrincipal.", Transform: transform.From(directoryRolePrincipalType)},
+		{Name: "principal_display_name", Type: proto.ColumnType_STRING, Description: "The display name of the principal.", Transform: transform.From(directoryRolePrincipalDisplayName)},
+		{Name: "directory_scope_id", Type: proto.ColumnType_STRING, Description: fmt.Sprintf("The identifier of the directory object the %s is scoped to, e.g. an administrative unit, or / for the whole tenant. Either this or app_scope_id is set.", kind), Transform: transform.FromMethod("GetDirectoryScopeId")},
+		{Name: "app_scope_id", Type: proto.ColumnType_STRING, Description: fmt.Sprintf("The identifier of the app specific scope the %s is scoped to, when it does not apply to directory objects. Either this or directory_scope_id is set.", kind), Transform: transform.FromMethod("GetAppScopeId")},
+	}
+}
+
+// directoryRoleFilterColumns returns the filter columns of the role definition,
+// principal and scope columns. Only role assignments support the in operator.
+func directoryRoleFilterColumns(in bool) odataFilterColumns {
+	return odataFilterColumns{
+		{Column: "role_definition_id", In: in},
+		{Column: "principal_id", In: in},
+		{Column: "directory_scope_id"},
+		{Column: "app_scope_id"},
+	}
+}
+
+// directoryRolePrincipalSelectProperties returns the select properties of a
+// table of role assignments, eligibilities or schedules, i.e. the given
+// properties and those of the principal columns, which are expanded rather
+// than selected.
+func directoryRolePrincipalSelectProperties(properties graphSelectProperties) graphSelectProperties {
+	selectProperties := graphSelectProperties{
+		"principal_type":         {},
+		"principal_display_name": {},
+	}
+	for column, graphProperties := range properties {
+		selectProperties[column] = graphProperties
+	}
+	return selectProperties
+}
+
+// directoryRolePrincipalExpand returns the $expand of the requests of role
+// assignments, eligibilities and schedules, i.e. the principal if any of its
+// columns are queried.
+func directoryRolePrincipalExpand(d *plugin.QueryData) []string {
+	for _, column := range []string{"principal_type", "principal_display_name"} {
+		if helpers.StringSliceContains(d.QueryContext.Columns, column) {
+			return []string{"principal"}
+		}
+	}
+	return nil
+}
+
+//// LIST FUNCTION
+
+func listAdDirectoryRoleAssignments(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdDirectoryRoleAssignments")
+
+	// Create client
+	client, adapter, err := GetGraphClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("azuread_directory_role_assignment.listAdDirectoryRoleAssignments", "connection_error", err)
+		return nil, err
+	}
+
+	input := &rolemanagement.DirectoryRoleAssignmentsRequestBuilderGetQueryParameters{
+		Select: buildSelect(d, directoryRoleAssignmentSelectProperties),
+		Expand: directoryRolePrincipalExpand(d),
+	}
+	if filter := buildODataFilter(d, directoryRoleAssignmentFilterColumns); filter != "" {
+		input.Filter = &filter
+	}
+
+	options := &rolemanagement.DirectoryRoleAssignmentsRequestBuilderGetRequestConfiguration{
+		QueryParameters: input,
+	}
+
+	result, err := client.RoleManagement().Directory().RoleAssignments().Get(ctx, options)
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdDirectoryRoleAssignments", "list_directory_role_assignment_error", errObj)
+		return nil, errObj
+	}
+
+	pageIterator, err := msgraphcore.NewPageIterator[models.UnifiedRoleAssignmentable](result, adapter, models.CreateUnifiedRoleAssignmentCollectionResponseFromDiscriminatorValue)
+	if err != nil {
+		plugin.Logger(ctx).Error("listAdDirectoryRoleAssignments", "create_iterator_instance_error", err)
+		return nil, err
+	}
+
+	err = pageIterator.Iterate(ctx, func(pageItem models.UnifiedRoleAssignmentable) bool {
+		d.StreamListItem(ctx, &ADDirectoryRoleAssignmentInfo{pageItem})
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdDirectoryRoleAssignments", "paging_error", errObj)
+		return nil, errObj
+	}
+
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getAdDirectoryRoleAssignment(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdDirectoryRoleAssignment")
+
+	roleAssignmentId := d.EqualsQuals["id"].GetStringValue()
+	if roleAssignmentId == "" {
+		return nil, nil
+	}
+
+	// Create client
+	client, _, err := GetGraphClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("azuread_directory_role_assignment.getAdDirectoryRoleAssignment", "connection_error", err)
+		return nil, err
+	}
+
+	options := &rolemanagement.DirectoryRoleAssignmentsUnifiedRoleAssignmentItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &rolemanagement.DirectoryRoleAssignmentsUnifiedRoleAssignmentItemRequestBuilderGetQueryParameters{
+			Select: buildSelect(d, directoryRoleAssignmentSelectProperties),
+			Expand: directoryRolePrincipalExpand(d),
+		},
+	}
+
+	roleAssignment, err := client.RoleManagement().Directory().RoleAssignments().ByUnifiedRoleAssignmentId(roleAssignmentId).Get(ctx, options)
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("getAdDirectoryRoleAssignment", "get_directory_role_assignment_error", errObj)
+		return nil, errObj
+	}
+
+	return &ADDirectoryRoleAssignmentInfo{roleAssignment}, nil
+}
+
+//// TRANSFORM FUNCTIONS
+
+// directoryRolePrincipal is a role assignment, eligibility or schedule with its
+// expanded principal.
+type directoryRolePrincipal interface {
+	GetPrincipal() models.DirectoryObjectable
+}
+
+func directoryRolePrincipalType(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	item, ok := d.HydrateItem.(directoryRolePrincipal)
+	if !ok {
+		return nil, nil
+	}
+	return directoryObjectType(item.GetPrincipal()), nil
+}
+
+func directoryRolePrincipalDisplayName(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	item, ok := d.HydrateItem.(directoryRolePrincipal)
+	if !ok || item.GetPrincipal() == nil {
+		return nil, nil
+	}
+	return directoryObjectDisplayName(item.GetPrincipal()), nil
+}
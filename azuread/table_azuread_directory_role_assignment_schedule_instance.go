@@ -0,0 +1,145 @@
+package azuread
+
+import (
+	"context"
+	"slices"
+
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/rolemanagement"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAzureAdDirectoryRoleAssignmentScheduleInstance(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "azuread_directory_role_assignment_schedule_instance",
+		Description: "Represents a current or upcoming Privileged Identity Management assignment of an Azure AD role to a principal, either assigned or activated from an eligibility.",
+		Get: &plugin.GetConfig{
+			Hydrate: getAdDirectoryRoleAssignmentScheduleInstance,
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
+			},
+			KeyColumns: plugin.SingleColumn("id"),
+		},
+		List: &plugin.ListConfig{
+			Hydrate: listAdDirectoryRoleAssignmentScheduleInstances,
+			KeyColumns: append(directoryRoleScheduleFilterColumns.keyColumns(),
+				&plugin.KeyColumn{Name: "filter", Require: plugin.Optional},
+			),
+		},
+
+		Columns: commonColumns(slices.Concat(
+			[]*plugin.Column{
+				{Name: "id", Type: proto.ColumnType_STRING, Description: "The unique identifier for the assignment schedule instance.", Transform: transform.FromMethod("GetId")},
+			},
+			directoryRolePrincipalScopeColumns("assignment"),
+			[]*plugin.Column{
+				{Name: "assignment_type", Type: proto.ColumnType_STRING, Description: "How the role is assigned, i.e. Assigned, or Activated if the principal activated an eligible assignment.", Transform: transform.FromMethod("GetAssignmentType")},
+				{Name: "member_type", Type: proto.ColumnType_STRING, Description: "How the principal is assigned the role, i.e. Direct, or Group if it is assigned through a group.", Transform: transform.FromMethod("GetMemberType")},
+				{Name: "start_date_time", Type: proto.ColumnType_TIMESTAMP, Description: "When the assignment starts, i.e. when the role was activated for an activated assignment.", Transform: transform.FromMethod("GetStartDateTime")},
+				{Name: "end_date_time", Type: proto.ColumnType_TIMESTAMP, Description: "When the assignment ends, null if it does not expire.", Transform: transform.FromMethod("GetEndDateTime")},
+
+				// Other fields
+				{Name: "filter", Type: proto.ColumnType_STRING, Transform: transform.FromQual("filter"), Description: "Odata query to search for resources."},
+				{Name: "role_assignment_origin_id", Type: proto.ColumnType_STRING, Description: "The identifier of the role assignment in Microsoft Entra ID.", Transform: transform.FromMethod("GetRoleAssignmentOriginId")},
+				{Name: "role_assignment_schedule_id", Type: proto.ColumnType_STRING, Description: "The identifier of the assignment schedule the instance is an occurrence of.", Transform: transform.FromMethod("GetRoleAssignmentScheduleId")},
+
+				// Standard columns
+				{Name: "title", Type: proto.ColumnType_STRING, Description: ColumnDescriptionTitle, Transform: transform.FromMethod("GetId")},
+			},
+		)),
+	}
+}
+
+var directoryRoleAssignmentScheduleInstanceSelectProperties = directoryRolePrincipalSelectProperties(nil)
+
+//// LIST FUNCTION
+
+func listAdDirectoryRoleAssignmentScheduleInstances(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdDirectoryRoleAssignmentScheduleInstances")
+
+	// Create client
+	client, adapter, err := GetGraphClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("azuread_directory_role_assignment_schedule_instance.listAdDirectoryRoleAssignmentScheduleInstances", "connection_error", err)
+		return nil, err
+	}
+
+	input := &rolemanagement.DirectoryRoleAssignmentScheduleInstancesRequestBuilderGetQueryParameters{
+		Select: buildSelect(d, directoryRoleAssignmentScheduleInstanceSelectProperties),
+		Expand: directoryRolePrincipalExpand(d),
+	}
+	if filter := buildODataFilter(d, directoryRoleScheduleFilterColumns); filter != "" {
+		input.Filter = &filter
+	}
+
+	options := &rolemanagement.DirectoryRoleAssignmentScheduleInstancesRequestBuilderGetRequestConfiguration{
+		QueryParameters: input,
+	}
+
+	result, err := client.RoleManagement().Directory().RoleAssignmentScheduleInstances().Get(ctx, options)
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdDirectoryRoleAssignmentScheduleInstances", "list_directory_role_assignment_schedule_instance_error", errObj)
+		return nil, errObj
+	}
+
+	pageIterator, err := msgraphcore.NewPageIterator[models.UnifiedRoleAssignmentScheduleInstanceable](result, adapter, models.CreateUnifiedRoleAssignmentScheduleInstanceCollectionResponseFromDiscriminatorValue)
+	if err != nil {
+		plugin.Logger(ctx).Error("listAdDirectoryRoleAssignmentScheduleInstances", "create_iterator_instance_error", err)
+		return nil, err
+	}
+
+	err = pageIterator.Iterate(ctx, func(pageItem models.UnifiedRoleAssignmentScheduleInstanceable) bool {
+		d.StreamListItem(ctx, &ADDirectoryRoleAssignmentScheduleInstanceInfo{pageItem})
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdDirectoryRoleAssignmentScheduleInstances", "paging_error", errObj)
+		return nil, errObj
+	}
+
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getAdDirectoryRoleAssignmentScheduleInstance(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdDirectoryRoleAssignmentScheduleInstance")
+
+	instanceId := d.EqualsQuals["id"].GetStringValue()
+	if instanceId == "" {
+		return nil, nil
+	}
+
+	// Create client
+	client, _, err := GetGraphClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("azuread_directory_role_assignment_schedule_instance.getAdDirectoryRoleAssignmentScheduleInstance", "connection_error", err)
+		return nil, err
+	}
+
+	options := &rolemanagement.DirectoryRoleAssignmentScheduleInstancesUnifiedRoleAssignmentScheduleInstanceItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &rolemanagement.DirectoryRoleAssignmentScheduleInstancesUnifiedRoleAssignmentScheduleInstanceItemRequestBuilderGetQueryParameters{
+			Select: buildSelect(d, directoryRoleAssignmentScheduleInstanceSelectProperties),
+			Expand: directoryRolePrincipalExpand(d),
+		},
+	}
+
+	instance, err := client.RoleManagement().Directory().RoleAssignmentScheduleInstances().ByUnifiedRoleAssignmentScheduleInstanceId(instanceId).Get(ctx, options)
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("getAdDirectoryRoleAssignmentScheduleInstance", "get_directory_role_assignment_schedule_instance_error", errObj)
+		return nil, errObj
+	}
+
+	return &ADDirectoryRoleAssignmentScheduleInstanceInfo{instance}, nil
+}
@@ -0,0 +1,161 @@
+package azuread
+
+import (
+	"context"
+	"slices"
+
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/rolemanagement"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAzureAdDirectoryRoleAssignmentScheduleRequest(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "azuread_directory_role_assignment_schedule_request",
+		Description: "Represents a Privileged Identity Management request to assign, activate, extend, renew or remove an Azure AD role assignment.",
+		Get: &plugin.GetConfig{
+			Hydrate: getAdDirectoryRoleAssignmentScheduleRequest,
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
+			},
+			KeyColumns: plugin.SingleColumn("id"),
+		},
+		List: &plugin.ListConfig{
+			Hydrate: listAdDirectoryRoleAssignmentScheduleRequests,
+			KeyColumns: append(directoryRoleScheduleFilterColumns.keyColumns(),
+				&plugin.KeyColumn{Name: "filter", Require: plugin.Optional},
+			),
+		},
+
+		Columns: commonColumns(slices.Concat(
+			[]*plugin.Column{
+				{Name: "id", Type: proto.ColumnType_STRING, Description: "The unique identifier for the assignment schedule request.", Transform: transform.FromMethod("GetId")},
+				{Name: "action", Type: proto.ColumnType_STRING, Description: "The operation requested, e.g. adminAssign, selfActivate, selfDeactivate, adminRemove, selfExtend or selfRenew.", Transform: transform.FromMethod("AssignmentScheduleRequestAction")},
+				{Name: "status", Type: proto.ColumnType_STRING, Description: "The status of the request, e.g. Provisioned, PendingApproval, Denied or Revoked.", Transform: transform.FromMethod("GetStatus")},
+			},
+			directoryRolePrincipalScopeColumns("request"),
+			[]*plugin.Column{
+				{Name: "justification", Type: proto.ColumnType_STRING, Description: "The message given by the requester for the request.", Transform: transform.FromMethod("GetJustification")},
+				{Name: "start_date_time", Type: proto.ColumnType_TIMESTAMP, Description: "When the requested assignment starts.", Transform: transform.FromMethod("AssignmentScheduleRequestStartDateTime")},
+				{Name: "end_date_time", Type: proto.ColumnType_TIMESTAMP, Description: "When the requested assignment ends, null if it does not expire or expires after a duration.", Transform: transform.FromMethod("AssignmentScheduleRequestEndDateTime")},
+				{Name: "created_date_time", Type: proto.ColumnType_TIMESTAMP, Description: "When the request was made.", Transform: transform.FromMethod("GetCreatedDateTime")},
+
+				// Other fields
+				{Name: "filter", Type: proto.ColumnType_STRING, Transform: transform.FromQual("filter"), Description: "Odata query to search for resources."},
+				{Name: "completed_date_time", Type: proto.ColumnType_TIMESTAMP, Description: "When the request was completed.", Transform: transform.FromMethod("GetCompletedDateTime")},
+				{Name: "approval_id", Type: proto.ColumnType_STRING, Description: "The identifier of the approval of the request, if it needs one.", Transform: transform.FromMethod("GetApprovalId")},
+				{Name: "target_schedule_id", Type: proto.ColumnType_STRING, Description: "The identifier of the assignment schedule created or changed by the request.", Transform: transform.FromMethod("GetTargetScheduleId")},
+				{Name: "is_validation_only", Type: proto.ColumnType_BOOL, Description: "True if the request was only validated, rather than carried out.", Transform: transform.FromMethod("GetIsValidationOnly")},
+
+				// JSON fields
+				{Name: "created_by", Type: proto.ColumnType_JSON, Description: "The user or application which made the request, e.g. the principal who activated an eligible role.", Transform: transform.FromMethod("AssignmentScheduleRequestCreatedBy")},
+				{Name: "schedule_info", Type: proto.ColumnType_JSON, Description: "The requested period of the assignment, with its start and its expiration.", Transform: transform.FromMethod("AssignmentScheduleRequestScheduleInfo")},
+				{Name: "ticket_info", Type: proto.ColumnType_JSON, Description: "The ticket number and system given by the requester, if any.", Transform: transform.FromMethod("AssignmentScheduleRequestTicketInfo")},
+
+				// Standard columns
+				{Name: "title", Type: proto.ColumnType_STRING, Description: ColumnDescriptionTitle, Transform: transform.FromMethod("GetId")},
+			},
+		)),
+	}
+}
+
+var directoryRoleAssignmentScheduleRequestSelectProperties = directoryRolePrincipalSelectProperties(graphSelectProperties{
+	"action":          {"action"},
+	"start_date_time": {"scheduleInfo"},
+	"end_date_time":   {"scheduleInfo"},
+	"created_by":      {"createdBy"},
+	"schedule_info":   {"scheduleInfo"},
+	"ticket_info":     {"ticketInfo"},
+})
+
+//// LIST FUNCTION
+
+func listAdDirectoryRoleAssignmentScheduleRequests(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdDirectoryRoleAssignmentScheduleRequests")
+
+	// Create client
+	client, adapter, err := GetGraphClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("azuread_directory_role_assignment_schedule_request.listAdDirectoryRoleAssignmentScheduleRequests", "connection_error", err)
+		return nil, err
+	}
+
+	input := &rolemanagement.DirectoryRoleAssignmentScheduleRequestsRequestBuilderGetQueryParameters{
+		Select: buildSelect(d, directoryRoleAssignmentScheduleRequestSelectProperties),
+		Expand: directoryRolePrincipalExpand(d),
+	}
+	if filter := buildODataFilter(d, directoryRoleScheduleFilterColumns); filter != "" {
+		input.Filter = &filter
+	}
+
+	options := &rolemanagement.DirectoryRoleAssignmentScheduleRequestsRequestBuilderGetRequestConfiguration{
+		QueryParameters: input,
+	}
+
+	result, err := client.RoleManagement().Directory().RoleAssignmentScheduleRequests().Get(ctx, options)
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdDirectoryRoleAssignmentScheduleRequests", "list_directory_role_assignment_schedule_request_error", errObj)
+		return nil, errObj
+	}
+
+	pageIterator, err := msgraphcore.NewPageIterator[models.UnifiedRoleAssignmentScheduleRequestable](result, adapter, models.CreateUnifiedRoleAssignmentScheduleRequestCollectionResponseFromDiscriminatorValue)
+	if err != nil {
+		plugin.Logger(ctx).Error("listAdDirectoryRoleAssignmentScheduleRequests", "create_iterator_instance_error", err)
+		return nil, err
+	}
+
+	err = pageIterator.Iterate(ctx, func(pageItem models.UnifiedRoleAssignmentScheduleRequestable) bool {
+		d.StreamListItem(ctx, &ADDirectoryRoleAssignmentScheduleRequestInfo{pageItem})
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdDirectoryRoleAssignmentScheduleRequests", "paging_error", errObj)
+		return nil, errObj
+	}
+
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getAdDirectoryRoleAssignmentScheduleRequest(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdDirectoryRoleAssignmentScheduleRequest")
+
+	requestId := d.EqualsQuals["id"].GetStringValue()
+	if requestId == "" {
+		return nil, nil
+	}
+
+	// Create client
+	client, _, err := GetGraphClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("azuread_directory_role_assignment_schedule_request.getAdDirectoryRoleAssignmentScheduleRequest", "connection_error", err)
+		return nil, err
+	}
+
+	options := &rolemanagement.DirectoryRoleAssignmentScheduleRequestsUnifiedRoleAssignmentScheduleRequestItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &rolemanagement.DirectoryRoleAssignmentScheduleRequestsUnifiedRoleAssignmentScheduleRequestItemRequestBuilderGetQueryParameters{
+			Select: buildSelect(d, directoryRoleAssignmentScheduleRequestSelectProperties),
+			Expand: directoryRolePrincipalExpand(d),
+		},
+	}
+
+	request, err := client.RoleManagement().Directory().RoleAssignmentScheduleRequests().ByUnifiedRoleAssignmentScheduleRequestId(requestId).Get(ctx, options)
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("getAdDirectoryRoleAssignmentScheduleRequest", "get_directory_role_assignment_schedule_request_error", errObj)
+		return nil, errObj
+	}
+
+	return &ADDirectoryRoleAssignmentScheduleRequestInfo{request}, nil
+}
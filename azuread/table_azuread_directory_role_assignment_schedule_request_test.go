@@ -0,0 +1,63 @@
+package azuread
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDirectoryRoleAssignmentScheduleRequests(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("roleManagement/directory/roleAssignmentScheduleRequests",
+		map[string]interface{}{
+			"id":               "rar1",
+			"action":           "selfActivate",
+			"status":           "Provisioned",
+			"roleDefinitionId": "rd1",
+			"principalId":      "u1",
+			"directoryScopeId": "/",
+			"justification":    "Incident 42",
+			"createdBy": map[string]interface{}{
+				"user": map[string]interface{}{"id": "u1", "displayName": "Alice"},
+			},
+			"scheduleInfo": map[string]interface{}{
+				"startDateTime": "2024-03-01T08:00:00Z",
+				"expiration":    map[string]interface{}{"type": "afterDateTime", "endDateTime": "2024-03-01T16:00:00Z"},
+			},
+			"ticketInfo": map[string]interface{}{"ticketNumber": "INC42", "ticketSystem": "ServiceNow"},
+			"principal":  map[string]interface{}{"@odata.type": "#microsoft.graph.user", "id": "u1", "displayName": "Alice"},
+		},
+	)
+
+	columns := []string{"id", "action", "justification", "principal_type", "start_date_time", "end_date_time", "created_by", "ticket_info"}
+	rows, err := executeQuery(t, "azuread_directory_role_assignment_schedule_request", columns, nil)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(rows))
+	}
+	row := rows[0]
+	if row["action"].GetStringValue() != "selfActivate" || row["justification"].GetStringValue() != "Incident 42" || row["principal_type"].GetStringValue() != "user" {
+		t.Errorf("unexpected request %v", row)
+	}
+	if got := string(row["created_by"].GetJsonValue()); got != `{"user":{"displayName":"Alice","id":"u1"}}` {
+		t.Errorf("unexpected created_by %s", got)
+	}
+	if got := string(row["ticket_info"].GetJsonValue()); got != `{"ticketNumber":"INC42","ticketSystem":"ServiceNow"}` {
+		t.Errorf("unexpected ticket_info %s", got)
+	}
+
+	// The start and end are read from the requested schedule
+	start, end := row["start_date_time"].GetTimestampValue(), row["end_date_time"].GetTimestampValue()
+	if start == nil || !start.AsTime().Equal(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected start_date_time %v", start)
+	}
+	if end == nil || !end.AsTime().Equal(time.Date(2024, 3, 1, 16, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected end_date_time %v", end)
+	}
+
+	requests := standIn.requestsFor("roleManagement/directory/roleAssignmentScheduleRequests")
+	if len(requests) == 0 || requests[0].URL.Query().Get("$expand") != "principal" {
+		t.Errorf("expected the principal to be expanded, got %v", requests)
+	}
+}
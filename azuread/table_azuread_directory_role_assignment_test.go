@@ -0,0 +1,74 @@
+package azuread
+
+import (
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestDirectoryRoleAssignments(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("roleManagement/directory/roleAssignments",
+		map[string]interface{}{
+			"id":               "ra1",
+			"roleDefinitionId": "rd1",
+			"principalId":      "u1",
+			"directoryScopeId": "/",
+			"principal":        map[string]interface{}{"@odata.type": "#microsoft.graph.user", "id": "u1", "displayName": "Alice"},
+		},
+		map[string]interface{}{
+			"id":               "ra2",
+			"roleDefinitionId": "rd1",
+			"principalId":      "g1",
+			"directoryScopeId": "/administrativeUnits/au1",
+			"principal":        map[string]interface{}{"@odata.type": "#microsoft.graph.group", "id": "g1", "displayName": "Europe helpdesk"},
+		},
+		map[string]interface{}{
+			"id":               "ra3",
+			"roleDefinitionId": "rd2",
+			"principalId":      "sp1",
+			"appScopeId":       "/",
+			"principal":        map[string]interface{}{"@odata.type": "#microsoft.graph.servicePrincipal", "id": "sp1", "displayName": "Provisioning"},
+		},
+	)
+
+	// The principal is expanded for its columns
+	columns := []string{"id", "principal_id", "principal_type", "principal_display_name", "directory_scope_id", "app_scope_id"}
+	rows, err := executeQuery(t, "azuread_directory_role_assignment", columns, nil)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	assignments := map[string]map[string]*proto.Column{}
+	for _, row := range rows {
+		assignments[row["id"].GetStringValue()] = row
+	}
+	if len(assignments) != 3 {
+		t.Fatalf("expected 3 assignments, got %d", len(assignments))
+	}
+	if row := assignments["ra2"]; row["principal_type"].GetStringValue() != "group" || row["principal_display_name"].GetStringValue() != "Europe helpdesk" || row["directory_scope_id"].GetStringValue() != "/administrativeUnits/au1" {
+		t.Errorf("unexpected assignment %v", row)
+	}
+	if row := assignments["ra3"]; row["principal_type"].GetStringValue() != "servicePrincipal" || row["app_scope_id"].GetStringValue() != "/" {
+		t.Errorf("unexpected assignment %v", row)
+	}
+	requests := standIn.requestsFor("roleManagement/directory/roleAssignments")
+	if len(requests) == 0 || requests[0].URL.Query().Get("$expand") != "principal" {
+		t.Errorf("expected the principal to be expanded, got %v", requests)
+	}
+
+	// The principal is not expanded for the other columns, and the quals are pushed down
+	standIn.reset()
+	standIn.setCollection("roleManagement/directory/roleAssignments")
+	quals := equalsQuals(map[string]string{"principal_id": "u1", "role_definition_id": "rd1"})
+	if _, err := executeQuery(t, "azuread_directory_role_assignment", []string{"id", "role_definition_id", "principal_id"}, quals); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	requests = standIn.requestsFor("roleManagement/directory/roleAssignments")
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+	query := requests[0].URL.Query()
+	if query.Get("$filter") != "roleDefinitionId eq 'rd1' and principalId eq 'u1'" || query.Has("$expand") {
+		t.Errorf("unexpected query %v", query)
+	}
+}
@@ -0,0 +1,167 @@
+package azuread
+
+import (
+	"context"
+
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/rolemanagement"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAzureAdDirectoryRoleDefinition(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "azuread_directory_role_definition",
+		Description: "Represents a built-in or custom Azure AD role definition, i.e. a collection of permissions which can be assigned to a principal.",
+		Get: &plugin.GetConfig{
+			Hydrate: getAdDirectoryRoleDefinition,
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
+			},
+			KeyColumns: plugin.SingleColumn("id"),
+		},
+		List: &plugin.ListConfig{
+			Hydrate: listAdDirectoryRoleDefinitions,
+			KeyColumns: append(directoryRoleDefinitionFilterColumns.keyColumns(),
+				&plugin.KeyColumn{Name: "filter", Require: plugin.Optional},
+			),
+		},
+
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "id", Type: proto.ColumnType_STRING, Description: "The unique identifier for the role definition.", Transform: transform.FromMethod("GetId")},
+			{Name: "display_name", Type: proto.ColumnType_STRING, Description: "The display name for the role definition.", Transform: transform.FromMethod("GetDisplayName")},
+			{Name: "description", Type: proto.ColumnType_STRING, Description: "The description for the role definition.", Transform: transform.FromMethod("GetDescription")},
+			{Name: "is_built_in", Type: proto.ColumnType_BOOL, Description: "True if the role definition is part of the default set included in Microsoft Entra ID, false if it is a custom role.", Transform: transform.FromMethod("GetIsBuiltIn")},
+			{Name: "is_enabled", Type: proto.ColumnType_BOOL, Description: "True if the role is enabled. Built-in roles are always enabled.", Transform: transform.FromMethod("GetIsEnabled")},
+
+			// Other fields
+			{Name: "filter", Type: proto.ColumnType_STRING, Transform: transform.FromQual("filter"), Description: "Odata query to search for resources."},
+			{Name: "template_id", Type: proto.ColumnType_STRING, Description: "The custom template identifier of the role definition, or its id for built-in roles. It is the id of the directory role created when a built-in role is activated.", Transform: transform.FromMethod("GetTemplateId")},
+			{Name: "version", Type: proto.ColumnType_STRING, Description: "The version of the role definition.", Transform: transform.FromMethod("GetVersion")},
+
+			// JSON fields
+			{Name: "resource_scopes", Type: proto.ColumnType_JSON, Description: "The scopes of the permissions of the role definition, e.g. / for the whole tenant.", Transform: transform.FromMethod("GetResourceScopes")},
+			{Name: "role_permissions", Type: proto.ColumnType_JSON, Description: "The resource actions which are allowed, and excluded, by the role definition, and the conditions which must be met.", Transform: transform.FromMethod("RoleDefinitionRolePermissions")},
+
+			// Standard columns
+			{Name: "title", Type: proto.ColumnType_STRING, Description: ColumnDescriptionTitle, Transform: transform.From(adDirectoryRoleDefinitionTitle)},
+		}),
+	}
+}
+
+// directoryRoleDefinitionFilterColumns are the columns whose quals are pushed down to the $filter of list requests.
+var directoryRoleDefinitionFilterColumns = odataFilterColumns{
+	{Column: "display_name"},
+	{Column: "is_built_in", Type: odataBool},
+	{Column: "template_id"},
+}
+
+var directoryRoleDefinitionSelectProperties = graphSelectProperties{
+	"role_permissions": {"rolePermissions"},
+	"title":            {"displayName", "id"},
+}
+
+//// LIST FUNCTION
+
+func listAdDirectoryRoleDefinitions(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdDirectoryRoleDefinitions")
+
+	// Create client
+	client, adapter, err := GetGraphClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("azuread_directory_role_definition.listAdDirectoryRoleDefinitions", "connection_error", err)
+		return nil, err
+	}
+
+	input := &rolemanagement.DirectoryRoleDefinitionsRequestBuilderGetQueryParameters{
+		Select: buildSelect(d, directoryRoleDefinitionSelectProperties),
+	}
+	if filter := buildODataFilter(d, directoryRoleDefinitionFilterColumns); filter != "" {
+		input.Filter = &filter
+	}
+
+	options := &rolemanagement.DirectoryRoleDefinitionsRequestBuilderGetRequestConfiguration{
+		QueryParameters: input,
+	}
+
+	result, err := client.RoleManagement().Directory().RoleDefinitions().Get(ctx, options)
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdDirectoryRoleDefinitions", "list_directory_role_definition_error", errObj)
+		return nil, errObj
+	}
+
+	pageIterator, err := msgraphcore.NewPageIterator[models.UnifiedRoleDefinitionable](result, adapter, models.CreateUnifiedRoleDefinitionCollectionResponseFromDiscriminatorValue)
+	if err != nil {
+		plugin.Logger(ctx).Error("listAdDirectoryRoleDefinitions", "create_iterator_instance_error", err)
+		return nil, err
+	}
+
+	err = pageIterator.Iterate(ctx, func(pageItem models.UnifiedRoleDefinitionable) bool {
+		d.StreamListItem(ctx, &ADDirectoryRoleDefinitionInfo{pageItem})
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdDirectoryRoleDefinitions", "paging_error", errObj)
+		return nil, errObj
+	}
+
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getAdDirectoryRoleDefinition(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdDirectoryRoleDefinition")
+
+	roleDefinitionId := d.EqualsQuals["id"].GetStringValue()
+	if roleDefinitionId == "" {
+		return nil, nil
+	}
+
+	// Create client
+	client, _, err := GetGraphClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("azuread_directory_role_definition.getAdDirectoryRoleDefinition", "connection_error", err)
+		return nil, err
+	}
+
+	options := &rolemanagement.DirectoryRoleDefinitionsUnifiedRoleDefinitionItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &rolemanagement.DirectoryRoleDefinitionsUnifiedRoleDefinitionItemRequestBuilderGetQueryParameters{
+			Select: buildSelect(d, directoryRoleDefinitionSelectProperties),
+		},
+	}
+
+	roleDefinition, err := client.RoleManagement().Directory().RoleDefinitions().ByUnifiedRoleDefinitionId(roleDefinitionId).Get(ctx, options)
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("getAdDirectoryRoleDefinition", "get_directory_role_definition_error", errObj)
+		return nil, errObj
+	}
+
+	return &ADDirectoryRoleDefinitionInfo{roleDefinition}, nil
+}
+
+//// TRANSFORM FUNCTIONS
+
+func adDirectoryRoleDefinitionTitle(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	data := d.HydrateItem.(*ADDirectoryRoleDefinitionInfo)
+	if data == nil {
+		return nil, nil
+	}
+
+	title := data.GetDisplayName()
+	if title == nil {
+		title = data.GetId()
+	}
+
+	return title, nil
+}
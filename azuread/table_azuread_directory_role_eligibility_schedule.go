@@ -0,0 +1,153 @@
+package azuread
+
+import (
+	"context"
+	"slices"
+
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/rolemanagement"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAzureAdDirectoryRoleEligibilitySchedule(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "azuread_directory_role_eligibility_schedule",
+		Description: "Represents a Privileged Identity Management schedule of the eligibility of a principal for an Azure AD role, which the principal can activate.",
+		Get: &plugin.GetConfig{
+			Hydrate: getAdDirectoryRoleEligibilitySchedule,
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
+			},
+			KeyColumns: plugin.SingleColumn("id"),
+		},
+		List: &plugin.ListConfig{
+			Hydrate: listAdDirectoryRoleEligibilitySchedules,
+			KeyColumns: append(directoryRoleScheduleFilterColumns.keyColumns(),
+				&plugin.KeyColumn{Name: "filter", Require: plugin.Optional},
+			),
+		},
+
+		Columns: commonColumns(slices.Concat(
+			[]*plugin.Column{
+				{Name: "id", Type: proto.ColumnType_STRING, Description: "The unique identifier for the eligibility schedule.", Transform: transform.FromMethod("GetId")},
+			},
+			directoryRolePrincipalScopeColumns("eligibility"),
+			[]*plugin.Column{
+				{Name: "member_type", Type: proto.ColumnType_STRING, Description: "How the principal is eligible, i.e. Direct, or Group if it is eligible through a group.", Transform: transform.FromMethod("GetMemberType")},
+				{Name: "status", Type: proto.ColumnType_STRING, Description: "The status of the eligibility schedule, e.g. Provisioned.", Transform: transform.FromMethod("GetStatus")},
+				{Name: "start_date_time", Type: proto.ColumnType_TIMESTAMP, Description: "When the eligibility starts.", Transform: transform.FromMethod("EligibilityScheduleStartDateTime")},
+				{Name: "end_date_time", Type: proto.ColumnType_TIMESTAMP, Description: "When the eligibility ends, null if it does not expire.", Transform: transform.FromMethod("EligibilityScheduleEndDateTime")},
+
+				// Other fields
+				{Name: "filter", Type: proto.ColumnType_STRING, Transform: transform.FromQual("filter"), Description: "Odata query to search for resources."},
+				{Name: "created_date_time", Type: proto.ColumnType_TIMESTAMP, Description: "When the eligibility schedule was created.", Transform: transform.FromMethod("GetCreatedDateTime")},
+				{Name: "modified_date_time", Type: proto.ColumnType_TIMESTAMP, Description: "When the eligibility schedule was last modified.", Transform: transform.FromMethod("GetModifiedDateTime")},
+				{Name: "created_using", Type: proto.ColumnType_STRING, Description: "The identifier of the request which created the eligibility schedule.", Transform: transform.FromMethod("GetCreatedUsing")},
+
+				// JSON fields
+				{Name: "schedule_info", Type: proto.ColumnType_JSON, Description: "The period of the eligibility, with its start and its expiration.", Transform: transform.FromMethod("EligibilityScheduleScheduleInfo")},
+
+				// Standard columns
+				{Name: "title", Type: proto.ColumnType_STRING, Description: ColumnDescriptionTitle, Transform: transform.FromMethod("GetId")},
+			},
+		)),
+	}
+}
+
+var directoryRoleEligibilityScheduleSelectProperties = directoryRolePrincipalSelectProperties(graphSelectProperties{
+	"start_date_time": {"scheduleInfo"},
+	"end_date_time":   {"scheduleInfo"},
+	"schedule_info":   {"scheduleInfo"},
+})
+
+//// LIST FUNCTION
+
+func listAdDirectoryRoleEligibilitySchedules(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdDirectoryRoleEligibilitySchedules")
+
+	// Create client
+	client, adapter, err := GetGraphClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("azuread_directory_role_eligibility_schedule.listAdDirectoryRoleEligibilitySchedules", "connection_error", err)
+		return nil, err
+	}
+
+	input := &rolemanagement.DirectoryRoleEligibilitySchedulesRequestBuilderGetQueryParameters{
+		Select: buildSelect(d, directoryRoleEligibilityScheduleSelectProperties),
+		Expand: directoryRolePrincipalExpand(d),
+	}
+	if filter := buildODataFilter(d, directoryRoleScheduleFilterColumns); filter != "" {
+		input.Filter = &filter
+	}
+
+	options := &rolemanagement.DirectoryRoleEligibilitySchedulesRequestBuilderGetRequestConfiguration{
+		QueryParameters: input,
+	}
+
+	result, err := client.RoleManagement().Directory().RoleEligibilitySchedules().Get(ctx, options)
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdDirectoryRoleEligibilitySchedules", "list_directory_role_eligibility_schedule_error", errObj)
+		return nil, errObj
+	}
+
+	pageIterator, err := msgraphcore.NewPageIterator[models.UnifiedRoleEligibilityScheduleable](result, adapter, models.CreateUnifiedRoleEligibilityScheduleCollectionResponseFromDiscriminatorValue)
+	if err != nil {
+		plugin.Logger(ctx).Error("listAdDirectoryRoleEligibilitySchedules", "create_iterator_instance_error", err)
+		return nil, err
+	}
+
+	err = pageIterator.Iterate(ctx, func(pageItem models.UnifiedRoleEligibilityScheduleable) bool {
+		d.StreamListItem(ctx, &ADDirectoryRoleEligibilityScheduleInfo{pageItem})
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdDirectoryRoleEligibilitySchedules", "paging_error", errObj)
+		return nil, errObj
+	}
+
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getAdDirectoryRoleEligibilitySchedule(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdDirectoryRoleEligibilitySchedule")
+
+	scheduleId := d.EqualsQuals["id"].GetStringValue()
+	if scheduleId == "" {
+		return nil, nil
+	}
+
+	// Create client
+	client, _, err := GetGraphClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("azuread_directory_role_eligibility_schedule.getAdDirectoryRoleEligibilitySchedule", "connection_error", err)
+		return nil, err
+	}
+
+	options := &rolemanagement.DirectoryRoleEligibilitySchedulesUnifiedRoleEligibilityScheduleItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &rolemanagement.DirectoryRoleEligibilitySchedulesUnifiedRoleEligibilityScheduleItemRequestBuilderGetQueryParameters{
+			Select: buildSelect(d, directoryRoleEligibilityScheduleSelectProperties),
+			Expand: directoryRolePrincipalExpand(d),
+		},
+	}
+
+	schedule, err := client.RoleManagement().Directory().RoleEligibilitySchedules().ByUnifiedRoleEligibilityScheduleId(scheduleId).Get(ctx, options)
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("getAdDirectoryRoleEligibilitySchedule", "get_directory_role_eligibility_schedule_error", errObj)
+		return nil, errObj
+	}
+
+	return &ADDirectoryRoleEligibilityScheduleInfo{schedule}, nil
+}
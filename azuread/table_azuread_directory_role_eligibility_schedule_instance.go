@@ -0,0 +1,143 @@
+package azuread
+
+import (
+	"context"
+	"slices"
+
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/rolemanagement"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAzureAdDirectoryRoleEligibilityScheduleInstance(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "azuread_directory_role_eligibility_schedule_instance",
+		Description: "Represents a current or upcoming Privileged Identity Management eligibility of a principal for an Azure AD role, i.e. an occurrence of an eligibility schedule.",
+		Get: &plugin.GetConfig{
+			Hydrate: getAdDirectoryRoleEligibilityScheduleInstance,
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
+			},
+			KeyColumns: plugin.SingleColumn("id"),
+		},
+		List: &plugin.ListConfig{
+			Hydrate: listAdDirectoryRoleEligibilityScheduleInstances,
+			KeyColumns: append(directoryRoleScheduleFilterColumns.keyColumns(),
+				&plugin.KeyColumn{Name: "filter", Require: plugin.Optional},
+			),
+		},
+
+		Columns: commonColumns(slices.Concat(
+			[]*plugin.Column{
+				{Name: "id", Type: proto.ColumnType_STRING, Description: "The unique identifier for the eligibility schedule instance.", Transform: transform.FromMethod("GetId")},
+			},
+			directoryRolePrincipalScopeColumns("eligibility"),
+			[]*plugin.Column{
+				{Name: "member_type", Type: proto.ColumnType_STRING, Description: "How the principal is eligible, i.e. Direct, or Group if it is eligible through a group.", Transform: transform.FromMethod("GetMemberType")},
+				{Name: "start_date_time", Type: proto.ColumnType_TIMESTAMP, Description: "When the eligibility starts.", Transform: transform.FromMethod("GetStartDateTime")},
+				{Name: "end_date_time", Type: proto.ColumnType_TIMESTAMP, Description: "When the eligibility ends, null if it does not expire.", Transform: transform.FromMethod("GetEndDateTime")},
+
+				// Other fields
+				{Name: "filter", Type: proto.ColumnType_STRING, Transform: transform.FromQual("filter"), Description: "Odata query to search for resources."},
+				{Name: "role_eligibility_schedule_id", Type: proto.ColumnType_STRING, Description: "The identifier of the eligibility schedule the instance is an occurrence of.", Transform: transform.FromMethod("GetRoleEligibilityScheduleId")},
+
+				// Standard columns
+				{Name: "title", Type: proto.ColumnType_STRING, Description: ColumnDescriptionTitle, Transform: transform.FromMethod("GetId")},
+			},
+		)),
+	}
+}
+
+var directoryRoleEligibilityScheduleInstanceSelectProperties = directoryRolePrincipalSelectProperties(nil)
+
+//// LIST FUNCTION
+
+func listAdDirectoryRoleEligibilityScheduleInstances(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdDirectoryRoleEligibilityScheduleInstances")
+
+	// Create client
+	client, adapter, err := GetGraphClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("azuread_directory_role_eligibility_schedule_instance.listAdDirectoryRoleEligibilityScheduleInstances", "connection_error", err)
+		return nil, err
+	}
+
+	input := &rolemanagement.DirectoryRoleEligibilityScheduleInstancesRequestBuilderGetQueryParameters{
+		Select: buildSelect(d, directoryRoleEligibilityScheduleInstanceSelectProperties),
+		Expand: directoryRolePrincipalExpand(d),
+	}
+	if filter := buildODataFilter(d, directoryRoleScheduleFilterColumns); filter != "" {
+		input.Filter = &filter
+	}
+
+	options := &rolemanagement.DirectoryRoleEligibilityScheduleInstancesRequestBuilderGetRequestConfiguration{
+		QueryParameters: input,
+	}
+
+	result, err := client.RoleManagement().Directory().RoleEligibilityScheduleInstances().Get(ctx, options)
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdDirectoryRoleEligibilityScheduleInstances", "list_directory_role_eligibility_schedule_instance_error", errObj)
+		return nil, errObj
+	}
+
+	pageIterator, err := msgraphcore.NewPageIterator[models.UnifiedRoleEligibilityScheduleInstanceable](result, adapter, models.CreateUnifiedRoleEligibilityScheduleInstanceCollectionResponseFromDiscriminatorValue)
+	if err != nil {
+		plugin.Logger(ctx).Error("listAdDirectoryRoleEligibilityScheduleInstances", "create_iterator_instance_error", err)
+		return nil, err
+	}
+
+	err = pageIterator.Iterate(ctx, func(pageItem models.UnifiedRoleEligibilityScheduleInstanceable) bool {
+		d.StreamListItem(ctx, &ADDirectoryRoleEligibilityScheduleInstanceInfo{pageItem})
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdDirectoryRoleEligibilityScheduleInstances", "paging_error", errObj)
+		return nil, errObj
+	}
+
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getAdDirectoryRoleEligibilityScheduleInstance(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdDirectoryRoleEligibilityScheduleInstance")
+
+	instanceId := d.EqualsQuals["id"].GetStringValue()
+	if instanceId == "" {
+		return nil, nil
+	}
+
+	// Create client
+	client, _, err := GetGraphClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("azuread_directory_role_eligibility_schedule_instance.getAdDirectoryRoleEligibilityScheduleInstance", "connection_error", err)
+		return nil, err
+	}
+
+	options := &rolemanagement.DirectoryRoleEligibilityScheduleInstancesUnifiedRoleEligibilityScheduleInstanceItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &rolemanagement.DirectoryRoleEligibilityScheduleInstancesUnifiedRoleEligibilityScheduleInstanceItemRequestBuilderGetQueryParameters{
+			Select: buildSelect(d, directoryRoleEligibilityScheduleInstanceSelectProperties),
+			Expand: directoryRolePrincipalExpand(d),
+		},
+	}
+
+	instance, err := client.RoleManagement().Directory().RoleEligibilityScheduleInstances().ByUnifiedRoleEligibilityScheduleInstanceId(instanceId).Get(ctx, options)
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("getAdDirectoryRoleEligibilityScheduleInstance", "get_directory_role_eligibility_schedule_instance_error", errObj)
+		return nil, errObj
+	}
+
+	return &ADDirectoryRoleEligibilityScheduleInstanceInfo{instance}, nil
+}
@@ -0,0 +1,57 @@
+package azuread
+
+import (
+	"testing"
+)
+
+func TestDirectoryRoleEligibilitySchedules(t *testing.T) {
+	for table, path := range map[string]string{
+		"azuread_directory_role_eligibility_schedule":          "roleManagement/directory/roleEligibilitySchedules",
+		"azuread_directory_role_eligibility_schedule_instance": "roleManagement/directory/roleEligibilityScheduleInstances",
+	} {
+		standIn.reset()
+		standIn.setCollection(path,
+			map[string]interface{}{
+				"id":               "re1",
+				"roleDefinitionId": "rd1",
+				"principalId":      "g1",
+				"directoryScopeId": "/administrativeUnits/au1",
+				"memberType":       "Direct",
+				"principal":        map[string]interface{}{"@odata.type": "#microsoft.graph.group", "id": "g1", "displayName": "Europe helpdesk"},
+			},
+		)
+
+		// The principal is expanded for its columns
+		columns := []string{"id", "principal_id", "principal_type", "principal_display_name", "directory_scope_id"}
+		rows, err := executeQuery(t, table, columns, nil)
+		if err != nil {
+			t.Fatalf("%s: list failed: %v", table, err)
+		}
+		if len(rows) != 1 {
+			t.Fatalf("%s: expected 1 eligibility, got %d", table, len(rows))
+		}
+		if row := rows[0]; row["principal_type"].GetStringValue() != "group" || row["principal_display_name"].GetStringValue() != "Europe helpdesk" || row["directory_scope_id"].GetStringValue() != "/administrativeUnits/au1" {
+			t.Errorf("%s: unexpected eligibility %v", table, row)
+		}
+		requests := standIn.requestsFor(path)
+		if len(requests) == 0 || requests[0].URL.Query().Get("$expand") != "principal" {
+			t.Errorf("%s: expected the principal to be expanded, got %v", table, requests)
+		}
+
+		// The principal is not expanded for the other columns, and the quals are pushed down
+		standIn.reset()
+		standIn.setCollection(path)
+		quals := equalsQuals(map[string]string{"principal_id": "g1", "directory_scope_id": "/"})
+		if _, err := executeQuery(t, table, []string{"id", "principal_id", "directory_scope_id"}, quals); err != nil {
+			t.Fatalf("%s: list failed: %v", table, err)
+		}
+		requests = standIn.requestsFor(path)
+		if len(requests) != 1 {
+			t.Fatalf("%s: expected 1 request, got %d", table, len(requests))
+		}
+		query := requests[0].URL.Query()
+		if query.Get("$filter") != "principalId eq 'g1' and directoryScopeId eq '/'" || query.Has("$expand") {
+			t.Errorf("%s: unexpected query %v", table, query)
+		}
+	}
+}
@@ -39,6 +39,8 @@ func tableAzureAdDirectorySetting(_ context.Context) *plugin.Table {
 //// LIST FUNCTION
 
 func listAdDirectorySetting(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdDirectorySetting")
+
 	// Create client
 	client, adapter, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -48,7 +50,7 @@ func listAdDirectorySetting(ctx context.Context, d *plugin.QueryData, _ *plugin.
 
 	result, err := client.GroupSettings().Get(ctx, nil)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("listAdDirectorySetting", "list_directory_setting_error", errObj)
 		return nil, errObj
 	}
@@ -74,8 +76,9 @@ func listAdDirectorySetting(ctx context.Context, d *plugin.QueryData, _ *plugin.
 		return d.RowsRemaining(ctx) != 0
 	})
 	if err != nil {
-		plugin.Logger(ctx).Error("listAdDirectorySetting", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdDirectorySetting", "paging_error", errObj)
+		return nil, errObj
 	}
 
 	return nil, nil
@@ -84,6 +87,7 @@ func listAdDirectorySetting(ctx context.Context, d *plugin.QueryData, _ *plugin.
 //// HYDRATE FUNCTIONS
 
 func getAdDirectorySetting(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdDirectorySetting")
 
 	directorySettingID := d.EqualsQuals["id"].GetStringValue()
 	settingName := d.EqualsQuals["name"].GetStringValue()
@@ -100,7 +104,7 @@ func getAdDirectorySetting(ctx context.Context, d *plugin.QueryData, h *plugin.H
 
 	setting, err := client.GroupSettings().ByGroupSettingId(directorySettingID).Get(ctx, nil)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("azuread_directory_setting.getAdDirectorySetting", "get_directory_setting_error", errObj)
 		return nil, errObj
 	}
@@ -22,7 +22,7 @@ func tableAzureAdDomain(_ context.Context) *plugin.Table {
 		Get: &plugin.GetConfig{
 			Hydrate: getAdDomain,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_ResourceNotFound", "Invalid object identifier"}),
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
 			},
 			KeyColumns: plugin.SingleColumn("id"),
 		},
@@ -57,6 +57,8 @@ type ADDomainInfo struct {
 //// LIST FUNCTION
 
 func listAdDomains(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdDomains")
+
 	// Create client
 	client, adapter, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -79,13 +81,16 @@ func listAdDomains(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateDa
 		}
 	}
 
+	// Request only the properties of the queried columns
+	input.Select = buildSelect(d, nil)
+
 	options := &domains.DomainsRequestBuilderGetRequestConfiguration{
 		QueryParameters: input,
 	}
 
 	result, err := client.Domains().Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("listAdDomains", "list_domain_error", errObj)
 		return nil, errObj
 	}
@@ -103,8 +108,9 @@ func listAdDomains(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateDa
 		return d.RowsRemaining(ctx) != 0
 	})
 	if err != nil {
-		plugin.Logger(ctx).Error("listAdDomains", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdDomains", "paging_error", errObj)
+		return nil, errObj
 	}
 
 	return nil, nil
@@ -113,6 +119,8 @@ func listAdDomains(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateDa
 //// HYDRATE FUNCTIONS
 
 func getAdDomain(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdDomain")
+
 	domainId := d.EqualsQuals["id"].GetStringValue()
 	if domainId == "" {
 		return nil, nil
@@ -125,9 +133,15 @@ func getAdDomain(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData
 		return nil, err
 	}
 
-	domain, err := client.Domains().ByDomainId(domainId).Get(ctx, nil)
+	options := &domains.DomainItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &domains.DomainItemRequestBuilderGetQueryParameters{
+			Select: buildSelect(d, nil),
+		},
+	}
+
+	domain, err := client.Domains().ByDomainId(domainId).Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getAdDomain", "get_domain_error", errObj)
 		return nil, errObj
 	}
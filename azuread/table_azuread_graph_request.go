@@ -0,0 +1,135 @@
+package azuread
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAzureAdGraphRequest(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "azuread_graph_request",
+		Description: "Items returned by a GET request for any Microsoft Graph path, to query resources which have no table yet.",
+		List: &plugin.ListConfig{
+			Hydrate: listAdGraphRequest,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "path", Require: plugin.Required},
+				{Name: "filter", Require: plugin.Optional},
+				{Name: "select", Require: plugin.Optional},
+				{Name: "api_version", Require: plugin.Optional},
+			},
+		},
+
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "The path of the resource relative to the Graph API version, e.g. identity/conditionalAccess/authenticationStrength/policies. It may include OData query options, e.g. users?$top=10.", Transform: transform.FromQual("path")},
+			{Name: "filter", Type: proto.ColumnType_STRING, Description: "The OData $filter of the request.", Transform: transform.FromQual("filter")},
+			{Name: "select", Type: proto.ColumnType_STRING, Description: "The comma separated properties to return, i.e. the OData $select of the request.", Transform: transform.FromQual("select")},
+			{Name: "api_version", Type: proto.ColumnType_STRING, Description: "The Microsoft Graph API version of the request, v1.0 or beta. Defaults to the API version of the connection, see graph_api_version in the connection config.", Transform: transform.FromQual("api_version")},
+
+			// Json fields
+			{Name: "data", Type: proto.ColumnType_JSON, Description: "An item of the collection returned for the path, or the object returned if it is not a collection.", Transform: transform.FromValue()},
+		}),
+	}
+}
+
+// graphRequestPage is a response of the Graph request: a page of a collection, or a single object.
+type graphRequestPage map[string]interface{}
+
+//// LIST FUNCTION
+
+func listAdGraphRequest(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdGraphRequest")
+
+	// Create client
+	_, adapter, err := GetGraphClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("azuread_graph_request.listAdGraphRequest", "connection_error", err)
+		return nil, err
+	}
+
+	baseUrl := adapter.GetBaseUrl()
+	if apiVersion := d.EqualsQualString("api_version"); apiVersion != "" {
+		if apiVersion != graphApiVersionV1 && apiVersion != graphApiVersionBeta {
+			return nil, fmt.Errorf("invalid api_version %q, must be one of: %s, %s", apiVersion, graphApiVersionV1, graphApiVersionBeta)
+		}
+		baseUrl = graphBaseUrlForVersion(baseUrl, apiVersion)
+	}
+
+	requestUrl, err := buildGraphRequestUrl(baseUrl, d.EqualsQualString("path"), d.EqualsQualString("filter"), d.EqualsQualString("select"))
+	if err != nil {
+		plugin.Logger(ctx).Error("listAdGraphRequest", "invalid_path_error", err)
+		return nil, err
+	}
+
+	next := requestUrl.String()
+	for next != "" {
+		page := graphRequestPage{}
+		if err := getGraphJSON(ctx, adapter, next, &page); err != nil {
+			errObj := getErrorObject(d, err)
+			plugin.Logger(ctx).Error("listAdGraphRequest", "graph_request_error", errObj)
+			return nil, errObj
+		}
+
+		items, ok := page["value"].([]interface{})
+		if !ok {
+			// A single object, e.g. organization/<id>/branding
+			d.StreamListItem(ctx, map[string]interface{}(page))
+			return nil, nil
+		}
+		for _, item := range items {
+			d.StreamListItem(ctx, item)
+
+			// Context can be cancelled due to manual cancellation or the limit has been hit
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+
+		next, _ = page["@odata.nextLink"].(string)
+		if next != "" {
+			// The access token is only sent to the Graph endpoint
+			nextUrl, err := url.Parse(next)
+			if err != nil || nextUrl.Host != requestUrl.Host {
+				return nil, fmt.Errorf("unexpected next link %q", next)
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// buildGraphRequestUrl returns the URL of a Graph request for a path relative
+// to the base URL, with the $filter and $select query options if given. The
+// path must not be a URL, so that the access token is only sent to Graph.
+func buildGraphRequestUrl(baseUrl, path, filter, selectProperties string) (*url.URL, error) {
+	path = strings.TrimLeft(strings.TrimSpace(path), "/")
+	if path == "" {
+		return nil, fmt.Errorf("path must not be empty")
+	}
+	if strings.Contains(path, "://") {
+		return nil, fmt.Errorf("path must be relative to the Graph API version, e.g. users, got %q", path)
+	}
+
+	requestUrl, err := url.Parse(strings.TrimSuffix(baseUrl, "/") + "/" + path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %v", path, err)
+	}
+
+	query := requestUrl.Query()
+	if filter != "" {
+		query.Set("$filter", filter)
+	}
+	if selectProperties != "" {
+		query.Set("$select", selectProperties)
+	}
+	requestUrl.RawQuery = query.Encode()
+
+	return requestUrl, nil
+}
@@ -0,0 +1,95 @@
+package azuread
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildGraphRequestUrl(t *testing.T) {
+	got, err := buildGraphRequestUrl("https://graph.microsoft.com/v1.0", "/users?$top=5", "accountEnabled eq false", "id,displayName")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got.Path != "/v1.0/users" {
+		t.Errorf("unexpected path %q", got.Path)
+	}
+	query := got.Query()
+	if query.Get("$top") != "5" || query.Get("$filter") != "accountEnabled eq false" || query.Get("$select") != "id,displayName" {
+		t.Errorf("unexpected query %v", query)
+	}
+
+	for _, path := range []string{"", " / ", "https://example.com/users"} {
+		if _, err := buildGraphRequestUrl("https://graph.microsoft.com/v1.0", path, "", ""); err == nil {
+			t.Errorf("expected an error for path %q", path)
+		}
+	}
+}
+
+func TestGraphRequest(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("identity/conditionalAccess/namedLocations",
+		map[string]interface{}{"id": "l1", "displayName": "Head office"},
+		map[string]interface{}{"id": "l2", "displayName": "Branch offices"},
+		map[string]interface{}{"id": "l3", "displayName": "Blocked countries"},
+	)
+	standIn.setObject("organization/o1/branding", map[string]interface{}{"signInPageText": "Welcome"})
+
+	columns := []string{"path", "filter", "select", "api_version", "data"}
+
+	// Collections are paged
+	quals := equalsQuals(map[string]string{
+		"path":        "identity/conditionalAccess/namedLocations",
+		"filter":      "startswith(displayName, 'B')",
+		"select":      "id,displayName",
+		"api_version": "beta",
+	})
+	rows, err := executeQuery(t, "azuread_graph_request", columns, quals)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(rows))
+	}
+	found := false
+	for _, row := range rows {
+		found = found || strings.Contains(string(row["data"].GetJsonValue()), `"displayName":"Head office"`)
+	}
+	if !found {
+		t.Errorf("expected the named locations as data, got %v", rows)
+	}
+	requests := standIn.requestsFor("identity/conditionalAccess/namedLocations")
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	query := requests[0].URL.Query()
+	if query.Get("$filter") != "startswith(displayName, 'B')" || query.Get("$select") != "id,displayName" {
+		t.Errorf("unexpected query %v", query)
+	}
+	if !strings.HasPrefix(requests[0].URL.Path, "/beta/") {
+		t.Errorf("expected a beta request, got %s", requests[0].URL.Path)
+	}
+
+	// Single objects are returned as one row
+	rows, err = executeQuery(t, "azuread_graph_request", columns, equalsQuals(map[string]string{"path": "organization/o1/branding"}))
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if len(rows) != 1 || !strings.Contains(string(rows[0]["data"].GetJsonValue()), `"signInPageText":"Welcome"`) {
+		t.Errorf("unexpected rows %v", rows)
+	}
+
+	// Invalid requests
+	for _, tc := range []struct {
+		quals map[string]string
+		err   string
+	}{
+		{map[string]string{"path": "https://example.com/users"}, "path must be relative"},
+		{map[string]string{"path": "users", "api_version": "v2.0"}, "invalid api_version"},
+		{map[string]string{"path": "deviceManagement/managedDevices"}, "Request_ResourceNotFound"},
+	} {
+		_, err := executeQuery(t, "azuread_graph_request", columns, equalsQuals(tc.quals))
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%v: expected an error containing %q, got %v", tc.quals, tc.err, err)
+		}
+	}
+}
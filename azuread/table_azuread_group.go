@@ -2,10 +2,6 @@ package azuread
 
 import (
 	"context"
-	"fmt"
-	"strings"
-
-	"github.com/iancoleman/strcase"
 
 	abstractions "github.com/microsoft/kiota-abstractions-go"
 	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
@@ -26,30 +22,31 @@ func tableAzureAdGroup(_ context.Context) *plugin.Table {
 		Get: &plugin.GetConfig{
 			Hydrate: getAdGroup,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_ResourceNotFound", "Invalid object identifier"}),
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
 			},
 			KeyColumns: plugin.SingleColumn("id"),
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listAdGroups,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Invalid filter clause"}),
-			},
-			KeyColumns: plugin.KeyColumnSlice{
-				// Key fields
-				{Name: "display_name", Require: plugin.Optional},
-				{Name: "filter", Require: plugin.Optional},
-				{Name: "mail", Require: plugin.Optional},
-				{Name: "mail_enabled", Require: plugin.Optional, Operators: []string{"<>", "="}},
-				{Name: "on_premises_sync_enabled", Require: plugin.Optional, Operators: []string{"<>", "="}},
-				{Name: "security_enabled", Require: plugin.Optional, Operators: []string{"<>", "="}},
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryInvalidFilter),
 			},
+			KeyColumns: append(groupFilterColumns.keyColumns(),
+				&plugin.KeyColumn{Name: "filter", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "search", Require: plugin.Optional},
+			),
+		},
+		HydrateConfig: []plugin.HydrateConfig{
+			{Func: getAdGroupIsSubscribedByMail, MaxConcurrency: maxBatchedHydrateConcurrency},
+			{Func: getAdGroupMembers, MaxConcurrency: maxBatchedHydrateConcurrency},
+			{Func: getAdGroupOwners, MaxConcurrency: maxBatchedHydrateConcurrency},
 		},
 		Columns: commonColumns([]*plugin.Column{
 			{Name: "display_name", Type: proto.ColumnType_STRING, Description: "The name displayed in the address book for the user. This is usually the combination of the user's first name, middle initial and last name.", Transform: transform.FromMethod("GetDisplayName")},
 			{Name: "id", Type: proto.ColumnType_STRING, Description: "The unique identifier for the group.", Transform: transform.FromMethod("GetId")},
 			{Name: "description", Type: proto.ColumnType_STRING, Description: "An optional description for the group.", Transform: transform.FromMethod("GetDescription")},
 			{Name: "filter", Type: proto.ColumnType_STRING, Transform: transform.FromQual("filter"), Description: "Odata query to search for groups."},
+			{Name: "search", Type: proto.ColumnType_STRING, Transform: transform.FromQual("search"), Description: "Term to search for in the display name of the group, or an OData $search expression such as \"displayName:Ada\"."},
 
 			// Other fields
 			{Name: "classification", Type: proto.ColumnType_STRING, Description: "Describes a classification for the group (such as low, medium or high business impact).", Transform: transform.FromMethod("GetClassification")},
@@ -72,6 +69,7 @@ func tableAzureAdGroup(_ context.Context) *plugin.Table {
 			{Name: "security_enabled", Type: proto.ColumnType_BOOL, Description: "Specifies whether the group is a security group.", Transform: transform.FromMethod("GetSecurityEnabled")},
 			{Name: "security_identifier", Type: proto.ColumnType_STRING, Description: "Security identifier of the group, used in Windows scenarios.", Transform: transform.FromMethod("GetSecurityIdentifier")},
 			{Name: "visibility", Type: proto.ColumnType_STRING, Description: "Specifies the group join policy and group content visibility for groups. Possible values are: Private, Public, or Hiddenmembership.", Transform: transform.FromMethod("GetVisibility")},
+			{Name: "last_change_time", Type: proto.ColumnType_TIMESTAMP, Description: "The time the delta sync last saw the group change, i.e. of the sync which first returned it or last returned changes to it. Null unless the table is listed in delta_sync_tables in the connection config.", Transform: transform.FromField("LastChangeTime")},
 
 			// JSON fields
 			{Name: "assigned_labels", Type: proto.ColumnType_JSON, Description: "The list of sensitivity label pairs (label ID, label name) associated with a Microsoft 365 group.", Transform: transform.FromMethod("GroupAssignedLabels")},
@@ -82,6 +80,9 @@ func tableAzureAdGroup(_ context.Context) *plugin.Table {
 			{Name: "resource_behavior_options", Type: proto.ColumnType_JSON, Description: "Specifies the group behaviors that can be set for a Microsoft 365 group during creation. Possible values are AllowOnlyMembersToPost, HideGroupInOutlook, SubscribeNewGroupMembers, WelcomeEmailDisabled."},
 			{Name: "resource_provisioning_options", Type: proto.ColumnType_JSON, Description: "Specifies the group resources that are provisioned as part of Microsoft 365 group creation, that are not normally part of default group creation. Possible value is Team."},
 
+			// Beta fields
+			{Name: "beta_properties", Type: proto.ColumnType_JSON, Description: betaColumnDescription("Properties of the group only returned by the beta endpoint."), Hydrate: getGraphBetaProperties, Transform: transform.FromValue()},
+
 			// Standard columns
 			{Name: "tags", Type: proto.ColumnType_JSON, Description: ColumnDescriptionTags, Transform: transform.From(adGroupTags)},
 			{Name: "title", Type: proto.ColumnType_STRING, Description: ColumnDescriptionTitle, Transform: transform.From(adGroupTitle)},
@@ -89,9 +90,34 @@ func tableAzureAdGroup(_ context.Context) *plugin.Table {
 	}
 }
 
+// groupFilterColumns are the columns whose quals are pushed down to the $filter of list requests.
+var groupFilterColumns = odataFilterColumns{
+	{Column: "display_name", Operators: odataStringOperators, In: true, Advanced: true},
+	{Column: "mail", Operators: odataStringOperators, In: true, Advanced: true},
+	{Column: "mail_enabled", Type: odataBool, Operators: odataBoolOperators, Advanced: true},
+	{Column: "on_premises_sync_enabled", Type: odataBool, Operators: odataBoolOperators, Advanced: true},
+	{Column: "security_enabled", Type: odataBool, Operators: odataBoolOperators, Advanced: true},
+}
+
+var groupSelectProperties = graphSelectProperties{
+	"assigned_labels":               {"assignedLabels"},
+	"resource_behavior_options":     {"resourceBehaviorOptions"},
+	"resource_provisioning_options": {"resourceProvisioningOptions"},
+	"tags":                          {"assignedLabels"},
+	"title":                         {"displayName", "id"},
+	// Only set by the delta sync
+	"last_change_time": nil,
+}
+
 //// LIST FUNCTION
 
 func listAdGroups(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdGroups")
+
+	if usesDeltaSync(d) {
+		return listAdGroupsFromDeltaSync(ctx, d)
+	}
+
 	// Create client
 	client, adapter, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -114,31 +140,26 @@ func listAdGroups(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateDat
 		}
 	}
 
-	equalQuals := d.EqualsQuals
-	quals := d.Quals
-
-	var queryFilter string
-	filter := buildGroupQueryFilter(equalQuals)
-	filter = append(filter, buildGroupBoolNEFilter(quals)...)
-
-	if equalQuals["filter"] != nil {
-		queryFilter = equalQuals["filter"].GetStringValue()
+	query := buildODataQuery(d, groupFilterColumns)
+	if query.Filter != "" {
+		input.Filter = &query.Filter
 	}
-
-	if queryFilter != "" {
-		input.Filter = &queryFilter
-	} else if len(filter) > 0 {
-		joinStr := strings.Join(filter, " and ")
-		input.Filter = &joinStr
+	if query.Search != "" {
+		input.Search = &query.Search
 	}
+	input.Count = query.count()
+
+	// Request only the properties of the queried columns
+	input.Select = buildSelect(d, groupSelectProperties)
 
 	options := &groups.GroupsRequestBuilderGetRequestConfiguration{
+		Headers:         query.headers(),
 		QueryParameters: input,
 	}
 
 	result, err := client.Groups().Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("listAdGroups", "list_group_error", errObj)
 		return nil, errObj
 	}
@@ -153,14 +174,44 @@ func listAdGroups(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateDat
 		resourceBehaviorOptions := formatResourceBehaviorOptions(ctx, pageItem)
 		resourceProvisioningOptions := formatResourceProvisioningOptions(ctx, pageItem)
 
-		d.StreamListItem(ctx, &ADGroupInfo{pageItem, resourceBehaviorOptions, resourceProvisioningOptions})
+		d.StreamListItem(ctx, &ADGroupInfo{pageItem, resourceBehaviorOptions, resourceProvisioningOptions, nil})
 
 		// Context can be cancelled due to manual cancellation or the limit has been hit
 		return d.RowsRemaining(ctx) != 0
 	})
 	if err != nil {
-		plugin.Logger(ctx).Error("listAdGroups", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdGroups", "paging_error", errObj)
+		return nil, errObj
+	}
+
+	return nil, nil
+}
+
+// listAdGroupsFromDeltaSync lists the groups from the delta sync store of the connection, see delta_sync_tables.
+func listAdGroupsFromDeltaSync(ctx context.Context, d *plugin.QueryData) (interface{}, error) {
+	objects, err := deltaSyncObjects(ctx, d, deltaSyncSelect(d, groupSelectProperties))
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdGroupsFromDeltaSync", "delta_sync_error", errObj)
+		return nil, errObj
+	}
+
+	for _, object := range objects {
+		group, err := parseDeltaSyncObject[models.Groupable](object, models.CreateGroupFromDiscriminatorValue)
+		if err != nil {
+			plugin.Logger(ctx).Error("listAdGroupsFromDeltaSync", "parse_error", err)
+			return nil, err
+		}
+		resourceBehaviorOptions := formatResourceBehaviorOptions(ctx, group)
+		resourceProvisioningOptions := formatResourceProvisioningOptions(ctx, group)
+
+		d.StreamListItem(ctx, &ADGroupInfo{group, resourceBehaviorOptions, resourceProvisioningOptions, &object.LastChangeTime})
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			break
+		}
 	}
 
 	return nil, nil
@@ -169,6 +220,7 @@ func listAdGroups(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateDat
 //// HYDRATE FUNCTIONS
 
 func getAdGroup(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdGroup")
 
 	groupId := d.EqualsQuals["id"].GetStringValue()
 	if groupId == "" {
@@ -182,7 +234,9 @@ func getAdGroup(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData)
 		return nil, err
 	}
 
-	input := &groups.GroupItemRequestBuilderGetQueryParameters{}
+	input := &groups.GroupItemRequestBuilderGetQueryParameters{
+		Select: buildSelect(d, groupSelectProperties),
+	}
 
 	options := &groups.GroupItemRequestBuilderGetRequestConfiguration{
 		QueryParameters: input,
@@ -190,19 +244,20 @@ func getAdGroup(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData)
 
 	group, err := client.Groups().ByGroupId(groupId).Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getAdGroup", "get_group_error", errObj)
 		return nil, errObj
 	}
 	resourceBehaviorOptions := formatResourceBehaviorOptions(ctx, group)
 	resourceProvisioningOptions := formatResourceProvisioningOptions(ctx, group)
 
-	return &ADGroupInfo{group, resourceBehaviorOptions, resourceProvisioningOptions}, nil
+	return &ADGroupInfo{group, resourceBehaviorOptions, resourceProvisioningOptions, nil}, nil
 }
 
 // Returned only on $select. Supported only on the Get group API (GET /groups/{ID}).
 
 func getAdGroupIsSubscribedByMail(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdGroupIsSubscribedByMail")
 
 	var groupId string
 	if h.Item != nil {
@@ -229,9 +284,16 @@ func getAdGroupIsSubscribedByMail(ctx context.Context, d *plugin.QueryData, h *p
 		QueryParameters: input,
 	}
 
-	group, err := client.Groups().ByGroupId(groupId).Get(ctx, options)
+	requestInfo, err := client.Groups().ByGroupId(groupId).ToGetRequestInformation(ctx, options)
+	if err != nil {
+		plugin.Logger(ctx).Error("getAdGroupIsSubscribedByMail", "request_information_error", err)
+		return nil, err
+	}
+
+	// Requested in a batch with the groups of the other rows
+	group, err := batchGet[models.Groupable](ctx, d, requestInfo, models.CreateGroupFromDiscriminatorValue)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getAdGroupIsSubscribedByMail", "get_group_error", errObj)
 		return nil, nil
 	}
@@ -240,56 +302,29 @@ func getAdGroupIsSubscribedByMail(ctx context.Context, d *plugin.QueryData, h *p
 }
 
 func getAdGroupMembers(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
-	// Create client
-	client, adapter, err := GetGraphClient(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("azuread_group.getAdGroupMembers", "connection_error", err)
-		return nil, err
-	}
+	ctx = withGraphTelemetry(ctx, d, "getAdGroupMembers")
 
 	group := h.Item.(*ADGroupInfo)
 	groupID := group.GetId()
 
-	headers := &abstractions.RequestHeaders{}
-	headers.Add("ConsistencyLevel", "eventual")
-
-	requestParameters := &groups.ItemMembersRequestBuilderGetQueryParameters{
-		Count: Bool(true),
-	}
-
-	config := &groups.ItemMembersRequestBuilderGetRequestConfiguration{
-		Headers:         headers,
-		QueryParameters: requestParameters,
-	}
-
 	memberIds := []*string{}
-	members, err := client.Groups().ByGroupId(*groupID).Members().Get(ctx, config)
-	if err != nil {
-		errObj := getErrorObject(err)
-		plugin.Logger(ctx).Error("getAdGroupMembers", "get_group_members_error", errObj)
-		return nil, errObj
-	}
-
-	pageIterator, err := msgraphcore.NewPageIterator[models.DirectoryObjectable](members, adapter, models.CreateDirectoryObjectCollectionResponseFromDiscriminatorValue)
-	if err != nil {
-		plugin.Logger(ctx).Error("getAdGroupMembers", "create_iterator_instance_error", err)
-		return nil, err
-	}
-
-	err = pageIterator.Iterate(ctx, func(pageItem models.DirectoryObjectable) bool {
+	err := batchIterateGroupMembers(ctx, d, *groupID, nil, func(pageItem models.DirectoryObjectable) bool {
 		memberIds = append(memberIds, pageItem.GetId())
 
 		return true
 	})
 	if err != nil {
-		plugin.Logger(ctx).Error("getAdGroupMembers", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("getAdGroupMembers", "list_group_members_error", errObj)
+		return nil, errObj
 	}
 
 	return memberIds, nil
 }
 
 func getAdGroupOwners(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdGroupOwners")
+
 	// Create client
 	client, adapter, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -313,9 +348,16 @@ func getAdGroupOwners(ctx context.Context, d *plugin.QueryData, h *plugin.Hydrat
 	}
 
 	ownerIds := []*string{}
-	owners, err := client.Groups().ByGroupId(*groupID).Owners().Get(ctx, config)
+	requestInfo, err := client.Groups().ByGroupId(*groupID).Owners().ToGetRequestInformation(ctx, config)
 	if err != nil {
-		errObj := getErrorObject(err)
+		plugin.Logger(ctx).Error("getAdGroupOwners", "request_information_error", err)
+		return nil, err
+	}
+
+	// The first page is requested in a batch with those of the other rows
+	owners, err := batchGet[models.DirectoryObjectCollectionResponseable](ctx, d, requestInfo, models.CreateDirectoryObjectCollectionResponseFromDiscriminatorValue)
+	if err != nil {
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getAdGroupOwners", "get_group_owners_error", errObj)
 		return nil, errObj
 	}
@@ -332,13 +374,56 @@ func getAdGroupOwners(ctx context.Context, d *plugin.QueryData, h *plugin.Hydrat
 		return true
 	})
 	if err != nil {
-		plugin.Logger(ctx).Error("getAdGroupMembers", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("getAdGroupMembers", "paging_error", errObj)
+		return nil, errObj
 	}
 
 	return ownerIds, nil
 }
 
+//// UTILITY FUNCTIONS
+
+// batchIterateGroupMembers calls fn for each direct member of a group until it
+// returns false, with only the selected properties if any. The first page is
+// requested in a batch with the concurrent requests of the other rows, or of
+// the other groups of a walk of nested groups.
+func batchIterateGroupMembers(ctx context.Context, d *plugin.QueryData, groupId string, selectProperties []string, fn func(models.DirectoryObjectable) bool) error {
+	client, adapter, err := GetGraphClient(ctx, d)
+	if err != nil {
+		return err
+	}
+
+	headers := &abstractions.RequestHeaders{}
+	headers.Add("ConsistencyLevel", "eventual")
+
+	requestParameters := &groups.ItemMembersRequestBuilderGetQueryParameters{
+		Count:  Bool(true),
+		Select: selectProperties,
+	}
+
+	config := &groups.ItemMembersRequestBuilderGetRequestConfiguration{
+		Headers:         headers,
+		QueryParameters: requestParameters,
+	}
+
+	requestInfo, err := client.Groups().ByGroupId(groupId).Members().ToGetRequestInformation(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	members, err := batchGet[models.DirectoryObjectCollectionResponseable](ctx, d, requestInfo, models.CreateDirectoryObjectCollectionResponseFromDiscriminatorValue)
+	if err != nil {
+		return err
+	}
+
+	pageIterator, err := msgraphcore.NewPageIterator[models.DirectoryObjectable](members, adapter, models.CreateDirectoryObjectCollectionResponseFromDiscriminatorValue)
+	if err != nil {
+		return err
+	}
+	return pageIterator.Iterate(ctx, fn)
+}
+
 //// TRANSFORM FUNCTIONS
 
 func adGroupTags(ctx context.Context, d *transform.TransformData) (interface{}, error) {
@@ -374,57 +459,6 @@ func adGroupTitle(_ context.Context, d *transform.TransformData) (interface{}, e
 	return title, nil
 }
 
-func buildGroupQueryFilter(equalQuals plugin.KeyColumnEqualsQualMap) []string {
-	filters := []string{}
-
-	filterQuals := map[string]string{
-		"display_name":             "string",
-		"mail":                     "string",
-		"mail_enabled":             "bool",
-		"on_premises_sync_enabled": "bool",
-		"security_enabled":         "bool",
-	}
-
-	for qual, qualType := range filterQuals {
-		switch qualType {
-		case "string":
-			if equalQuals[qual] != nil {
-				filters = append(filters, fmt.Sprintf("%s eq '%s'", strcase.ToCamel(qual), equalQuals[qual].GetStringValue()))
-			}
-		case "bool":
-			if equalQuals[qual] != nil {
-				filters = append(filters, fmt.Sprintf("%s eq %t", strcase.ToCamel(qual), equalQuals[qual].GetBoolValue()))
-			}
-		}
-	}
-
-	return filters
-}
-
-func buildGroupBoolNEFilter(quals plugin.KeyColumnQualMap) []string {
-	filters := []string{}
-
-	filterQuals := []string{
-		"mail_enabled",
-		"on_premises_sync_enabled",
-		"security_enabled",
-	}
-
-	for _, qual := range filterQuals {
-		if quals[qual] != nil {
-			for _, q := range quals[qual].Quals {
-				value := q.Value.GetBoolValue()
-				if q.Operator == "<>" {
-					filters = append(filters, fmt.Sprintf("%s eq %t", strcase.ToCamel(qual), !value))
-					break
-				}
-			}
-		}
-	}
-
-	return filters
-}
-
 func formatResourceBehaviorOptions(ctx context.Context, group models.Groupable) []string {
 	var resourceBehaviorOptions []string
 	data := group.GetAdditionalData()["resourceBehaviorOptions"]
@@ -2,10 +2,7 @@ package azuread
 
 import (
 	"context"
-	"fmt"
-	"strings"
 
-	"github.com/iancoleman/strcase"
 	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
 	"github.com/microsoftgraph/msgraph-sdk-go/groups"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
@@ -24,7 +21,7 @@ func tableAzureAdGroupAppRoleAssignment(_ context.Context) *plugin.Table {
 		Get: &plugin.GetConfig{
 			Hydrate: getAdGroupAppRoleAssignment,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_ResourceNotFound", "Invalid object identifier"}),
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
 			},
 			KeyColumns: plugin.KeyColumnSlice{
 				{Name: "group_id", Require: plugin.Required},
@@ -33,14 +30,9 @@ func tableAzureAdGroupAppRoleAssignment(_ context.Context) *plugin.Table {
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listAdGroupAppRoleAssignments,
-			KeyColumns: plugin.KeyColumnSlice{
-				// Key fields
+			KeyColumns: append(plugin.KeyColumnSlice{
 				{Name: "group_id", Require: plugin.Required},
-
-				// Other fields for filtering OData
-				{Name: "resource_id", Require: plugin.Optional},
-				{Name: "principal_display_name", Require: plugin.Optional},
-			},
+			}, groupAppRoleAssignmentFilterColumns.keyColumns()...),
 		},
 
 		Columns: []*plugin.Column{
@@ -63,9 +55,16 @@ func tableAzureAdGroupAppRoleAssignment(_ context.Context) *plugin.Table {
 	}
 }
 
+// groupAppRoleAssignmentFilterColumns are the columns whose quals are pushed down to the $filter of list requests.
+var groupAppRoleAssignmentFilterColumns = odataFilterColumns{
+	{Column: "resource_id", Type: odataGuid},
+	{Column: "principal_display_name"},
+}
+
 //// LIST FUNCTION
 
 func listAdGroupAppRoleAssignments(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdGroupAppRoleAssignments")
 
 	groupId := d.EqualsQuals["group_id"].GetStringValue()
 	if groupId == "" {
@@ -94,20 +93,20 @@ func listAdGroupAppRoleAssignments(ctx context.Context, d *plugin.QueryData, _ *
 		}
 	}
 
-	// Apply optional filters
-	filters := buildAdGroupAppRoleAssignmentQueryFilter(d.Quals)
-	if len(filters) > 0 {
-		filterString := strings.Join(filters, " and ")
-		input.Filter = &filterString
+	if filter := buildODataFilter(d, groupAppRoleAssignmentFilterColumns); filter != "" {
+		input.Filter = &filter
 	}
 
+	// Request only the properties of the queried columns
+	input.Select = buildSelect(d, nil)
+
 	options := &groups.ItemAppRoleAssignmentsRequestBuilderGetRequestConfiguration{
 		QueryParameters: input,
 	}
 
 	result, err := client.Groups().ByGroupId(groupId).AppRoleAssignments().Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("listAdGroupAppRoleAssignments", "list_group_app_role_assignment_error", errObj)
 		return nil, errObj
 	}
@@ -126,8 +125,9 @@ func listAdGroupAppRoleAssignments(ctx context.Context, d *plugin.QueryData, _ *
 	})
 
 	if err != nil {
-		plugin.Logger(ctx).Error("listAdGroupAppRoleAssignments", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdGroupAppRoleAssignments", "paging_error", errObj)
+		return nil, errObj
 	}
 
 	return nil, nil
@@ -136,6 +136,7 @@ func listAdGroupAppRoleAssignments(ctx context.Context, d *plugin.QueryData, _ *
 //// HYDRATE FUNCTIONS
 
 func getAdGroupAppRoleAssignment(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdGroupAppRoleAssignment")
 
 	groupId := d.EqualsQuals["group_id"].GetStringValue()
 	appRoleAssignmentId := d.EqualsQuals["id"].GetStringValue()
@@ -150,38 +151,18 @@ func getAdGroupAppRoleAssignment(ctx context.Context, d *plugin.QueryData, h *pl
 		return nil, err
 	}
 
-	appRoleAssignment, err := client.Groups().ByGroupId(groupId).AppRoleAssignments().ByAppRoleAssignmentId(appRoleAssignmentId).Get(ctx, nil)
+	options := &groups.ItemAppRoleAssignmentsAppRoleAssignmentItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &groups.ItemAppRoleAssignmentsAppRoleAssignmentItemRequestBuilderGetQueryParameters{
+			Select: buildSelect(d, nil),
+		},
+	}
+
+	appRoleAssignment, err := client.Groups().ByGroupId(groupId).AppRoleAssignments().ByAppRoleAssignmentId(appRoleAssignmentId).Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getAdGroupAppRoleAssignment", "get_group_app_role_assignment_error", errObj)
 		return nil, errObj
 	}
 
 	return &ADAppRoleAssignmentInfo{appRoleAssignment}, nil
 }
-
-//// TRANSFORM FUNCTIONS
-
-func buildAdGroupAppRoleAssignmentQueryFilter(quals plugin.KeyColumnQualMap) []string {
-	filters := []string{}
-
-	filterTypes := map[string]string{
-		"resource_id":            "guid",
-		"principal_display_name": "string",
-	}
-
-	for k, v := range quals {
-		if filterType, ok := filterTypes[k]; ok {
-			for _, q := range v.Quals {
-				switch filterType {
-				case "guid":
-					filters = append(filters, fmt.Sprintf("%s eq %s", strcase.ToCamel(k), q.Value.GetStringValue()))
-				case "string":
-					filters = append(filters, fmt.Sprintf("%s eq '%s'", strcase.ToCamel(k), q.Value.GetStringValue()))
-				}
-			}
-		}
-	}
-
-	return filters
-}
@@ -0,0 +1,135 @@
+package azuread
+
+import (
+	"context"
+
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
+	"github.com/microsoftgraph/msgraph-sdk-go/groups"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/quals"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAzureAdGroupMember(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "azuread_group_member",
+		Description: "Represents a member of an Azure AD group, directly or through nested groups.",
+		List: &plugin.ListConfig{
+			Hydrate: listAdGroupMembers,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "group_id", Require: plugin.Required},
+				{Name: "transitive", Require: plugin.Optional, Operators: odataBoolOperators},
+			},
+		},
+
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "group_id", Type: proto.ColumnType_STRING, Description: "The unique identifier of the group.", Transform: transform.FromField("GroupId")},
+			{Name: "member_id", Type: proto.ColumnType_STRING, Description: "The unique identifier of the member.", Transform: transform.FromMethod("GetId")},
+			{Name: "member_type", Type: proto.ColumnType_STRING, Description: "The type of the member, i.e. user, group, servicePrincipal, device or orgContact.", Transform: transform.FromMethod("MemberType")},
+			{Name: "display_name", Type: proto.ColumnType_STRING, Description: "The display name of the member.", Transform: transform.FromMethod("MemberDisplayName")},
+			{Name: "user_principal_name", Type: proto.ColumnType_STRING, Description: "The user principal name (UPN) of the member, if it is a user.", Transform: transform.FromMethod("MemberUserPrincipalName")},
+			{Name: "mail", Type: proto.ColumnType_STRING, Description: "The SMTP address of the member, if it is a user, group or contact.", Transform: transform.FromMethod("MemberMail")},
+			{Name: "transitive", Type: proto.ColumnType_BOOL, Description: "True if the members of the nested groups of the group are returned, as well as its direct members. Defaults to false, i.e. only the direct members are returned.", Transform: transform.FromField("Transitive")},
+		}),
+	}
+}
+
+// groupMemberSelectProperties maps the group member columns which are not read with a Get method to their Graph properties.
+var groupMemberSelectProperties = graphSelectProperties{
+	"group_id":            {},
+	"member_type":         {},
+	"display_name":        {"displayName"},
+	"user_principal_name": {"userPrincipalName"},
+	"mail":                {"mail"},
+	"transitive":          {},
+}
+
+//// LIST FUNCTION
+
+func listAdGroupMembers(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdGroupMembers")
+
+	groupId := d.EqualsQuals["group_id"].GetStringValue()
+	if groupId == "" {
+		return nil, nil
+	}
+
+	transitive := false
+	if d.Quals["transitive"] != nil {
+		for _, q := range d.Quals["transitive"].Quals {
+			value := q.Value.GetBoolValue()
+			if q.Operator == quals.QualOperatorNotEqual {
+				value = !value
+			}
+			transitive = value
+		}
+	}
+
+	// Restrict the limit value to be passed in the query parameter which is not between 1 and 999, otherwise API will throw an error as follow
+	// unexpected status 400 with OData error: Request_UnsupportedQuery: Invalid page size specified: '1000'. Must be between 1 and 999 inclusive.
+	top := int32(999)
+	limit := d.QueryContext.Limit
+	if limit != nil {
+		if *limit > 0 && *limit < 999 {
+			top = int32(*limit)
+		}
+	}
+
+	err := iterateGroupMembers(ctx, d, groupId, transitive, top, buildSelect(d, groupMemberSelectProperties), func(member models.DirectoryObjectable) bool {
+		d.StreamListItem(ctx, &ADGroupMemberInfo{member, groupId, transitive})
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdGroupMembers", "list_group_member_error", errObj)
+		return nil, errObj
+	}
+
+	return nil, nil
+}
+
+//// UTILITY FUNCTIONS
+
+// iterateGroupMembers calls fn for each direct member of a group, or each
+// member of the group and its nested groups if transitive, until it returns
+// false. The members are requested in pages of the given size, with only the
+// selected properties if any.
+func iterateGroupMembers(ctx context.Context, d *plugin.QueryData, groupId string, transitive bool, top int32, selectProperties []string, fn func(models.DirectoryObjectable) bool) error {
+	client, adapter, err := GetGraphClient(ctx, d)
+	if err != nil {
+		return err
+	}
+
+	var result models.DirectoryObjectCollectionResponseable
+	if transitive {
+		result, err = client.Groups().ByGroupId(groupId).TransitiveMembers().Get(ctx, &groups.ItemTransitiveMembersRequestBuilderGetRequestConfiguration{
+			QueryParameters: &groups.ItemTransitiveMembersRequestBuilderGetQueryParameters{
+				Top:    Int32(top),
+				Select: selectProperties,
+			},
+		})
+	} else {
+		result, err = client.Groups().ByGroupId(groupId).Members().Get(ctx, &groups.ItemMembersRequestBuilderGetRequestConfiguration{
+			QueryParameters: &groups.ItemMembersRequestBuilderGetQueryParameters{
+				Top:    Int32(top),
+				Select: selectProperties,
+			},
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	pageIterator, err := msgraphcore.NewPageIterator[models.DirectoryObjectable](result, adapter, models.CreateDirectoryObjectCollectionResponseFromDiscriminatorValue)
+	if err != nil {
+		return err
+	}
+	return pageIterator.Iterate(ctx, fn)
+}
@@ -0,0 +1,73 @@
+package azuread
+
+import (
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestGroupMembers(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("groups/g1/members",
+		map[string]interface{}{"@odata.type": "#microsoft.graph.user", "id": "u1", "displayName": "Alice", "userPrincipalName": "alice@example.com", "mail": "alice@example.com"},
+		map[string]interface{}{"@odata.type": "#microsoft.graph.group", "id": "g2", "displayName": "Engineering"},
+		map[string]interface{}{"@odata.type": "#microsoft.graph.servicePrincipal", "id": "sp1", "displayName": "Build agent"},
+	)
+	standIn.setCollection("groups/g1/transitiveMembers",
+		map[string]interface{}{"@odata.type": "#microsoft.graph.user", "id": "u1", "displayName": "Alice", "userPrincipalName": "alice@example.com"},
+		map[string]interface{}{"@odata.type": "#microsoft.graph.group", "id": "g2", "displayName": "Engineering"},
+		map[string]interface{}{"@odata.type": "#microsoft.graph.servicePrincipal", "id": "sp1", "displayName": "Build agent"},
+		map[string]interface{}{"@odata.type": "#microsoft.graph.user", "id": "u2", "displayName": "Bob", "userPrincipalName": "bob@example.com"},
+		map[string]interface{}{"@odata.type": "#microsoft.graph.device", "id": "d1", "displayName": "BUILD-01"},
+	)
+
+	columns := []string{"group_id", "member_id", "member_type", "display_name", "user_principal_name", "transitive"}
+
+	rows, err := executeQuery(t, "azuread_group_member", columns, equalsQuals(map[string]string{"group_id": "g1"}))
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	members := map[string]map[string]*proto.Column{}
+	for _, row := range rows {
+		members[row["member_id"].GetStringValue()] = row
+	}
+	if len(members) != 3 {
+		t.Fatalf("expected 3 direct members, got %d", len(members))
+	}
+	alice := members["u1"]
+	if alice["member_type"].GetStringValue() != "user" || alice["display_name"].GetStringValue() != "Alice" || alice["user_principal_name"].GetStringValue() != "alice@example.com" {
+		t.Errorf("unexpected member %v", alice)
+	}
+	if alice["group_id"].GetStringValue() != "g1" || alice["transitive"].GetBoolValue() {
+		t.Errorf("unexpected group columns %v", alice)
+	}
+	if got := members["sp1"]["member_type"].GetStringValue(); got != "servicePrincipal" {
+		t.Errorf("unexpected member type %q", got)
+	}
+	if _, ok := members["g2"]["user_principal_name"].GetValue().(*proto.Column_NullValue); !ok {
+		t.Errorf("expected no user principal name for a group, got %v", members["g2"]["user_principal_name"])
+	}
+	requests := standIn.requestsFor("groups/g1/members")
+	if len(requests) == 0 || requests[0].URL.Query().Get("$select") != "id,displayName,userPrincipalName" {
+		t.Errorf("expected the queried properties to be selected, got %v", requests)
+	}
+
+	// Members of nested groups are listed with transitive
+	quals := equalsQuals(map[string]string{"group_id": "g1"})
+	quals["transitive"] = &proto.Quals{Quals: []*proto.Qual{boolQual("transitive", "=", true)}}
+	rows, err = executeQuery(t, "azuread_group_member", columns, quals)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("expected 5 transitive members, got %d", len(rows))
+	}
+	for _, row := range rows {
+		if !row["transitive"].GetBoolValue() {
+			t.Errorf("expected a transitive member, got %v", row)
+		}
+	}
+	if len(standIn.requestsFor("groups/g1/transitiveMembers")) != 3 {
+		t.Errorf("expected the transitive members to be paged, got %d requests", len(standIn.requestsFor("groups/g1/transitiveMembers")))
+	}
+}
@@ -0,0 +1,326 @@
+package azuread
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
+	"github.com/microsoftgraph/msgraph-sdk-go/groups"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+//// TABLE DEFINITION
+
+func tableAzureAdGroupNesting(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "azuread_group_nesting",
+		Description: "Represents an edge of the graph of nested Azure AD groups, i.e. a group which is a member of another group, under a root group.",
+		List: &plugin.ListConfig{
+			Hydrate: listAdGroupNesting,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "root_group_id", Require: plugin.Optional},
+			},
+		},
+
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "root_group_id", Type: proto.ColumnType_STRING, Description: "The unique identifier of the root group, i.e. the group which is not nested in any other group, or the group given in the query."},
+			{Name: "root_group_display_name", Type: proto.ColumnType_STRING, Description: "The display name of the root group."},
+			{Name: "parent_group_id", Type: proto.ColumnType_STRING, Description: "The unique identifier of the group which the child group is a member of."},
+			{Name: "parent_group_display_name", Type: proto.ColumnType_STRING, Description: "The display name of the parent group."},
+			{Name: "child_group_id", Type: proto.ColumnType_STRING, Description: "The unique identifier of the group which is a member of the parent group."},
+			{Name: "child_group_display_name", Type: proto.ColumnType_STRING, Description: "The display name of the child group."},
+			{Name: "depth", Type: proto.ColumnType_INT, Description: "The nesting depth of the child group under the root group, i.e. 1 for a member of the root group, by the shortest path."},
+			{Name: "is_cycle", Type: proto.ColumnType_BOOL, Description: "True if the edge is part of a membership cycle, i.e. the parent group is also nested, directly or not, in the child group."},
+			{Name: "max_depth", Type: proto.ColumnType_INT, Description: "The maximum nesting depth of the groups under the root group."},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func listAdGroupNesting(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdGroupNesting")
+
+	client, adapter, err := GetGraphClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("azuread_group_nesting.listAdGroupNesting", "connection_error", err)
+		return nil, err
+	}
+
+	graph := newGroupNestingGraph()
+	var start []string
+
+	rootGroupId := d.EqualsQuals["root_group_id"].GetStringValue()
+	if rootGroupId != "" {
+		group, err := client.Groups().ByGroupId(rootGroupId).Get(ctx, &groups.GroupItemRequestBuilderGetRequestConfiguration{
+			QueryParameters: &groups.GroupItemRequestBuilderGetQueryParameters{
+				Select: []string{"id", "displayName"},
+			},
+		})
+		if err != nil {
+			errObj := getErrorObject(d, err)
+			plugin.Logger(ctx).Error("listAdGroupNesting", "get_group_error", errObj)
+			return nil, errObj
+		}
+		graph.names[rootGroupId] = group.GetDisplayName()
+		start = []string{rootGroupId}
+	} else {
+		// All the groups are walked to find the root groups
+		result, err := client.Groups().Get(ctx, &groups.GroupsRequestBuilderGetRequestConfiguration{
+			QueryParameters: &groups.GroupsRequestBuilderGetQueryParameters{
+				Top:    Int32(999),
+				Select: []string{"id", "displayName"},
+			},
+		})
+		if err != nil {
+			errObj := getErrorObject(d, err)
+			plugin.Logger(ctx).Error("listAdGroupNesting", "list_group_error", errObj)
+			return nil, errObj
+		}
+
+		pageIterator, err := msgraphcore.NewPageIterator[models.Groupable](result, adapter, models.CreateGroupCollectionResponseFromDiscriminatorValue)
+		if err != nil {
+			plugin.Logger(ctx).Error("listAdGroupNesting", "create_iterator_instance_error", err)
+			return nil, err
+		}
+
+		err = pageIterator.Iterate(ctx, func(group models.Groupable) bool {
+			if group.GetId() != nil {
+				graph.names[*group.GetId()] = group.GetDisplayName()
+				start = append(start, *group.GetId())
+			}
+			return true
+		})
+		if err != nil {
+			errObj := getErrorObject(d, err)
+			plugin.Logger(ctx).Error("listAdGroupNesting", "paging_error", errObj)
+			return nil, errObj
+		}
+	}
+
+	if err := graph.walk(ctx, d, start); err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdGroupNesting", "list_group_members_error", errObj)
+		return nil, errObj
+	}
+
+	roots := start
+	if rootGroupId == "" {
+		roots = graph.roots()
+	}
+	for _, root := range roots {
+		for _, edge := range graph.edges(root) {
+			d.StreamListItem(ctx, edge)
+
+			// Context can be cancelled due to manual cancellation or the limit has been hit
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+//// UTILITY FUNCTIONS
+
+// groupNestingGraph is the graph of the groups which are members of other groups.
+type groupNestingGraph struct {
+	names map[string]*string
+	// children holds the groups which are direct members of each walked group
+	children map[string][]string
+	// component holds the strongly connected component of each group, i.e.
+	// the groups in a membership cycle share the same component
+	component map[string]int
+}
+
+func newGroupNestingGraph() *groupNestingGraph {
+	return &groupNestingGraph{
+		names:    map[string]*string{},
+		children: map[string][]string{},
+	}
+}
+
+// walk requests the members of the groups, then of their nested groups, level
+// by level, until every nested group has been walked. The members of the
+// groups of a level are requested concurrently, so they share Graph batches.
+func (g *groupNestingGraph) walk(ctx context.Context, d *plugin.QueryData, start []string) error {
+	walked := map[string]bool{}
+	level := start
+
+	for len(level) > 0 {
+		children := make([][]string, len(level))
+		errs := make([]error, len(level))
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		limit := make(chan struct{}, maxBatchedHydrateConcurrency)
+		for i, groupId := range level {
+			walked[groupId] = true
+
+			wg.Add(1)
+			limit <- struct{}{}
+			go func(i int, groupId string) {
+				defer func() {
+					<-limit
+					wg.Done()
+				}()
+				errs[i] = batchIterateGroupMembers(ctx, d, groupId, []string{"id", "displayName"}, func(member models.DirectoryObjectable) bool {
+					if typeName := directoryObjectType(member); member.GetId() == nil || typeName == nil || *typeName != "group" {
+						return true
+					}
+					children[i] = append(children[i], *member.GetId())
+					mu.Lock()
+					g.names[*member.GetId()] = directoryObjectDisplayName(member)
+					mu.Unlock()
+					return true
+				})
+			}(i, groupId)
+		}
+		wg.Wait()
+
+		var next []string
+		queued := map[string]bool{}
+		for i, groupId := range level {
+			if errs[i] != nil {
+				return errs[i]
+			}
+			g.children[groupId] = children[i]
+			for _, child := range children[i] {
+				if !walked[child] && !queued[child] {
+					queued[child] = true
+					next = append(next, child)
+				}
+			}
+		}
+		level = next
+	}
+
+	g.findCycles()
+	return nil
+}
+
+// findCycles sets the strongly connected component of each group with
+// Tarjan's algorithm.
+func (g *groupNestingGraph) findCycles() {
+	g.component = map[string]int{}
+	index := map[string]int{}
+	lowLink := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+
+	var connect func(groupId string)
+	connect = func(groupId string) {
+		index[groupId] = len(index)
+		lowLink[groupId] = index[groupId]
+		stack = append(stack, groupId)
+		onStack[groupId] = true
+
+		for _, child := range g.children[groupId] {
+			if _, ok := index[child]; !ok {
+				connect(child)
+				lowLink[groupId] = min(lowLink[groupId], lowLink[child])
+			} else if onStack[child] {
+				lowLink[groupId] = min(lowLink[groupId], index[child])
+			}
+		}
+
+		if lowLink[groupId] == index[groupId] {
+			component := len(g.component)
+			for {
+				member := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[member] = false
+				g.component[member] = component
+				if member == groupId {
+					break
+				}
+			}
+		}
+	}
+
+	for _, groupId := range g.sortedGroups() {
+		if _, ok := index[groupId]; !ok {
+			connect(groupId)
+		}
+	}
+}
+
+// roots returns the groups with nested groups which are not nested in any
+// other group. A cycle of groups which is not nested in any other group is
+// rooted at the first of its groups by id.
+func (g *groupNestingGraph) roots() []string {
+	nested := map[int]bool{}
+	for groupId, children := range g.children {
+		for _, child := range children {
+			if g.component[child] != g.component[groupId] {
+				nested[g.component[child]] = true
+			}
+		}
+	}
+
+	var roots []string
+	rooted := map[int]bool{}
+	for _, groupId := range g.sortedGroups() {
+		component := g.component[groupId]
+		if len(g.children[groupId]) == 0 || nested[component] || rooted[component] {
+			continue
+		}
+		rooted[component] = true
+		roots = append(roots, groupId)
+	}
+	return roots
+}
+
+// edges returns the edges of the groups nested under the root, breadth first,
+// so the depth of each edge is that of the shortest path from the root to its
+// child, also for an edge back to a group which was already reached.
+func (g *groupNestingGraph) edges(root string) []*ADGroupNestingEdge {
+	var edges []*ADGroupNestingEdge
+	depths := map[string]int{root: 0}
+	queue := []string{root}
+
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+
+		for _, child := range g.children[parent] {
+			if _, ok := depths[child]; !ok {
+				depths[child] = depths[parent] + 1
+				queue = append(queue, child)
+			}
+			edges = append(edges, &ADGroupNestingEdge{
+				RootGroupId:            root,
+				RootGroupDisplayName:   g.names[root],
+				ParentGroupId:          parent,
+				ParentGroupDisplayName: g.names[parent],
+				ChildGroupId:           child,
+				ChildGroupDisplayName:  g.names[child],
+				Depth:                  depths[child],
+				IsCycle:                g.component[parent] == g.component[child],
+			})
+		}
+	}
+
+	maxDepth := 0
+	for _, depth := range depths {
+		maxDepth = max(maxDepth, depth)
+	}
+	for _, edge := range edges {
+		edge.MaxDepth = maxDepth
+	}
+	return edges
+}
+
+func (g *groupNestingGraph) sortedGroups() []string {
+	groupIds := make([]string, 0, len(g.children))
+	for groupId := range g.children {
+		groupIds = append(groupIds, groupId)
+	}
+	sort.Strings(groupIds)
+	return groupIds
+}
@@ -0,0 +1,97 @@
+package azuread
+
+import (
+	"testing"
+)
+
+func TestGroupNesting(t *testing.T) {
+	standIn.reset()
+	// g1 has g2 and g3, which both have g4, which has g5. g6 and g7 are members
+	// of each other, and g8 has no nested groups.
+	standIn.setCollection("groups",
+		nestedGroup("g1", "All staff"), nestedGroup("g2", "Engineering"), nestedGroup("g3", "Sales"), nestedGroup("g4", "Contractors"),
+		nestedGroup("g5", "Interns"), nestedGroup("g6", "Loop A"), nestedGroup("g7", "Loop B"), nestedGroup("g8", "Empty"),
+	)
+	standIn.setCollection("groups/g1/members",
+		map[string]interface{}{"@odata.type": "#microsoft.graph.user", "id": "u1", "displayName": "Alice"},
+		nestedGroup("g2", "Engineering"),
+		nestedGroup("g3", "Sales"),
+	)
+	standIn.setCollection("groups/g2/members", nestedGroup("g4", "Contractors"))
+	standIn.setCollection("groups/g3/members", nestedGroup("g4", "Contractors"))
+	standIn.setCollection("groups/g4/members", nestedGroup("g5", "Interns"))
+	standIn.setCollection("groups/g5/members")
+	standIn.setCollection("groups/g6/members", nestedGroup("g7", "Loop B"))
+	standIn.setCollection("groups/g7/members", nestedGroup("g6", "Loop A"))
+	standIn.setCollection("groups/g8/members")
+
+	columns := []string{"root_group_id", "root_group_display_name", "parent_group_id", "child_group_id", "child_group_display_name", "depth", "is_cycle", "max_depth"}
+	rows, err := executeQuery(t, "azuread_group_nesting", columns, nil)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+
+	type edge struct {
+		root, parent, child string
+		depth               int64
+		cycle               bool
+		maxDepth            int64
+	}
+	got := map[edge]bool{}
+	for _, row := range rows {
+		got[edge{
+			row["root_group_id"].GetStringValue(),
+			row["parent_group_id"].GetStringValue(),
+			row["child_group_id"].GetStringValue(),
+			row["depth"].GetIntValue(),
+			row["is_cycle"].GetBoolValue(),
+			row["max_depth"].GetIntValue(),
+		}] = true
+		if row["root_group_id"].GetStringValue() == "g1" && row["root_group_display_name"].GetStringValue() != "All staff" {
+			t.Errorf("unexpected root display name %v", row["root_group_display_name"])
+		}
+	}
+	want := []edge{
+		{"g1", "g1", "g2", 1, false, 3},
+		{"g1", "g1", "g3", 1, false, 3},
+		{"g1", "g2", "g4", 2, false, 3},
+		{"g1", "g3", "g4", 2, false, 3},
+		{"g1", "g4", "g5", 3, false, 3},
+		{"g6", "g6", "g7", 1, true, 1},
+		// The edge back to the root has the depth of the root
+		{"g6", "g7", "g6", 0, true, 1},
+	}
+	if len(rows) != len(want) {
+		t.Errorf("expected %d edges, got %d: %v", len(want), len(rows), got)
+	}
+	for _, e := range want {
+		if !got[e] {
+			t.Errorf("missing edge %+v in %v", e, got)
+		}
+	}
+	// Each group is walked once, even if it is nested in several groups
+	if len(standIn.requestsFor("groups/g4/members")) != 1 {
+		t.Errorf("expected g4 to be walked once, got %d requests", len(standIn.requestsFor("groups/g4/members")))
+	}
+	if len(standIn.requestsFor("$batch")) == 0 {
+		t.Errorf("expected the members to be requested in batches")
+	}
+
+	// Only the groups nested under the given root group
+	walked := len(standIn.requestsFor("groups/g1/members"))
+	rows, err = executeQuery(t, "azuread_group_nesting", columns, equalsQuals(map[string]string{"root_group_id": "g2"}))
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 edges, got %d", len(rows))
+	}
+	for _, row := range rows {
+		if row["root_group_id"].GetStringValue() != "g2" || row["root_group_display_name"].GetStringValue() != "Engineering" || row["max_depth"].GetIntValue() != 2 {
+			t.Errorf("unexpected edge %v", row)
+		}
+	}
+	if len(standIn.requestsFor("groups/g1/members")) != walked {
+		t.Errorf("expected g1 not to be walked for root g2")
+	}
+}
@@ -2,10 +2,7 @@ package azuread
 
 import (
 	"context"
-	"fmt"
-	"strings"
 
-	"github.com/iancoleman/strcase"
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 
@@ -25,14 +22,11 @@ func tableAzureAdIdentityProvider(_ context.Context) *plugin.Table {
 		List: &plugin.ListConfig{
 			Hydrate: listAdIdentityProviders,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_UnsupportedQuery", "Invalid filter clause"}),
-			},
-			KeyColumns: plugin.KeyColumnSlice{
-				// Key fields
-				{Name: "id", Require: plugin.Optional},
-				{Name: "name", Require: plugin.Optional},
-				{Name: "filter", Require: plugin.Optional},
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryInvalidFilter),
 			},
+			KeyColumns: append(identityProviderFilterColumns.keyColumns(),
+				&plugin.KeyColumn{Name: "filter", Require: plugin.Optional},
+			),
 		},
 
 		Columns: commonColumns([]*plugin.Column{
@@ -51,9 +45,17 @@ func tableAzureAdIdentityProvider(_ context.Context) *plugin.Table {
 	}
 }
 
+// identityProviderFilterColumns are the columns whose quals are pushed down to the $filter of list requests.
+var identityProviderFilterColumns = odataFilterColumns{
+	{Column: "id"},
+	{Column: "name", Property: "displayName"},
+}
+
 //// LIST FUNCTION
 
 func listAdIdentityProviders(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdIdentityProviders")
+
 	// Create client
 	client, adapter, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -72,19 +74,8 @@ func listAdIdentityProviders(ctx context.Context, d *plugin.QueryData, _ *plugin
 	// 	input.Top = &l
 	// }
 
-	var queryFilter string
-	equalQuals := d.EqualsQuals
-	filter := buildIdentityProviderQueryFilter(equalQuals)
-
-	if equalQuals["filter"] != nil {
-		queryFilter = equalQuals["filter"].GetStringValue()
-	}
-
-	if queryFilter != "" {
-		input.Filter = &queryFilter
-	} else if len(filter) > 0 {
-		joinStr := strings.Join(filter, " and ")
-		input.Filter = &joinStr
+	if filter := buildODataFilter(d, identityProviderFilterColumns); filter != "" {
+		input.Filter = &filter
 	}
 
 	options := &identity.IdentityProvidersRequestBuilderGetRequestConfiguration{
@@ -93,18 +84,18 @@ func listAdIdentityProviders(ctx context.Context, d *plugin.QueryData, _ *plugin
 
 	result, err := client.Identity().IdentityProviders().Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("listAdIdentityProviders", "list_identity_provider_error", errObj)
 		return nil, errObj
 	}
 
-	pageIterator, err := msgraphcore.NewPageIterator[models.BuiltInIdentityProvider](result, adapter, models.CreateBuiltInIdentityProviderFromDiscriminatorValue)
+	pageIterator, err := msgraphcore.NewPageIterator[models.BuiltInIdentityProviderable](result, adapter, models.CreateBuiltInIdentityProviderFromDiscriminatorValue)
 	if err != nil {
 		plugin.Logger(ctx).Error("listAdIdentityProviders", "create_iterator_instance_error", err)
 		return nil, err
 	}
 
-	err = pageIterator.Iterate(ctx, func(pageItem models.BuiltInIdentityProvider) bool {
+	err = pageIterator.Iterate(ctx, func(pageItem models.BuiltInIdentityProviderable) bool {
 		clientID := pageItem.GetAdditionalData()["clientId"]
 		clientSecret := pageItem.GetAdditionalData()["clientSecret"]
 
@@ -115,8 +106,9 @@ func listAdIdentityProviders(ctx context.Context, d *plugin.QueryData, _ *plugin
 	})
 
 	if err != nil {
-		plugin.Logger(ctx).Error("listAdIdentityProviders", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdIdentityProviders", "paging_error", errObj)
+		return nil, errObj
 	}
 
 	return nil, nil
@@ -124,27 +116,6 @@ func listAdIdentityProviders(ctx context.Context, d *plugin.QueryData, _ *plugin
 
 //// TRANSFORM FUNCTIONS
 
-func buildIdentityProviderQueryFilter(equalQuals plugin.KeyColumnEqualsQualMap) []string {
-	filters := []string{}
-
-	filterQuals := map[string]string{
-		"id":   "string",
-		"name": "string",
-	}
-
-	for qual := range filterQuals {
-		if equalQuals[qual] != nil {
-			if qual == "name" {
-				filters = append(filters, fmt.Sprintf("displayName eq '%s'", equalQuals[qual].GetStringValue()))
-			} else {
-				filters = append(filters, fmt.Sprintf("%s eq '%s'", strcase.ToCamel(qual), equalQuals[qual].GetStringValue()))
-			}
-		}
-	}
-
-	return filters
-}
-
 func adIdentityProviderTitle(_ context.Context, d *transform.TransformData) (interface{}, error) {
 	data := d.HydrateItem.(models.IdentityProviderBaseable)
 	if data == nil {
@@ -0,0 +1,214 @@
+package azuread
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAzureAdPluginPermissionCheck(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "azuread_plugin_permission_check",
+		Description: "Reports whether the access token of the connection has the permissions, and the tenant the licenses, required by each table of the plugin.",
+		List: &plugin.ListConfig{
+			Hydrate: listAdPluginPermissionChecks,
+		},
+
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "table_name", Type: proto.ColumnType_STRING, Description: "The name of the plugin table."},
+			{Name: "permissions_granted", Type: proto.ColumnType_BOOL, Description: "True if the access token has every Microsoft Graph permission required by the table."},
+			{Name: "required_permissions", Type: proto.ColumnType_JSON, Description: "The Microsoft Graph permissions required by the table."},
+			{Name: "missing_permissions", Type: proto.ColumnType_JSON, Description: "The required Microsoft Graph permissions which the access token does not have."},
+			{Name: "required_license", Type: proto.ColumnType_STRING, Description: "The license the tenant needs for the table, if any."},
+			{Name: "license_present", Type: proto.ColumnType_BOOL, Description: "True if the tenant has the license required by the table. Null if the table needs no license, or if the subscribed SKUs of the tenant could not be read."},
+			{Name: "token_type", Type: proto.ColumnType_STRING, Description: "The type of the access token: application, for app-only tokens with roles, or delegated, for tokens with scopes issued on behalf of a user."},
+			{Name: "token_roles", Type: proto.ColumnType_JSON, Description: "The application permissions (roles claim) of the access token."},
+			{Name: "token_scopes", Type: proto.ColumnType_JSON, Description: "The delegated permissions (scp claim) of the access token."},
+			{Name: "token_app_id", Type: proto.ColumnType_STRING, Description: "The application ID of the client the access token was issued to."},
+			{Name: "token_expires_on", Type: proto.ColumnType_TIMESTAMP, Description: "The time the access token expires."},
+
+			// Standard columns
+			{Name: "title", Type: proto.ColumnType_STRING, Description: ColumnDescriptionTitle, Transform: transform.FromField("TableName")},
+		}),
+	}
+}
+
+type ADPluginPermissionCheck struct {
+	TableName           string
+	PermissionsGranted  bool
+	RequiredPermissions []string
+	MissingPermissions  []string
+	RequiredLicense     string
+	LicensePresent      *bool
+	TokenType           string
+	TokenRoles          []string
+	TokenScopes         []string
+	TokenAppId          string
+	TokenExpiresOn      *time.Time
+}
+
+// accessTokenClaims are the claims of a Microsoft identity platform access token used by the check.
+type accessTokenClaims struct {
+	AppId string   `json:"appid"`
+	Azp   string   `json:"azp"`
+	Roles []string `json:"roles"`
+	Scp   string   `json:"scp"`
+	Exp   int64    `json:"exp"`
+}
+
+//// LIST FUNCTION
+
+func listAdPluginPermissionChecks(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdPluginPermissionChecks")
+
+	token, err := getGraphAccessToken(ctx, d)
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("azuread_plugin_permission_check.listAdPluginPermissionChecks", "get_access_token_error", errObj)
+		return nil, errObj
+	}
+
+	claims, err := parseAccessToken(token)
+	if err != nil {
+		plugin.Logger(ctx).Error("azuread_plugin_permission_check.listAdPluginPermissionChecks", "parse_access_token_error", err)
+		return nil, err
+	}
+
+	// A missing license does not stop the permission check, so an error here is only logged
+	servicePlans, err := getTenantServicePlans(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Warn("azuread_plugin_permission_check.listAdPluginPermissionChecks", "list_subscribed_skus_error", err)
+	}
+
+	granted := map[string]bool{}
+	scopes := strings.Fields(claims.Scp)
+	for _, permission := range append(scopes, claims.Roles...) {
+		granted[permission] = true
+	}
+
+	tokenType := "application"
+	if len(scopes) > 0 {
+		tokenType = "delegated"
+	}
+	appId := claims.AppId
+	if appId == "" {
+		appId = claims.Azp
+	}
+	var expiresOn *time.Time
+	if claims.Exp > 0 {
+		t := time.Unix(claims.Exp, 0).UTC()
+		expiresOn = &t
+	}
+
+	tables := make([]string, 0, len(d.Table.Plugin.TableMap))
+	for table := range d.Table.Plugin.TableMap {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	for _, table := range tables {
+		requirement := tableRequirements[table]
+
+		check := &ADPluginPermissionCheck{
+			TableName:           table,
+			RequiredPermissions: requirement.Permissions,
+			MissingPermissions:  []string{},
+			RequiredLicense:     requirement.License,
+			TokenType:           tokenType,
+			TokenRoles:          claims.Roles,
+			TokenScopes:         scopes,
+			TokenAppId:          appId,
+			TokenExpiresOn:      expiresOn,
+		}
+		for _, permission := range requirement.Permissions {
+			if !isPermissionGranted(permission, granted) {
+				check.MissingPermissions = append(check.MissingPermissions, permission)
+			}
+		}
+		check.PermissionsGranted = len(check.MissingPermissions) == 0
+
+		if requirement.License != "" && servicePlans != nil {
+			present := false
+			for _, plan := range licenseServicePlans[requirement.License] {
+				present = present || servicePlans[plan]
+			}
+			check.LicensePresent = &present
+		}
+
+		d.StreamListItem(ctx, check)
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+//// UTILITY FUNCTIONS
+
+// parseAccessToken decodes the claims of a JWT access token. The signature is
+// not verified, the token is only inspected.
+func parseAccessToken(token string) (*accessTokenClaims, error) {
+	payload, err := accessTokenPayload(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims accessTokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("error decoding the access token claims: %v", err)
+	}
+	return &claims, nil
+}
+
+// accessTokenPayload returns the decoded JSON payload of a JWT access token.
+func accessTokenPayload(token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("the access token is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding the access token: %v", err)
+	}
+	return payload, nil
+}
+
+// getTenantServicePlans returns the service plans enabled by the subscribed SKUs of the tenant.
+func getTenantServicePlans(ctx context.Context, d *plugin.QueryData) (map[string]bool, error) {
+	client, _, err := GetGraphClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.SubscribedSkus().Get(ctx, nil)
+	if err != nil {
+		return nil, getErrorObject(d, err)
+	}
+
+	servicePlans := map[string]bool{}
+	for _, sku := range result.GetValue() {
+		if sku.GetCapabilityStatus() == nil || *sku.GetCapabilityStatus() != "Enabled" {
+			continue
+		}
+		for _, plan := range sku.GetServicePlans() {
+			if plan.GetServicePlanName() != nil && plan.GetProvisioningStatus() != nil && *plan.GetProvisioningStatus() == "Success" {
+				servicePlans[*plan.GetServicePlanName()] = true
+			}
+		}
+	}
+	return servicePlans, nil
+}
@@ -0,0 +1,88 @@
+package azuread
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseAccessToken(t *testing.T) {
+	expiresOn := time.Unix(1700000000, 0)
+	claims, err := parseAccessToken(testAccessToken([]string{"User.Read.All"}, expiresOn))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if !reflect.DeepEqual(claims.Roles, []string{"User.Read.All"}) || claims.Exp != expiresOn.Unix() {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+
+	if _, err := parseAccessToken("not-a-jwt"); err == nil {
+		t.Error("expected an error for a token which is not a JWT")
+	}
+}
+
+func TestIsPermissionGranted(t *testing.T) {
+	cases := []struct {
+		permission string
+		granted    []string
+		want       bool
+	}{
+		{"User.Read.All", []string{"User.Read.All"}, true},
+		{"User.Read.All", []string{"User.ReadWrite.All"}, true},
+		{"User.Read.All", []string{"Directory.Read.All"}, true},
+		{"Group.Read.All", []string{"Directory.AccessAsUser.All"}, true},
+		{"AuditLog.Read.All", []string{"Directory.Read.All"}, false},
+		{"Policy.Read.All", []string{"User.Read.All"}, false},
+		{"GroupMember.Read.All", []string{"Group.ReadWrite.All"}, true},
+		{"Group.Read.All", []string{"GroupMember.Read.All"}, false},
+	}
+
+	for _, tc := range cases {
+		granted := map[string]bool{}
+		for _, p := range tc.granted {
+			granted[p] = true
+		}
+		if got := isPermissionGranted(tc.permission, granted); got != tc.want {
+			t.Errorf("%s with %v: expected %t, got %t", tc.permission, tc.granted, tc.want, got)
+		}
+	}
+}
+
+func TestPluginPermissionCheck(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("subscribedSkus", map[string]interface{}{
+		"id":               "sku1",
+		"capabilityStatus": "Enabled",
+		"skuPartNumber":    "AAD_PREMIUM",
+		"servicePlans": []interface{}{
+			map[string]interface{}{"servicePlanName": "AAD_PREMIUM", "provisioningStatus": "Success"},
+		},
+	})
+
+	rows, err := executeQuery(t, "azuread_plugin_permission_check", []string{"table_name", "permissions_granted", "missing_permissions", "license_present", "token_type"}, nil)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+
+	checks := map[string]map[string]string{}
+	for _, row := range rows {
+		checks[row["table_name"].GetStringValue()] = map[string]string{
+			"missing_permissions": string(row["missing_permissions"].GetJsonValue()),
+			"license_present":     row["license_present"].String(),
+			"token_type":          row["token_type"].GetStringValue(),
+		}
+	}
+
+	if got := checks["azuread_user"]["missing_permissions"]; got != "[]" {
+		t.Errorf("expected no missing permissions for azuread_user, got %s", got)
+	}
+	if got := checks["azuread_sign_in_report"]["missing_permissions"]; got != `["AuditLog.Read.All","Directory.Read.All"]` {
+		t.Errorf("unexpected missing permissions for azuread_sign_in_report: %s", got)
+	}
+	if got := checks["azuread_conditional_access_policy"]["license_present"]; got != "bool_value:true" {
+		t.Errorf("expected the license to be present for azuread_conditional_access_policy, got %s", got)
+	}
+	if got := checks["azuread_user"]["token_type"]; got != "application" {
+		t.Errorf("expected an application token, got %q", got)
+	}
+}
@@ -33,6 +33,8 @@ func tableAzureAdSecurityDefaultsPolicy(_ context.Context) *plugin.Table {
 //// LIST FUNCTION
 
 func listAdSecurityDefaultPolicies(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdSecurityDefaultPolicies")
+
 	// Create client
 	client, _, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -42,7 +44,7 @@ func listAdSecurityDefaultPolicies(ctx context.Context, d *plugin.QueryData, _ *
 
 	result, err := client.Policies().IdentitySecurityDefaultsEnforcementPolicy().Get(ctx, nil)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("listAdSecurityDefaultPolicies", "list_security_defaults_policy_error", errObj)
 		return nil, errObj
 	}
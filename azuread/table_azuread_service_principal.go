@@ -2,10 +2,7 @@ package azuread
 
 import (
 	"context"
-	"fmt"
-	"strings"
 
-	"github.com/iancoleman/strcase"
 	abstractions "github.com/microsoft/kiota-abstractions-go"
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
@@ -25,25 +22,26 @@ func tableAzureAdServicePrincipal(_ context.Context) *plugin.Table {
 		Get: &plugin.GetConfig{
 			Hydrate: getAdServicePrincipal,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_ResourceNotFound", "Invalid object identifier"}),
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
 			},
 			KeyColumns: plugin.SingleColumn("id"),
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listAdServicePrincipals,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_UnsupportedQuery"}),
-			},
-			KeyColumns: plugin.KeyColumnSlice{
-				// Key fields
-				{Name: "display_name", Require: plugin.Optional},
-				{Name: "account_enabled", Require: plugin.Optional, Operators: []string{"<>", "="}},
-				{Name: "service_principal_type", Require: plugin.Optional},
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryInvalidFilter),
 			},
+			KeyColumns: append(servicePrincipalFilterColumns.keyColumns(),
+				&plugin.KeyColumn{Name: "search", Require: plugin.Optional},
+			),
+		},
+		HydrateConfig: []plugin.HydrateConfig{
+			{Func: getServicePrincipalOwners, MaxConcurrency: maxPerRowHydrateConcurrency},
 		},
 
 		Columns: commonColumns([]*plugin.Column{
 			{Name: "id", Type: proto.ColumnType_STRING, Description: "The unique identifier for the service principal.", Transform: transform.FromMethod("GetId")},
+			{Name: "search", Type: proto.ColumnType_STRING, Transform: transform.FromQual("search"), Description: "Term to search for in the display name of the service principal, or an OData $search expression such as \"displayName:Ada\"."},
 			{Name: "display_name", Type: proto.ColumnType_STRING, Description: "The display name for the service principal.", Transform: transform.FromMethod("GetDisplayName")},
 			{Name: "app_id", Type: proto.ColumnType_STRING, Description: "The unique identifier for the associated application (its appId property).", Transform: transform.FromMethod("GetAppId")},
 
@@ -58,6 +56,7 @@ func tableAzureAdServicePrincipal(_ context.Context) *plugin.Table {
 			{Name: "description", Type: proto.ColumnType_STRING, Description: "Free text field to provide an internal end-user facing description of the service principal.", Transform: transform.FromMethod("GetDescription")},
 			{Name: "login_url", Type: proto.ColumnType_STRING, Description: "Specifies the URL where the service provider redirects the user to Azure AD to authenticate. Azure AD uses the URL to launch the application from Microsoft 365 or the Azure AD My Apps. When blank, Azure AD performs IdP-initiated sign-on for applications configured with SAML-based single sign-on.", Transform: transform.FromMethod("GetLoginUrl")},
 			{Name: "logout_url", Type: proto.ColumnType_STRING, Description: "Specifies the URL that will be used by Microsoft's authorization service to logout an user using OpenId Connect front-channel, back-channel or SAML logout protocols.", Transform: transform.FromMethod("GetLogoutUrl")},
+			{Name: "last_change_time", Type: proto.ColumnType_TIMESTAMP, Description: "The time the delta sync last saw the service principal change, i.e. of the sync which first returned it or last returned changes to it. Null unless the table is listed in delta_sync_tables in the connection config.", Transform: transform.FromField("LastChangeTime")},
 
 			// JSON fields
 			{Name: "add_ins", Type: proto.ColumnType_JSON, Description: "Defines custom behavior that a consuming service can use to call an app in specific contexts.", Transform: transform.FromMethod("ServicePrincipalAddIns")},
@@ -73,6 +72,9 @@ func tableAzureAdServicePrincipal(_ context.Context) *plugin.Table {
 			{Name: "service_principal_names", Type: proto.ColumnType_JSON, Description: "Contains the list of identifiersUris, copied over from the associated application. Additional values can be added to hybrid applications. These values can be used to identify the permissions exposed by this app within Azure AD.", Transform: transform.FromMethod("GetServicePrincipalNames")},
 			{Name: "tags_src", Type: proto.ColumnType_JSON, Description: "Custom strings that can be used to categorize and identify the service principal.", Transform: transform.FromMethod("GetTags")},
 
+			// Beta fields
+			{Name: "beta_properties", Type: proto.ColumnType_JSON, Description: betaColumnDescription("Properties of the service principal only returned by the beta endpoint."), Hydrate: getGraphBetaProperties, Transform: transform.FromValue()},
+
 			// Standard columns
 			{Name: "tags", Type: proto.ColumnType_JSON, Description: ColumnDescriptionTags, Transform: transform.From(adServicePrincipalTags)},
 			{Name: "title", Type: proto.ColumnType_STRING, Description: ColumnDescriptionTitle, Transform: transform.From(adServicePrincipalTitle)},
@@ -80,9 +82,35 @@ func tableAzureAdServicePrincipal(_ context.Context) *plugin.Table {
 	}
 }
 
+// servicePrincipalFilterColumns are the columns whose quals are pushed down to the $filter of list requests.
+var servicePrincipalFilterColumns = odataFilterColumns{
+	{Column: "display_name", Operators: odataStringOperators, In: true, Advanced: true},
+	{Column: "account_enabled", Type: odataBool, Operators: odataBoolOperators, Advanced: true},
+	{Column: "service_principal_type", In: true, Advanced: true},
+}
+
+var servicePrincipalSelectProperties = graphSelectProperties{
+	"add_ins":                  {"addIns"},
+	"app_roles":                {"appRoles"},
+	"info":                     {"info"},
+	"key_credentials":          {"keyCredentials"},
+	"oauth2_permission_scopes": {"oauth2PermissionScopes"},
+	"password_credentials":     {"passwordCredentials"},
+	"tags":                     {"tags"},
+	"title":                    {"displayName", "id"},
+	// Only set by the delta sync
+	"last_change_time": nil,
+}
+
 //// LIST FUNCTION
 
 func listAdServicePrincipals(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdServicePrincipals")
+
+	if usesDeltaSync(d) {
+		return listAdServicePrincipalsFromDeltaSync(ctx, d)
+	}
+
 	// Create client
 	client, adapter, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -105,30 +133,26 @@ func listAdServicePrincipals(ctx context.Context, d *plugin.QueryData, _ *plugin
 		}
 	}
 
-	var queryFilter string
-	equalQuals := d.EqualsQuals
-	quals := d.Quals
-	filter := buildServicePrincipalQueryFilter(equalQuals)
-	filter = append(filter, buildServicePrincipalBoolNEFilter(quals)...)
-
-	if equalQuals["filter"] != nil {
-		queryFilter = equalQuals["filter"].GetStringValue()
+	query := buildODataQuery(d, servicePrincipalFilterColumns)
+	if query.Filter != "" {
+		input.Filter = &query.Filter
 	}
-
-	if queryFilter != "" {
-		input.Filter = &queryFilter
-	} else if len(filter) > 0 {
-		joinStr := strings.Join(filter, " and ")
-		input.Filter = &joinStr
+	if query.Search != "" {
+		input.Search = &query.Search
 	}
+	input.Count = query.count()
+
+	// Request only the properties of the queried columns
+	input.Select = buildSelect(d, servicePrincipalSelectProperties)
 
 	options := &serviceprincipals.ServicePrincipalsRequestBuilderGetRequestConfiguration{
+		Headers:         query.headers(),
 		QueryParameters: input,
 	}
 
 	result, err := client.ServicePrincipals().Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("listAdServicePrincipals", "list_service_principal_error", errObj)
 		return nil, errObj
 	}
@@ -140,14 +164,42 @@ func listAdServicePrincipals(ctx context.Context, d *plugin.QueryData, _ *plugin
 	}
 
 	err = pageIterator.Iterate(ctx, func(pageItem models.ServicePrincipalable) bool {
-		d.StreamListItem(ctx, &ADServicePrincipalInfo{pageItem})
+		d.StreamListItem(ctx, &ADServicePrincipalInfo{pageItem, nil})
 
 		// Context can be cancelled due to manual cancellation or the limit has been hit
 		return d.RowsRemaining(ctx) != 0
 	})
 	if err != nil {
-		plugin.Logger(ctx).Error("listAdServicePrincipals", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdServicePrincipals", "paging_error", errObj)
+		return nil, errObj
+	}
+
+	return nil, nil
+}
+
+// listAdServicePrincipalsFromDeltaSync lists the service principals from the delta sync store of the connection, see delta_sync_tables.
+func listAdServicePrincipalsFromDeltaSync(ctx context.Context, d *plugin.QueryData) (interface{}, error) {
+	objects, err := deltaSyncObjects(ctx, d, deltaSyncSelect(d, servicePrincipalSelectProperties))
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdServicePrincipalsFromDeltaSync", "delta_sync_error", errObj)
+		return nil, errObj
+	}
+
+	for _, object := range objects {
+		servicePrincipal, err := parseDeltaSyncObject[models.ServicePrincipalable](object, models.CreateServicePrincipalFromDiscriminatorValue)
+		if err != nil {
+			plugin.Logger(ctx).Error("listAdServicePrincipalsFromDeltaSync", "parse_error", err)
+			return nil, err
+		}
+
+		d.StreamListItem(ctx, &ADServicePrincipalInfo{servicePrincipal, &object.LastChangeTime})
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			break
+		}
 	}
 
 	return nil, nil
@@ -156,6 +208,7 @@ func listAdServicePrincipals(ctx context.Context, d *plugin.QueryData, _ *plugin
 //// HYDRATE FUNCTIONS
 
 func getAdServicePrincipal(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdServicePrincipal")
 
 	servicePrincipalID := d.EqualsQuals["id"].GetStringValue()
 	if servicePrincipalID == "" {
@@ -169,17 +222,25 @@ func getAdServicePrincipal(ctx context.Context, d *plugin.QueryData, h *plugin.H
 		return nil, err
 	}
 
-	servicePrincipal, err := client.ServicePrincipals().ByServicePrincipalId(servicePrincipalID).Get(ctx, nil)
+	options := &serviceprincipals.ServicePrincipalItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &serviceprincipals.ServicePrincipalItemRequestBuilderGetQueryParameters{
+			Select: buildSelect(d, servicePrincipalSelectProperties),
+		},
+	}
+
+	servicePrincipal, err := client.ServicePrincipals().ByServicePrincipalId(servicePrincipalID).Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getAdServicePrincipal", "get_service_principal_error", errObj)
 		return nil, errObj
 	}
 
-	return &ADServicePrincipalInfo{servicePrincipal}, nil
+	return &ADServicePrincipalInfo{servicePrincipal, nil}, nil
 }
 
 func getServicePrincipalOwners(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getServicePrincipalOwners")
+
 	// Create client
 	client, adapter, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -209,7 +270,7 @@ func getServicePrincipalOwners(ctx context.Context, d *plugin.QueryData, h *plug
 	ownerIds := []*string{}
 	owners, err := client.ServicePrincipals().ByServicePrincipalId(*servicePrincipalID).Owners().Get(ctx, config)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getServicePrincipalOwners", "get_service_principal_owners_error", errObj)
 		return nil, errObj
 	}
@@ -227,8 +288,9 @@ func getServicePrincipalOwners(ctx context.Context, d *plugin.QueryData, h *plug
 	})
 
 	if err != nil {
-		plugin.Logger(ctx).Error("getServicePrincipalOwners", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("getServicePrincipalOwners", "paging_error", errObj)
+		return nil, errObj
 	}
 
 	return ownerIds, nil
@@ -255,50 +317,3 @@ func adServicePrincipalTitle(_ context.Context, d *transform.TransformData) (int
 
 	return title, nil
 }
-
-func buildServicePrincipalQueryFilter(equalQuals plugin.KeyColumnEqualsQualMap) []string {
-	filters := []string{}
-
-	filterQuals := map[string]string{
-		"display_name":           "string",
-		"account_enabled":        "bool",
-		"service_principal_type": "string",
-	}
-
-	for qual, qualType := range filterQuals {
-		switch qualType {
-		case "string":
-			if equalQuals[qual] != nil {
-				filters = append(filters, fmt.Sprintf("%s eq '%s'", strcase.ToCamel(qual), equalQuals[qual].GetStringValue()))
-			}
-		case "bool":
-			if equalQuals[qual] != nil {
-				filters = append(filters, fmt.Sprintf("%s eq %t", strcase.ToCamel(qual), equalQuals[qual].GetBoolValue()))
-			}
-		}
-	}
-
-	return filters
-}
-
-func buildServicePrincipalBoolNEFilter(quals plugin.KeyColumnQualMap) []string {
-	filters := []string{}
-
-	filterQuals := []string{
-		"account_enabled",
-	}
-
-	for _, qual := range filterQuals {
-		if quals[qual] != nil {
-			for _, q := range quals[qual].Quals {
-				value := q.Value.GetBoolValue()
-				if q.Operator == "<>" {
-					filters = append(filters, fmt.Sprintf("%s eq %t", strcase.ToCamel(qual), !value))
-					break
-				}
-			}
-		}
-	}
-
-	return filters
-}
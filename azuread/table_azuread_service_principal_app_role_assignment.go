@@ -2,10 +2,7 @@ package azuread
 
 import (
 	"context"
-	"fmt"
-	"strings"
 
-	"github.com/iancoleman/strcase"
 	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 	"github.com/microsoftgraph/msgraph-sdk-go/serviceprincipals"
@@ -24,7 +21,7 @@ func tableAzureAdServicePrincipalAppRoleAssignment(_ context.Context) *plugin.Ta
 		Get: &plugin.GetConfig{
 			Hydrate: getAdServicePrincipalAppRoleAssignment,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_ResourceNotFound", "Invalid object identifier"}),
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
 			},
 			KeyColumns: plugin.KeyColumnSlice{
 				{Name: "service_principal_id", Require: plugin.Required},
@@ -33,14 +30,9 @@ func tableAzureAdServicePrincipalAppRoleAssignment(_ context.Context) *plugin.Ta
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listAdServicePrincipalAppRoleAssignments,
-			KeyColumns: plugin.KeyColumnSlice{
-				// Key fields
+			KeyColumns: append(plugin.KeyColumnSlice{
 				{Name: "service_principal_id", Require: plugin.Required},
-
-				// Other fields for filtering OData
-				{Name: "resource_id", Require: plugin.Optional},
-				{Name: "principal_display_name", Require: plugin.Optional},
-			},
+			}, servicePrincipalAppRoleAssignmentFilterColumns.keyColumns()...),
 		},
 
 		Columns: []*plugin.Column{
@@ -63,9 +55,16 @@ func tableAzureAdServicePrincipalAppRoleAssignment(_ context.Context) *plugin.Ta
 	}
 }
 
+// servicePrincipalAppRoleAssignmentFilterColumns are the columns whose quals are pushed down to the $filter of list requests.
+var servicePrincipalAppRoleAssignmentFilterColumns = odataFilterColumns{
+	{Column: "resource_id", Type: odataGuid},
+	{Column: "principal_display_name"},
+}
+
 //// LIST FUNCTION
 
 func listAdServicePrincipalAppRoleAssignments(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdServicePrincipalAppRoleAssignments")
 
 	servicePrincipalId := d.EqualsQuals["service_principal_id"].GetStringValue()
 	if servicePrincipalId == "" {
@@ -94,20 +93,20 @@ func listAdServicePrincipalAppRoleAssignments(ctx context.Context, d *plugin.Que
 		}
 	}
 
-	// Apply optional filters
-	filters := buildAdServicePrincipalAppRoleAssignmentQueryFilter(d.Quals)
-	if len(filters) > 0 {
-		filterString := strings.Join(filters, " and ")
-		input.Filter = &filterString
+	if filter := buildODataFilter(d, servicePrincipalAppRoleAssignmentFilterColumns); filter != "" {
+		input.Filter = &filter
 	}
 
+	// Request only the properties of the queried columns
+	input.Select = buildSelect(d, nil)
+
 	options := &serviceprincipals.ItemAppRoleAssignmentsRequestBuilderGetRequestConfiguration{
 		QueryParameters: input,
 	}
 
 	result, err := client.ServicePrincipals().ByServicePrincipalId(servicePrincipalId).AppRoleAssignments().Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("listAdServicePrincipalAppRoleAssignments", "list_service_principal_app_role_assignment_error", errObj)
 		return nil, errObj
 	}
@@ -126,8 +125,9 @@ func listAdServicePrincipalAppRoleAssignments(ctx context.Context, d *plugin.Que
 	})
 
 	if err != nil {
-		plugin.Logger(ctx).Error("listAdServicePrincipalAppRoleAssignments", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdServicePrincipalAppRoleAssignments", "paging_error", errObj)
+		return nil, errObj
 	}
 
 	return nil, nil
@@ -136,6 +136,7 @@ func listAdServicePrincipalAppRoleAssignments(ctx context.Context, d *plugin.Que
 //// HYDRATE FUNCTIONS
 
 func getAdServicePrincipalAppRoleAssignment(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdServicePrincipalAppRoleAssignment")
 
 	servicePrincipalId := d.EqualsQuals["service_principal_id"].GetStringValue()
 	appRoleAssignmentId := d.EqualsQuals["id"].GetStringValue()
@@ -150,38 +151,18 @@ func getAdServicePrincipalAppRoleAssignment(ctx context.Context, d *plugin.Query
 		return nil, err
 	}
 
-	appRoleAssignment, err := client.ServicePrincipals().ByServicePrincipalId(servicePrincipalId).AppRoleAssignments().ByAppRoleAssignmentId(appRoleAssignmentId).Get(ctx, nil)
+	options := &serviceprincipals.ItemAppRoleAssignmentsAppRoleAssignmentItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &serviceprincipals.ItemAppRoleAssignmentsAppRoleAssignmentItemRequestBuilderGetQueryParameters{
+			Select: buildSelect(d, nil),
+		},
+	}
+
+	appRoleAssignment, err := client.ServicePrincipals().ByServicePrincipalId(servicePrincipalId).AppRoleAssignments().ByAppRoleAssignmentId(appRoleAssignmentId).Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getAdServicePrincipalAppRoleAssignment", "get_service_principal_app_role_assignment_error", errObj)
 		return nil, errObj
 	}
 
 	return &ADAppRoleAssignmentInfo{appRoleAssignment}, nil
 }
-
-//// TRANSFORM FUNCTIONS
-
-func buildAdServicePrincipalAppRoleAssignmentQueryFilter(quals plugin.KeyColumnQualMap) []string {
-	filters := []string{}
-
-	filterTypes := map[string]string{
-		"resource_id":            "guid",
-		"principal_display_name": "string",
-	}
-
-	for k, v := range quals {
-		if filterType, ok := filterTypes[k]; ok {
-			for _, q := range v.Quals {
-				switch filterType {
-				case "guid":
-					filters = append(filters, fmt.Sprintf("%s eq %s", strcase.ToCamel(k), q.Value.GetStringValue()))
-				case "string":
-					filters = append(filters, fmt.Sprintf("%s eq '%s'", strcase.ToCamel(k), q.Value.GetStringValue()))
-				}
-			}
-		}
-	}
-
-	return filters
-}
@@ -21,12 +21,13 @@ func tableAzureAdSignInReport(_ context.Context) *plugin.Table {
 		Get: &plugin.GetConfig{
 			Hydrate: getAdSignInReport,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_ResourceNotFound", "Invalid object identifier"}),
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
 			},
 			KeyColumns: plugin.SingleColumn("id"),
 		},
 		List: &plugin.ListConfig{
-			Hydrate: listAdSignInReports,
+			Hydrate:    listAdSignInReports,
+			KeyColumns: signInReportFilterColumns.keyColumns(),
 		},
 
 		Columns: commonColumns([]*plugin.Column{
@@ -62,9 +63,29 @@ func tableAzureAdSignInReport(_ context.Context) *plugin.Table {
 	}
 }
 
+// signInReportFilterColumns are the columns whose quals are pushed down to the $filter of list requests.
+var signInReportFilterColumns = odataFilterColumns{
+	{Column: "created_date_time", Type: odataTimestamp, Operators: odataTimestampOperators},
+	{Column: "user_id"},
+	{Column: "user_principal_name", Operators: odataStringOperators},
+	{Column: "user_display_name", Operators: odataStringOperators},
+	{Column: "app_id"},
+	{Column: "app_display_name", Operators: odataStringOperators},
+	{Column: "correlation_id"},
+}
+
+var signInReportSelectProperties = graphSelectProperties{
+	"applied_conditional_access_policies": {"appliedConditionalAccessPolicies"},
+	"device_detail":                       {"deviceDetail"},
+	"location":                            {"location"},
+	"status":                              {"status"},
+}
+
 //// LIST FUNCTION
 
 func listAdSignInReports(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdSignInReports")
+
 	// Create client
 	client, adapter, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -87,13 +108,20 @@ func listAdSignInReports(ctx context.Context, d *plugin.QueryData, _ *plugin.Hyd
 		}
 	}
 
+	if filter := buildODataFilter(d, signInReportFilterColumns); filter != "" {
+		input.Filter = &filter
+	}
+
+	// Request only the properties of the queried columns
+	input.Select = buildSelect(d, signInReportSelectProperties)
+
 	options := &auditlogs.SignInsRequestBuilderGetRequestConfiguration{
 		QueryParameters: input,
 	}
 
 	result, err := client.AuditLogs().SignIns().Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("listAdSignInReports", "list_sign_in_report_error", errObj)
 		return nil, errObj
 	}
@@ -114,8 +142,9 @@ func listAdSignInReports(ctx context.Context, d *plugin.QueryData, _ *plugin.Hyd
 		return d.RowsRemaining(ctx) != 0
 	})
 	if err != nil {
-		plugin.Logger(ctx).Error("listAdSignInReports", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdSignInReports", "paging_error", errObj)
+		return nil, errObj
 	}
 
 	return nil, nil
@@ -124,6 +153,8 @@ func listAdSignInReports(ctx context.Context, d *plugin.QueryData, _ *plugin.Hyd
 //// HYDRATE FUNCTIONS
 
 func getAdSignInReport(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdSignInReport")
+
 	signInID := d.EqualsQuals["id"].GetStringValue()
 	if signInID == "" {
 		return nil, nil
@@ -136,9 +167,15 @@ func getAdSignInReport(ctx context.Context, d *plugin.QueryData, h *plugin.Hydra
 		return nil, err
 	}
 
-	signIn, err := client.AuditLogs().SignIns().BySignInId(signInID).Get(ctx, nil)
+	options := &auditlogs.SignInsSignInItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &auditlogs.SignInsSignInItemRequestBuilderGetQueryParameters{
+			Select: buildSelect(d, signInReportSelectProperties),
+		},
+	}
+
+	signIn, err := client.AuditLogs().SignIns().BySignInId(signInID).Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getAdSignInReport", "get_sign_in_report_error", errObj)
 		return nil, errObj
 	}
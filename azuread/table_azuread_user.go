@@ -2,10 +2,7 @@ package azuread
 
 import (
 	"context"
-	"fmt"
-	"strings"
 
-	"github.com/iancoleman/strcase"
 	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 	"github.com/microsoftgraph/msgraph-sdk-go/users"
@@ -25,23 +22,16 @@ func tableAzureAdUser(_ context.Context) *plugin.Table {
 		Get: &plugin.GetConfig{
 			Hydrate: getAdUser,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_ResourceNotFound", "Invalid object identifier"}),
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
 			},
 			KeyColumns: plugin.SingleColumn("id"),
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listAdUsers,
-			KeyColumns: plugin.KeyColumnSlice{
-				// Key fields
-				{Name: "user_principal_name", Require: plugin.Optional},
-				{Name: "filter", Require: plugin.Optional},
-
-				// Other fields for filtering OData
-				{Name: "user_type", Require: plugin.Optional},
-				{Name: "account_enabled", Require: plugin.Optional, Operators: []string{"<>", "="}},
-				{Name: "display_name", Require: plugin.Optional},
-				{Name: "surname", Require: plugin.Optional},
-			},
+			KeyColumns: append(userFilterColumns.keyColumns(),
+				&plugin.KeyColumn{Name: "filter", Require: plugin.Optional},
+				&plugin.KeyColumn{Name: "search", Require: plugin.Optional},
+			),
 		},
 
 		Columns: commonColumns([]*plugin.Column{
@@ -55,6 +45,7 @@ func tableAzureAdUser(_ context.Context) *plugin.Table {
 			{Name: "department", Type: proto.ColumnType_STRING, Description: "The name of the department in which the user works.", Transform: transform.FromMethod("GetDepartment")},
 
 			{Name: "filter", Type: proto.ColumnType_STRING, Transform: transform.FromQual("filter"), Description: "Odata query to search for resources."},
+			{Name: "search", Type: proto.ColumnType_STRING, Transform: transform.FromQual("search"), Description: "Term to search for in the display name of the user, or an OData $search expression such as \"displayName:Ada\"."},
 
 			// Other fields
 			{Name: "on_premises_immutable_id", Type: proto.ColumnType_STRING, Description: "Used to associate an on-premises Active Directory user account with their Azure AD user object.", Transform: transform.FromMethod("GetOnPremisesImmutableId")},
@@ -64,6 +55,7 @@ func tableAzureAdUser(_ context.Context) *plugin.Table {
 			{Name: "password_policies", Type: proto.ColumnType_STRING, Description: "Specifies password policies for the user. This value is an enumeration with one possible value being DisableStrongPassword, which allows weaker passwords than the default policy to be specified. DisablePasswordExpiration can also be specified. The two may be specified together; for example: DisablePasswordExpiration, DisableStrongPassword.", Transform: transform.FromMethod("GetPasswordPolicies")},
 			{Name: "sign_in_sessions_valid_from_date_time", Type: proto.ColumnType_TIMESTAMP, Description: "Any refresh tokens or sessions tokens (session cookies) issued before this time are invalid, and applications will get an error when using an invalid refresh or sessions token to acquire a delegated access token (to access APIs such as Microsoft Graph).", Transform: transform.FromMethod("GetSignInSessionsValidFromDateTime")},
 			{Name: "usage_location", Type: proto.ColumnType_STRING, Description: "A two letter country code (ISO standard 3166), required for users that will be assigned licenses due to legal requirement to check for availability of services in countries.", Transform: transform.FromMethod("GetUsageLocation")},
+			{Name: "last_change_time", Type: proto.ColumnType_TIMESTAMP, Description: "The time the delta sync last saw the user change, i.e. of the sync which first returned it or last returned changes to it. Null unless the table is listed in delta_sync_tables in the connection config.", Transform: transform.FromField("LastChangeTime")},
 
 			// Json fields
 			{Name: "member_of", Type: proto.ColumnType_JSON, Description: "A list the groups and directory roles that the user is a direct member of.", Transform: transform.FromMethod("UserMemberOf")},
@@ -71,15 +63,34 @@ func tableAzureAdUser(_ context.Context) *plugin.Table {
 			{Name: "other_mails", Type: proto.ColumnType_JSON, Description: "A list of additional email addresses for the user.", Transform: transform.FromMethod("GetOtherMails")},
 			{Name: "password_profile", Type: proto.ColumnType_JSON, Description: "Specifies the password profile for the user. The profile contains the user’s password. This property is required when a user is created.", Transform: transform.FromMethod("UserPasswordProfile")},
 
+			// Beta fields
+			{Name: "sign_in_activity", Type: proto.ColumnType_JSON, Description: betaColumnDescription("The last interactive, non-interactive and successful sign-in of the user."), Transform: transform.FromMethod("UserSignInActivity")},
+			{Name: "beta_properties", Type: proto.ColumnType_JSON, Description: betaColumnDescription("Properties of the user only returned by the beta endpoint."), Hydrate: getGraphBetaProperties, Transform: transform.FromValue()},
+
 			// Standard columns
 			{Name: "title", Type: proto.ColumnType_STRING, Description: ColumnDescriptionTitle, Transform: transform.From(adUserTitle)},
 		}),
 	}
 }
 
+// userFilterColumns are the columns whose quals are pushed down to the $filter of list requests.
+var userFilterColumns = odataFilterColumns{
+	{Column: "user_principal_name", Operators: odataStringOperators, In: true, Advanced: true},
+	{Column: "user_type", In: true, Advanced: true},
+	{Column: "account_enabled", Type: odataBool, Operators: odataBoolOperators, Advanced: true},
+	{Column: "display_name", Operators: odataStringOperators, In: true, Advanced: true},
+	{Column: "surname", Operators: odataStringOperators, In: true, Advanced: true},
+}
+
 //// LIST FUNCTION
 
 func listAdUsers(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdUsers")
+
+	if usesDeltaSync(d) {
+		return listAdUsersFromDeltaSync(ctx, d)
+	}
+
 	// Create client
 	client, adapter, err := GetGraphClient(ctx, d)
 	if err != nil {
@@ -102,38 +113,30 @@ func listAdUsers(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData
 		}
 	}
 
-	equalQuals := d.EqualsQuals
-	quals := d.Quals
-
 	// Check for query context and requests only for queried columns
 	givenColumns := d.QueryContext.Columns
-	selectColumns, expandColumns := buildUserRequestFields(ctx, givenColumns)
+	selectColumns, expandColumns := buildUserRequestFields(d.Table.Columns, givenColumns, usesGraphBeta(d))
 
 	input.Select = selectColumns
 	input.Expand = expandColumns
 
-	var queryFilter string
-	filter := buildQueryFilter(equalQuals)
-	filter = append(filter, buildBoolNEFilter(quals)...)
-
-	if equalQuals["filter"] != nil {
-		queryFilter = equalQuals["filter"].GetStringValue()
+	query := buildODataQuery(d, userFilterColumns)
+	if query.Filter != "" {
+		input.Filter = &query.Filter
 	}
-
-	if queryFilter != "" {
-		input.Filter = &queryFilter
-	} else if len(filter) > 0 {
-		joinStr := strings.Join(filter, " and ")
-		input.Filter = &joinStr
+	if query.Search != "" {
+		input.Search = &query.Search
 	}
+	input.Count = query.count()
 
 	options := &users.UsersRequestBuilderGetRequestConfiguration{
+		Headers:         query.headers(),
 		QueryParameters: input,
 	}
 
 	result, err := client.Users().Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("listAdUsers", "list_user_error", errObj)
 		return nil, errObj
 	}
@@ -147,14 +150,45 @@ func listAdUsers(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData
 	err = pageIterator.Iterate(ctx, func(pageItem models.Userable) bool {
 		refreshTokensValidFromDateTime := pageItem.GetAdditionalData()["refreshTokensValidFromDateTime"]
 
-		d.StreamListItem(ctx, &ADUserInfo{pageItem, refreshTokensValidFromDateTime})
+		d.StreamListItem(ctx, &ADUserInfo{pageItem, refreshTokensValidFromDateTime, nil})
 
 		// Context can be cancelled due to manual cancellation or the limit has been hit
 		return d.RowsRemaining(ctx) != 0
 	})
 	if err != nil {
-		plugin.Logger(ctx).Error("listAdUsers", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdUsers", "paging_error", errObj)
+		return nil, errObj
+	}
+
+	return nil, nil
+}
+
+// listAdUsersFromDeltaSync lists the users from the delta sync store of the
+// connection, see delta_sync_tables. Delta queries can't return the sign-in
+// activity, nor expand the groups the users are members of.
+func listAdUsersFromDeltaSync(ctx context.Context, d *plugin.QueryData) (interface{}, error) {
+	objects, err := deltaSyncObjects(ctx, d, deltaSyncSelect(d, userSelectProperties, "signInActivity"))
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdUsersFromDeltaSync", "delta_sync_error", errObj)
+		return nil, errObj
+	}
+
+	for _, object := range objects {
+		user, err := parseDeltaSyncObject[models.Userable](object, models.CreateUserFromDiscriminatorValue)
+		if err != nil {
+			plugin.Logger(ctx).Error("listAdUsersFromDeltaSync", "parse_error", err)
+			return nil, err
+		}
+		refreshTokensValidFromDateTime := user.GetAdditionalData()["refreshTokensValidFromDateTime"]
+
+		d.StreamListItem(ctx, &ADUserInfo{user, refreshTokensValidFromDateTime, &object.LastChangeTime})
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			break
+		}
 	}
 
 	return nil, nil
@@ -163,6 +197,7 @@ func listAdUsers(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData
 //// HYDRATE FUNCTIONS
 
 func getAdUser(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdUser")
 
 	// Create client
 	client, _, err := GetGraphClient(ctx, d)
@@ -178,7 +213,7 @@ func getAdUser(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData)
 
 	// Check for query context and requests only for queried columns
 	givenColumns := d.QueryContext.Columns
-	selectColumns, expandColumns := buildUserRequestFields(ctx, givenColumns)
+	selectColumns, expandColumns := buildUserRequestFields(d.Table.Columns, givenColumns, usesGraphBeta(d))
 
 	input := &users.UserItemRequestBuilderGetQueryParameters{}
 	input.Select = selectColumns
@@ -190,42 +225,44 @@ func getAdUser(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData)
 
 	user, err := client.Users().ByUserId(userId).Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getAdUser", "get_user_error", errObj)
 		return nil, errObj
 	}
 	refreshTokensValidFromDateTime := user.GetAdditionalData()["refreshTokensValidFromDateTime"]
 
-	return &ADUserInfo{user, refreshTokensValidFromDateTime}, nil
+	return &ADUserInfo{user, refreshTokensValidFromDateTime, nil}, nil
 }
 
-func buildUserRequestFields(ctx context.Context, queryColumns []string) ([]string, []string) {
-	var selectColumns, expandColumns []string
-
-	for _, columnName := range queryColumns {
-		if columnName == "filter" || columnName == "tenant_id" {
-			continue
-		}
+var userSelectProperties = graphSelectProperties{
+	// Expanded rather than selected
+	"member_of":        nil,
+	"password_profile": {"passwordProfile"},
+	"sign_in_activity": {"signInActivity"},
+	"title":            {"displayName", "userPrincipalName"},
+	// Only set by the delta sync
+	"last_change_time": nil,
+}
 
-		if columnName == "member_of" {
-			expandColumns = append(expandColumns, fmt.Sprintf("%s($select=id,displayName)", strcase.ToLowerCamel(columnName)))
-			continue
-		}
+func buildUserRequestFields(columns []*plugin.Column, queryColumns []string, beta bool) ([]string, []string) {
+	var expandColumns []string
+	if helpers.StringSliceContains(queryColumns, "member_of") {
+		expandColumns = append(expandColumns, "memberOf($select=id,displayName)")
+	}
 
-		if columnName == "title" {
-			if !helpers.StringSliceContains(queryColumns, "display_name") {
-				selectColumns = append(selectColumns, []string{"displayName"}...)
-			}
-			if !helpers.StringSliceContains(queryColumns, "user_principal_name") {
-				selectColumns = append(selectColumns, []string{"userPrincipalName"}...)
-			}
-			continue
-		}
+	// The beta-only sign-in details are not requested from v1.0
+	if !beta {
+		queryColumns = helpers.RemoveFromStringSlice(queryColumns, "sign_in_activity")
+	}
 
-		selectColumns = append(selectColumns, strcase.ToLowerCamel(columnName))
+	// Beta properties are whatever the beta endpoint returns by default, so the
+	// properties are not restricted unless the sign-in details, which are only
+	// returned when selected, are requested too
+	if beta && helpers.StringSliceContains(queryColumns, "beta_properties") && !helpers.StringSliceContains(queryColumns, "sign_in_activity") {
+		return nil, expandColumns
 	}
 
-	return selectColumns, expandColumns
+	return selectProperties(columns, queryColumns, userSelectProperties), expandColumns
 }
 
 //// TRANSFORM FUNCTIONS
@@ -243,59 +280,3 @@ func adUserTitle(_ context.Context, d *transform.TransformData) (interface{}, er
 
 	return title, nil
 }
-
-func buildQueryFilter(equalQuals plugin.KeyColumnEqualsQualMap) []string {
-	filters := []string{}
-
-	filterQuals := map[string]string{
-		"display_name":             "string",
-		"id":                       "string",
-		"surname":                  "string",
-		"user_principal_name":      "string",
-		"user_type":                "string",
-		"account_enabled":          "bool",
-		"mail_enabled":             "bool",
-		"security_enabled":         "bool",
-		"on_premises_sync_enabled": "bool",
-	}
-
-	for qual, qualType := range filterQuals {
-		switch qualType {
-		case "string":
-			if equalQuals[qual] != nil {
-				filters = append(filters, fmt.Sprintf("%s eq '%s'", strcase.ToCamel(qual), equalQuals[qual].GetStringValue()))
-			}
-		case "bool":
-			if equalQuals[qual] != nil {
-				filters = append(filters, fmt.Sprintf("%s eq %t", strcase.ToCamel(qual), equalQuals[qual].GetBoolValue()))
-			}
-		}
-	}
-
-	return filters
-}
-
-func buildBoolNEFilter(quals plugin.KeyColumnQualMap) []string {
-	filters := []string{}
-
-	filterQuals := []string{
-		"account_enabled",
-		"mail_enabled",
-		"on_premises_sync_enabled",
-		"security_enabled",
-	}
-
-	for _, qual := range filterQuals {
-		if quals[qual] != nil {
-			for _, q := range quals[qual].Quals {
-				value := q.Value.GetBoolValue()
-				if q.Operator == "<>" {
-					filters = append(filters, fmt.Sprintf("%s eq %t", strcase.ToCamel(qual), !value))
-					break
-				}
-			}
-		}
-	}
-
-	return filters
-}
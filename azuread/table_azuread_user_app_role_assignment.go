@@ -2,10 +2,7 @@ package azuread
 
 import (
 	"context"
-	"fmt"
-	"strings"
 
-	"github.com/iancoleman/strcase"
 	abstractions "github.com/microsoft/kiota-abstractions-go"
 	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
@@ -25,7 +22,7 @@ func tableAzureAdUserAppRoleAssignment(_ context.Context) *plugin.Table {
 		Get: &plugin.GetConfig{
 			Hydrate: getAdUserAppRoleAssignment,
 			IgnoreConfig: &plugin.IgnoreConfig{
-				ShouldIgnoreErrorFunc: isIgnorableErrorPredicate([]string{"Request_ResourceNotFound", "Invalid object identifier"}),
+				ShouldIgnoreErrorFunc: isIgnorableErrorCategoryPredicate(ErrorCategoryNotFound),
 			},
 			KeyColumns: plugin.KeyColumnSlice{
 				{Name: "user_id", Require: plugin.Required},
@@ -34,14 +31,9 @@ func tableAzureAdUserAppRoleAssignment(_ context.Context) *plugin.Table {
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listAdUserAppRoleAssignments,
-			KeyColumns: plugin.KeyColumnSlice{
-				// Key fields
+			KeyColumns: append(plugin.KeyColumnSlice{
 				{Name: "user_id", Require: plugin.Required},
-
-				// Other fields for filtering OData
-				{Name: "resource_id", Require: plugin.Optional},
-				{Name: "principal_display_name", Require: plugin.Optional},
-			},
+			}, userAppRoleAssignmentFilterColumns.keyColumns()...),
 		},
 
 		Columns: []*plugin.Column{
@@ -64,9 +56,21 @@ func tableAzureAdUserAppRoleAssignment(_ context.Context) *plugin.Table {
 	}
 }
 
+// userAppRoleAssignmentFilterColumns are the columns whose quals are pushed down to the $filter of list requests.
+var userAppRoleAssignmentFilterColumns = odataFilterColumns{
+	{Column: "resource_id", Type: odataGuid},
+	{Column: "principal_display_name"},
+}
+
+var userAppRoleAssignmentSelectProperties = graphSelectProperties{
+	// The user of the key column
+	"user_id": nil,
+}
+
 //// LIST FUNCTION
 
 func listAdUserAppRoleAssignments(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdUserAppRoleAssignments")
 
 	userId := d.EqualsQuals["user_id"].GetStringValue()
 	if userId == "" {
@@ -99,13 +103,13 @@ func listAdUserAppRoleAssignments(ctx context.Context, d *plugin.QueryData, _ *p
 		}
 	}
 
-	// Apply optional filters
-	filters := buildAdUserAppRoleAssignmentQueryFilter(d.Quals)
-	if len(filters) > 0 {
-		filterString := strings.Join(filters, " and ")
-		input.Filter = &filterString
+	if filter := buildODataFilter(d, userAppRoleAssignmentFilterColumns); filter != "" {
+		input.Filter = &filter
 	}
 
+	// Request only the properties of the queried columns
+	input.Select = buildSelect(d, userAppRoleAssignmentSelectProperties)
+
 	options := &users.ItemAppRoleAssignmentsRequestBuilderGetRequestConfiguration{
 		Headers:         headers,
 		QueryParameters: input,
@@ -113,7 +117,7 @@ func listAdUserAppRoleAssignments(ctx context.Context, d *plugin.QueryData, _ *p
 
 	result, err := client.Users().ByUserId(userId).AppRoleAssignments().Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("listAdUserAppRoleAssignments", "list_user_app_role_assignment_error", errObj)
 		return nil, errObj
 	}
@@ -132,8 +136,9 @@ func listAdUserAppRoleAssignments(ctx context.Context, d *plugin.QueryData, _ *p
 	})
 
 	if err != nil {
-		plugin.Logger(ctx).Error("listAdUserAppRoleAssignments", "paging_error", err)
-		return nil, err
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdUserAppRoleAssignments", "paging_error", errObj)
+		return nil, errObj
 	}
 
 	return nil, nil
@@ -142,6 +147,7 @@ func listAdUserAppRoleAssignments(ctx context.Context, d *plugin.QueryData, _ *p
 //// HYDRATE FUNCTIONS
 
 func getAdUserAppRoleAssignment(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "getAdUserAppRoleAssignment")
 
 	userId := d.EqualsQuals["user_id"].GetStringValue()
 	appRoleAssignmentId := d.EqualsQuals["id"].GetStringValue()
@@ -156,11 +162,15 @@ func getAdUserAppRoleAssignment(ctx context.Context, d *plugin.QueryData, h *plu
 		return nil, err
 	}
 
-	options := &users.ItemAppRoleAssignmentsAppRoleAssignmentItemRequestBuilderGetRequestConfiguration{}
+	options := &users.ItemAppRoleAssignmentsAppRoleAssignmentItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemAppRoleAssignmentsAppRoleAssignmentItemRequestBuilderGetQueryParameters{
+			Select: buildSelect(d, userAppRoleAssignmentSelectProperties),
+		},
+	}
 
 	appRoleAssignment, err := client.Users().ByUserId(userId).AppRoleAssignments().ByAppRoleAssignmentId(appRoleAssignmentId).Get(ctx, options)
 	if err != nil {
-		errObj := getErrorObject(err)
+		errObj := getErrorObject(d, err)
 		plugin.Logger(ctx).Error("getAdUserAppRoleAssignment", "get_user_app_role_assignment_error", errObj)
 		return nil, errObj
 	}
@@ -178,27 +188,3 @@ func adUserAppRoleAssignmentUserId(_ context.Context, d *transform.TransformData
 
 	return data.UserId, nil
 }
-
-func buildAdUserAppRoleAssignmentQueryFilter(quals plugin.KeyColumnQualMap) []string {
-	filters := []string{}
-
-	filterTypes := map[string]string{
-		"resource_id":            "guid",
-		"principal_display_name": "string",
-	}
-
-	for k, v := range quals {
-		if filterType, ok := filterTypes[k]; ok {
-			for _, q := range v.Quals {
-				switch filterType {
-				case "guid":
-					filters = append(filters, fmt.Sprintf("%s eq %s", strcase.ToCamel(k), q.Value.GetStringValue()))
-				case "string":
-					filters = append(filters, fmt.Sprintf("%s eq '%s'", strcase.ToCamel(k), q.Value.GetStringValue()))
-				}
-			}
-		}
-	}
-
-	return filters
-}
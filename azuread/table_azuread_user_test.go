@@ -0,0 +1,86 @@
+package azuread
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/quals"
+)
+
+func TestUserFilterColumns(t *testing.T) {
+	keyQuals := plugin.KeyColumnQualMap{
+		"display_name": &plugin.KeyColumnQuals{
+			Name:  "display_name",
+			Quals: quals.QualSlice{{Column: "display_name", Operator: "=", Value: &proto.QualValue{Value: &proto.QualValue_StringValue{StringValue: "Ada O'Lovelace"}}}},
+		},
+		"user_type": &plugin.KeyColumnQuals{
+			Name:  "user_type",
+			Quals: quals.QualSlice{{Column: "user_type", Operator: "=", Value: &proto.QualValue{Value: &proto.QualValue_StringValue{StringValue: "Member"}}}},
+		},
+		"account_enabled": &plugin.KeyColumnQuals{
+			Name:  "account_enabled",
+			Quals: quals.QualSlice{{Column: "account_enabled", Operator: "<>", Value: &proto.QualValue{Value: &proto.QualValue_BoolValue{BoolValue: true}}}},
+		},
+	}
+
+	got, advanced := userFilterColumns.filter(keyQuals)
+	want := "userType eq 'Member' and accountEnabled eq false and displayName eq 'Ada O''Lovelace'"
+	if got != want || advanced {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestBuildUserRequestFields(t *testing.T) {
+	cases := []struct {
+		columns    []string
+		beta       bool
+		wantSelect []string
+		wantExpand []string
+	}{
+		{
+			columns:    []string{"id", "tenant_id", "filter"},
+			wantSelect: []string{"id"},
+		},
+		{
+			columns:    []string{"title"},
+			wantSelect: []string{"displayName", "userPrincipalName"},
+		},
+		{
+			columns:    []string{"display_name", "title"},
+			wantSelect: []string{"displayName", "userPrincipalName"},
+		},
+		{
+			columns:    []string{"id", "member_of"},
+			wantSelect: []string{"id"},
+			wantExpand: []string{"memberOf($select=id,displayName)"},
+		},
+		{
+			columns:    []string{"id", "sign_in_activity", "beta_properties"},
+			wantSelect: []string{"id"},
+		},
+		{
+			columns:    []string{"id", "sign_in_activity", "beta_properties"},
+			beta:       true,
+			wantSelect: []string{"id", "signInActivity"},
+		},
+		{
+			columns:    []string{"id", "beta_properties", "member_of"},
+			beta:       true,
+			wantExpand: []string{"memberOf($select=id,displayName)"},
+		},
+	}
+
+	columns := tableAzureAdUser(context.Background()).Columns
+	for _, tc := range cases {
+		gotSelect, gotExpand := buildUserRequestFields(columns, tc.columns, tc.beta)
+		if !reflect.DeepEqual(gotSelect, tc.wantSelect) {
+			t.Errorf("%v: expected select %v, got %v", tc.columns, tc.wantSelect, gotSelect)
+		}
+		if !reflect.DeepEqual(gotExpand, tc.wantExpand) {
+			t.Errorf("%v: expected expand %v, got %v", tc.columns, tc.wantExpand, gotExpand)
+		}
+	}
+}
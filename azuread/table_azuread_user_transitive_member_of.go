@@ -0,0 +1,248 @@
+package azuread
+
+import (
+	"context"
+	"sync"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
+	"github.com/microsoftgraph/msgraph-sdk-go/groups"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableAzureAdUserTransitiveMemberOf(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "azuread_user_transitive_member_of",
+		Description: "Represents a group, directory role or administrative unit which an Azure AD user is a member of, directly or through nested groups.",
+		List: &plugin.ListConfig{
+			Hydrate: listAdUserTransitiveMemberOf,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "user_id", Require: plugin.Required},
+			},
+		},
+
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "user_id", Type: proto.ColumnType_STRING, Description: "The unique identifier of the user.", Transform: transform.FromField("UserId")},
+			{Name: "id", Type: proto.ColumnType_STRING, Description: "The unique identifier of the group, directory role or administrative unit.", Transform: transform.FromMethod("GetId")},
+			{Name: "object_type", Type: proto.ColumnType_STRING, Description: "The type of the object the user is a member of, i.e. group, directoryRole or administrativeUnit.", Transform: transform.FromMethod("MemberOfType")},
+			{Name: "display_name", Type: proto.ColumnType_STRING, Description: "The display name of the group, directory role or administrative unit.", Transform: transform.FromMethod("MemberOfDisplayName")},
+			{Name: "direct", Type: proto.ColumnType_BOOL, Description: "True if the user is a direct member, false if the membership is granted through nested groups.", Transform: transform.FromMethod("MemberOfDirect")},
+			{Name: "depth", Type: proto.ColumnType_INT, Description: "The number of memberships in the path, i.e. 1 for a direct membership.", Transform: transform.FromMethod("MemberOfDepth")},
+			{Name: "path", Type: proto.ColumnType_JSON, Description: "The shortest chain of memberships which grants the membership, from the group the user is a direct member of to the object itself.", Transform: transform.FromField("Path")},
+		}),
+	}
+}
+
+//// LIST FUNCTION
+
+func listAdUserTransitiveMemberOf(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	ctx = withGraphTelemetry(ctx, d, "listAdUserTransitiveMemberOf")
+
+	userId := d.EqualsQuals["user_id"].GetStringValue()
+	if userId == "" {
+		return nil, nil
+	}
+
+	client, adapter, err := GetGraphClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("azuread_user_transitive_member_of.listAdUserTransitiveMemberOf", "connection_error", err)
+		return nil, err
+	}
+
+	selectProperties := []string{"id", "displayName"}
+
+	result, err := client.Users().ByUserId(userId).TransitiveMemberOf().Get(ctx, &users.ItemTransitiveMemberOfRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemTransitiveMemberOfRequestBuilderGetQueryParameters{
+			Top:    Int32(999),
+			Select: selectProperties,
+		},
+	})
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdUserTransitiveMemberOf", "list_user_transitive_member_of_error", errObj)
+		return nil, errObj
+	}
+
+	pageIterator, err := msgraphcore.NewPageIterator[models.DirectoryObjectable](result, adapter, models.CreateDirectoryObjectCollectionResponseFromDiscriminatorValue)
+	if err != nil {
+		plugin.Logger(ctx).Error("listAdUserTransitiveMemberOf", "create_iterator_instance_error", err)
+		return nil, err
+	}
+
+	var memberships []models.DirectoryObjectable
+	found := map[string]bool{}
+	err = pageIterator.Iterate(ctx, func(object models.DirectoryObjectable) bool {
+		if object.GetId() != nil && !found[*object.GetId()] {
+			found[*object.GetId()] = true
+			memberships = append(memberships, object)
+		}
+		return true
+	})
+	if err != nil {
+		errObj := getErrorObject(d, err)
+		plugin.Logger(ctx).Error("listAdUserTransitiveMemberOf", "paging_error", errObj)
+		return nil, errObj
+	}
+
+	// The paths are only needed for the path, depth and direct columns
+	var paths map[string][]map[string]interface{}
+	for _, column := range []string{"path", "depth", "direct"} {
+		if helpers.StringSliceContains(d.QueryContext.Columns, column) {
+			paths, err = walkUserMemberOfPaths(ctx, d, userId, found, selectProperties)
+			if err != nil {
+				errObj := getErrorObject(d, err)
+				plugin.Logger(ctx).Error("listAdUserTransitiveMemberOf", "list_member_of_error", errObj)
+				return nil, errObj
+			}
+			break
+		}
+	}
+
+	for _, object := range memberships {
+		d.StreamListItem(ctx, &ADUserTransitiveMemberOfInfo{object, userId, paths[*object.GetId()]})
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+//// UTILITY FUNCTIONS
+
+// walkUserMemberOfPaths returns the shortest chain of memberships of the user
+// to each of the given memberships. The memberOf relationship is walked
+// breadth first, from the direct memberships of the user up through the groups
+// they are nested in, so each membership is found with its shortest path. The
+// groups of a level are requested concurrently, so they share Graph batches.
+// Each group is only walked once, which also stops at cycles of nested groups,
+// and the walk stops as soon as every membership has a path.
+func walkUserMemberOfPaths(ctx context.Context, d *plugin.QueryData, userId string, memberships map[string]bool, selectProperties []string) (map[string][]map[string]interface{}, error) {
+	client, _, err := GetGraphClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	requestInfo, err := client.Users().ByUserId(userId).MemberOf().ToGetRequestInformation(ctx, &users.ItemMemberOfRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMemberOfRequestBuilderGetQueryParameters{
+			Top:    Int32(999),
+			Select: selectProperties,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	paths := map[string][]map[string]interface{}{}
+	unreached := len(memberships)
+	// reach sets the path of the objects a membership leads to, and returns the
+	// groups among them which are walked next
+	reach := func(objects []models.DirectoryObjectable, parentPath []map[string]interface{}) []string {
+		var groupIds []string
+		for _, object := range objects {
+			if object.GetId() == nil {
+				continue
+			}
+			if _, ok := paths[*object.GetId()]; ok {
+				continue
+			}
+			typeName := directoryObjectType(object)
+			paths[*object.GetId()] = append(append([]map[string]interface{}{}, parentPath...), map[string]interface{}{
+				"id":           *object.GetId(),
+				"display_name": directoryObjectDisplayName(object),
+				"object_type":  typeName,
+			})
+			if memberships[*object.GetId()] {
+				unreached--
+			}
+			if typeName != nil && *typeName == "group" {
+				groupIds = append(groupIds, *object.GetId())
+			}
+		}
+		return groupIds
+	}
+
+	direct, err := batchListMemberOf(ctx, d, requestInfo)
+	if err != nil {
+		return nil, err
+	}
+	level := reach(direct, nil)
+
+	for len(level) > 0 && unreached > 0 {
+		parents := make([][]models.DirectoryObjectable, len(level))
+		errs := make([]error, len(level))
+
+		var wg sync.WaitGroup
+		limit := make(chan struct{}, maxBatchedHydrateConcurrency)
+		for i, groupId := range level {
+			wg.Add(1)
+			limit <- struct{}{}
+			go func(i int, groupId string) {
+				defer func() {
+					<-limit
+					wg.Done()
+				}()
+				requestInfo, err := client.Groups().ByGroupId(groupId).MemberOf().ToGetRequestInformation(ctx, &groups.ItemMemberOfRequestBuilderGetRequestConfiguration{
+					QueryParameters: &groups.ItemMemberOfRequestBuilderGetQueryParameters{
+						Top:    Int32(999),
+						Select: selectProperties,
+					},
+				})
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				parents[i], errs[i] = batchListMemberOf(ctx, d, requestInfo)
+			}(i, groupId)
+		}
+		wg.Wait()
+
+		var next []string
+		for i, groupId := range level {
+			if errs[i] != nil {
+				return nil, errs[i]
+			}
+			next = append(next, reach(parents[i], paths[groupId])...)
+		}
+		level = next
+	}
+
+	return paths, nil
+}
+
+// batchListMemberOf returns every object of a memberOf request. The first page
+// is requested in a batch with the concurrent requests of the other groups of
+// the walk.
+func batchListMemberOf(ctx context.Context, d *plugin.QueryData, requestInfo *abstractions.RequestInformation) ([]models.DirectoryObjectable, error) {
+	_, adapter, err := GetGraphClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := batchGet[models.DirectoryObjectCollectionResponseable](ctx, d, requestInfo, models.CreateDirectoryObjectCollectionResponseFromDiscriminatorValue)
+	if err != nil {
+		return nil, err
+	}
+
+	pageIterator, err := msgraphcore.NewPageIterator[models.DirectoryObjectable](result, adapter, models.CreateDirectoryObjectCollectionResponseFromDiscriminatorValue)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []models.DirectoryObjectable
+	err = pageIterator.Iterate(ctx, func(object models.DirectoryObjectable) bool {
+		objects = append(objects, object)
+		return true
+	})
+	return objects, err
+}
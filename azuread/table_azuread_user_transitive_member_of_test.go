@@ -0,0 +1,109 @@
+package azuread
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+func TestUserTransitiveMemberOf(t *testing.T) {
+	standIn.reset()
+	// u1 is in g1, which is in g2 and the administrative unit au1. g2 is in g3,
+	// which has the Global Reader role and is in g1 again.
+	standIn.setCollection("users/u1/transitiveMemberOf",
+		map[string]interface{}{"@odata.type": "#microsoft.graph.group", "id": "g1", "displayName": "Engineering"},
+		map[string]interface{}{"@odata.type": "#microsoft.graph.directoryRole", "id": "r2", "displayName": "Reports Reader"},
+		map[string]interface{}{"@odata.type": "#microsoft.graph.group", "id": "g2", "displayName": "All staff"},
+		map[string]interface{}{"@odata.type": "#microsoft.graph.administrativeUnit", "id": "au1", "displayName": "Europe"},
+		map[string]interface{}{"@odata.type": "#microsoft.graph.group", "id": "g3", "displayName": "Readers"},
+		map[string]interface{}{"@odata.type": "#microsoft.graph.directoryRole", "id": "r1", "displayName": "Global Reader"},
+	)
+	standIn.setCollection("users/u1/memberOf",
+		map[string]interface{}{"@odata.type": "#microsoft.graph.group", "id": "g1", "displayName": "Engineering"},
+		map[string]interface{}{"@odata.type": "#microsoft.graph.directoryRole", "id": "r2", "displayName": "Reports Reader"},
+	)
+	standIn.setCollection("groups/g1/memberOf",
+		map[string]interface{}{"@odata.type": "#microsoft.graph.group", "id": "g2", "displayName": "All staff"},
+		map[string]interface{}{"@odata.type": "#microsoft.graph.administrativeUnit", "id": "au1", "displayName": "Europe"},
+	)
+	standIn.setCollection("groups/g2/memberOf",
+		map[string]interface{}{"@odata.type": "#microsoft.graph.group", "id": "g3", "displayName": "Readers"},
+	)
+	standIn.setCollection("groups/g3/memberOf",
+		map[string]interface{}{"@odata.type": "#microsoft.graph.directoryRole", "id": "r1", "displayName": "Global Reader"},
+		map[string]interface{}{"@odata.type": "#microsoft.graph.group", "id": "g1", "displayName": "Engineering"},
+	)
+
+	columns := []string{"user_id", "id", "object_type", "display_name", "direct", "depth", "path"}
+	rows, err := executeQuery(t, "azuread_user_transitive_member_of", columns, equalsQuals(map[string]string{"user_id": "u1"}))
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	memberships := map[string]map[string]*proto.Column{}
+	for _, row := range rows {
+		memberships[row["id"].GetStringValue()] = row
+	}
+	if len(rows) != 6 || len(memberships) != 6 {
+		t.Fatalf("expected 6 memberships, got %d rows", len(rows))
+	}
+
+	for id, want := range map[string]struct {
+		objectType string
+		direct     bool
+		depth      int64
+	}{
+		"g1":  {"group", true, 1},
+		"r2":  {"directoryRole", true, 1},
+		"g2":  {"group", false, 2},
+		"au1": {"administrativeUnit", false, 2},
+		"g3":  {"group", false, 3},
+		"r1":  {"directoryRole", false, 4},
+	} {
+		row := memberships[id]
+		if row["object_type"].GetStringValue() != want.objectType || row["direct"].GetBoolValue() != want.direct || row["depth"].GetIntValue() != want.depth {
+			t.Errorf("%s: unexpected membership %v", id, row)
+		}
+		if row["user_id"].GetStringValue() != "u1" {
+			t.Errorf("%s: unexpected user %v", id, row["user_id"])
+		}
+	}
+
+	var path []map[string]interface{}
+	if err := json.Unmarshal(memberships["r1"]["path"].GetJsonValue(), &path); err != nil {
+		t.Fatalf("invalid path: %v", err)
+	}
+	var ids []string
+	for _, p := range path {
+		ids = append(ids, p["id"].(string))
+	}
+	if len(ids) != 4 || ids[0] != "g1" || ids[1] != "g2" || ids[2] != "g3" || ids[3] != "r1" || path[3]["display_name"] != "Global Reader" {
+		t.Errorf("unexpected path %v", path)
+	}
+
+	// The walk stops once every membership has a path, so the cycle back to g1
+	// is not followed
+	if len(standIn.requestsFor("groups/g1/memberOf")) != 1 || len(standIn.requestsFor("groups/g3/memberOf")) != 1 {
+		t.Errorf("expected g1 and g3 to be expanded once, got %d and %d requests", len(standIn.requestsFor("groups/g1/memberOf")), len(standIn.requestsFor("groups/g3/memberOf")))
+	}
+	if len(standIn.requestsFor("$batch")) == 0 {
+		t.Errorf("expected the memberships of the groups to be requested in batches")
+	}
+
+	// The memberships alone need a single request
+	standIn.reset()
+	standIn.setCollection("users/u1/transitiveMemberOf",
+		map[string]interface{}{"@odata.type": "#microsoft.graph.group", "id": "g1", "displayName": "Engineering"},
+		map[string]interface{}{"@odata.type": "#microsoft.graph.group", "id": "g2", "displayName": "All staff"},
+	)
+	rows, err = executeQuery(t, "azuread_user_transitive_member_of", []string{"id", "display_name"}, equalsQuals(map[string]string{"user_id": "u1"}))
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Errorf("expected 2 memberships, got %d", len(rows))
+	}
+	if got := len(standIn.requestsFor("users/u1/memberOf")) + len(standIn.requestsFor("$batch")); got != 0 {
+		t.Errorf("expected the memberships not to be walked, got %d requests", got)
+	}
+}
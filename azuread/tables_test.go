@@ -0,0 +1,720 @@
+package azuread
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+)
+
+// tableTestCase describes the Graph data a table needs and the quals used to
+// run its List and Get hydrates against the Graph stand-in server.
+type tableTestCase struct {
+	table     string
+	setup     func(s *graphStandIn)
+	listQuals map[string]string
+	listRows  int
+	// getQuals is nil for tables without a Get config
+	getQuals map[string]string
+}
+
+func appRoleAssignment(id, principalType string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                   id,
+		"appRoleId":            "00000000-0000-0000-0000-000000000000",
+		"createdDateTime":      "2024-01-02T03:04:05Z",
+		"principalDisplayName": "Principal " + id,
+		"principalId":          "22222222-2222-2222-2222-222222222222",
+		"principalType":        principalType,
+		"resourceDisplayName":  "Resource",
+		"resourceId":           "33333333-3333-3333-3333-333333333333",
+	}
+}
+
+func nestedGroup(id, displayName string) map[string]interface{} {
+	return map[string]interface{}{"@odata.type": "#microsoft.graph.group", "id": id, "displayName": displayName}
+}
+
+var tableTestCases = []tableTestCase{
+	{
+		table: "azuread_admin_consent_request_policy",
+		setup: func(s *graphStandIn) {
+			s.setObject("policies/adminConsentRequestPolicy", map[string]interface{}{
+				"id":                    "adminConsentRequestPolicy",
+				"isEnabled":             true,
+				"notifyReviewers":       true,
+				"remindersEnabled":      false,
+				"requestDurationInDays": 30,
+				"version":               2,
+				"reviewers": []interface{}{
+					map[string]interface{}{"query": "/users/u1", "queryType": "MicrosoftGraph"},
+				},
+			})
+		},
+		listRows: 1,
+	},
+	{
+		table: "azuread_application",
+		setup: func(s *graphStandIn) {
+			s.setCollection("applications",
+				map[string]interface{}{"id": "a1", "appId": "app-1", "displayName": "App One", "signInAudience": "AzureADMyOrg"},
+				map[string]interface{}{"id": "a2", "appId": "app-2", "displayName": "App Two"},
+				map[string]interface{}{"id": "a3", "appId": "app-3", "displayName": "App Three"},
+			)
+		},
+		listRows: 3,
+		getQuals: map[string]string{"id": "a2"},
+	},
+	{
+		table: "azuread_application_app_role_assigned_to",
+		setup: func(s *graphStandIn) {
+			s.setCollection("servicePrincipals('appId=app-1')/appRoleAssignedTo",
+				appRoleAssignment("ar1", "User"),
+				appRoleAssignment("ar2", "Group"),
+			)
+		},
+		listQuals: map[string]string{"app_id": "app-1"},
+		listRows:  2,
+		getQuals:  map[string]string{"app_id": "app-1", "id": "ar1"},
+	},
+	{
+		table: "azuread_authorization_policy",
+		setup: func(s *graphStandIn) {
+			s.setObject("policies/authorizationPolicy", map[string]interface{}{
+				"id":                  "authorizationPolicy",
+				"displayName":         "Authorization Policy",
+				"allowInvitesFrom":    "everyone",
+				"allowedToUseSSPR":    true,
+				"blockMsolPowerShell": false,
+				"defaultUserRolePermissions": map[string]interface{}{
+					"allowedToCreateApps":           true,
+					"allowedToCreateSecurityGroups": true,
+				},
+			})
+		},
+		listRows: 1,
+	},
+	{
+		table: "azuread_conditional_access_named_location",
+		setup: func(s *graphStandIn) {
+			s.setCollection("identity/conditionalAccess/namedLocations",
+				map[string]interface{}{
+					"@odata.type": "#microsoft.graph.ipNamedLocation",
+					"id":          "nl1",
+					"displayName": "Office",
+					"isTrusted":   true,
+					"ipRanges": []interface{}{
+						map[string]interface{}{"@odata.type": "#microsoft.graph.iPv4CidrRange", "cidrAddress": "10.0.0.0/8"},
+					},
+				},
+				map[string]interface{}{
+					"@odata.type":                       "#microsoft.graph.countryNamedLocation",
+					"id":                                "nl2",
+					"displayName":                       "Countries",
+					"countriesAndRegions":               []interface{}{"GB", "FR"},
+					"includeUnknownCountriesAndRegions": false,
+				},
+			)
+		},
+		listRows: 2,
+		getQuals: map[string]string{"id": "nl2"},
+	},
+	{
+		table: "azuread_conditional_access_policy",
+		setup: func(s *graphStandIn) {
+			s.setCollection("identity/conditionalAccess/policies",
+				map[string]interface{}{
+					"id":          "cap1",
+					"displayName": "Require MFA",
+					"state":       "enabled",
+					"conditions": map[string]interface{}{
+						"clientAppTypes": []interface{}{"all"},
+						"applications":   map[string]interface{}{"includeApplications": []interface{}{"All"}},
+						"users":          map[string]interface{}{"includeUsers": []interface{}{"All"}},
+					},
+					"grantControls": map[string]interface{}{
+						"operator":        "OR",
+						"builtInControls": []interface{}{"mfa"},
+					},
+				},
+			)
+		},
+		listRows: 1,
+		getQuals: map[string]string{"id": "cap1"},
+	},
+	{
+		table: "azuread_device",
+		setup: func(s *graphStandIn) {
+			s.setCollection("devices",
+				map[string]interface{}{"id": "d1", "deviceId": "device-1", "displayName": "Laptop", "accountEnabled": true, "operatingSystem": "Windows"},
+				map[string]interface{}{"id": "d2", "deviceId": "device-2", "displayName": "Phone", "accountEnabled": false, "operatingSystem": "iOS"},
+			)
+		},
+		listRows: 2,
+		getQuals: map[string]string{"id": "d1"},
+	},
+	{
+		table: "azuread_directory_audit_report",
+		setup: func(s *graphStandIn) {
+			s.setCollection("auditLogs/directoryAudits",
+				map[string]interface{}{
+					"@odata.type":         "#microsoft.graph.directoryAudit",
+					"id":                  "da1",
+					"activityDateTime":    "2024-01-02T03:04:05Z",
+					"activityDisplayName": "Add user",
+					"category":            "UserManagement",
+					"correlationId":       "44444444-4444-4444-4444-444444444444",
+					"loggedByService":     "Core Directory",
+					"operationType":       "Add",
+					"result":              "success",
+					"initiatedBy": map[string]interface{}{
+						"user": map[string]interface{}{"id": "u1", "userPrincipalName": "admin@example.com"},
+					},
+					"targetResources": []interface{}{
+						map[string]interface{}{"id": "u2", "type": "User", "userPrincipalName": "new@example.com"},
+					},
+				},
+			)
+		},
+		listRows: 1,
+		getQuals: map[string]string{"id": "da1"},
+	},
+	{
+		table: "azuread_directory_role",
+		setup: func(s *graphStandIn) {
+			s.setCollection("directoryRoles",
+				map[string]interface{}{"id": "r1", "displayName": "Global Administrator", "roleTemplateId": "62e90394-69f5-4237-9190-012177145e10"},
+				map[string]interface{}{"id": "r2", "displayName": "User Administrator", "roleTemplateId": "fe930be7-5e62-47db-91af-98c3a49a38b1"},
+			)
+		},
+		listRows: 2,
+		getQuals: map[string]string{"id": "r1"},
+	},
+	{
+		table: "azuread_directory_role_assignment",
+		setup: func(s *graphStandIn) {
+			s.setCollection("roleManagement/directory/roleAssignments",
+				map[string]interface{}{"id": "ra1", "roleDefinitionId": "rd1", "principalId": "u1", "directoryScopeId": "/"},
+				map[string]interface{}{"id": "ra2", "roleDefinitionId": "rd1", "principalId": "g1", "directoryScopeId": "/administrativeUnits/au1"},
+			)
+		},
+		listRows: 2,
+		getQuals: map[string]string{"id": "ra1"},
+	},
+	{
+		table: "azuread_directory_role_assignment_schedule_instance",
+		setup: func(s *graphStandIn) {
+			s.setCollection("roleManagement/directory/roleAssignmentScheduleInstances",
+				map[string]interface{}{"id": "rai1", "roleDefinitionId": "rd1", "principalId": "u1", "directoryScopeId": "/", "assignmentType": "Activated", "startDateTime": "2024-03-01T08:00:00Z", "endDateTime": "2024-03-01T16:00:00Z"},
+				map[string]interface{}{"id": "rai2", "roleDefinitionId": "rd1", "principalId": "g1", "directoryScopeId": "/", "assignmentType": "Assigned"},
+			)
+		},
+		listRows: 2,
+		getQuals: map[string]string{"id": "rai1"},
+	},
+	{
+		table: "azuread_directory_role_assignment_schedule_request",
+		setup: func(s *graphStandIn) {
+			s.setCollection("roleManagement/directory/roleAssignmentScheduleRequests",
+				map[string]interface{}{"id": "rar1", "action": "selfActivate", "status": "Provisioned", "roleDefinitionId": "rd1", "principalId": "u1", "directoryScopeId": "/", "justification": "Incident 42"},
+			)
+		},
+		listRows: 1,
+		getQuals: map[string]string{"id": "rar1"},
+	},
+	{
+		table: "azuread_directory_role_definition",
+		setup: func(s *graphStandIn) {
+			s.setCollection("roleManagement/directory/roleDefinitions",
+				map[string]interface{}{
+					"id":          "rd1",
+					"displayName": "Global Reader",
+					"isBuiltIn":   true,
+					"isEnabled":   true,
+					"templateId":  "rd1",
+					"rolePermissions": []interface{}{
+						map[string]interface{}{"allowedResourceActions": []interface{}{"microsoft.directory/users/standard/read"}},
+					},
+				},
+				map[string]interface{}{"id": "rd2", "displayName": "Helpdesk Europe", "isBuiltIn": false, "isEnabled": true},
+				map[string]interface{}{"id": "rd3", "displayName": "Security Reader", "isBuiltIn": true, "isEnabled": true},
+			)
+		},
+		listRows: 3,
+		getQuals: map[string]string{"id": "rd2"},
+	},
+	{
+		table: "azuread_directory_role_eligibility_schedule",
+		setup: func(s *graphStandIn) {
+			s.setCollection("roleManagement/directory/roleEligibilitySchedules",
+				map[string]interface{}{
+					"id":               "res1",
+					"roleDefinitionId": "rd1",
+					"principalId":      "u1",
+					"directoryScopeId": "/",
+					"memberType":       "Direct",
+					"status":           "Provisioned",
+					"scheduleInfo": map[string]interface{}{
+						"startDateTime": "2024-01-01T00:00:00Z",
+						"expiration":    map[string]interface{}{"type": "noExpiration"},
+					},
+				},
+			)
+		},
+		listRows: 1,
+		getQuals: map[string]string{"id": "res1"},
+	},
+	{
+		table: "azuread_directory_role_eligibility_schedule_instance",
+		setup: func(s *graphStandIn) {
+			s.setCollection("roleManagement/directory/roleEligibilityScheduleInstances",
+				map[string]interface{}{"id": "rei1", "roleDefinitionId": "rd1", "principalId": "u1", "directoryScopeId": "/", "memberType": "Direct", "roleEligibilityScheduleId": "res1"},
+				map[string]interface{}{"id": "rei2", "roleDefinitionId": "rd2", "principalId": "u2", "directoryScopeId": "/", "memberType": "Group", "roleEligibilityScheduleId": "res2"},
+			)
+		},
+		listRows: 2,
+		getQuals: map[string]string{"id": "rei2"},
+	},
+	{
+		table: "azuread_directory_setting",
+		setup: func(s *graphStandIn) {
+			s.setCollection("groupSettings",
+				map[string]interface{}{
+					"id":          "s1",
+					"displayName": "Group.Unified",
+					"templateId":  "62375ab9-6b52-47ed-826b-58e47e0e304b",
+					"values": []interface{}{
+						map[string]interface{}{"name": "EnableGroupCreation", "value": "true"},
+						map[string]interface{}{"name": "AllowGuestsToAccessGroups", "value": "false"},
+					},
+				},
+			)
+		},
+		listRows: 2,
+		getQuals: map[string]string{"id": "s1", "name": "EnableGroupCreation"},
+	},
+	{
+		table: "azuread_domain",
+		setup: func(s *graphStandIn) {
+			s.setCollection("domains",
+				map[string]interface{}{"id": "example.com", "isDefault": true, "isVerified": true, "supportedServices": []interface{}{"Email"}},
+				map[string]interface{}{"id": "example.onmicrosoft.com", "isInitial": true, "isVerified": true},
+			)
+		},
+		listRows: 2,
+		getQuals: map[string]string{"id": "example.com"},
+	},
+	{
+		table: "azuread_graph_request",
+		setup: func(s *graphStandIn) {
+			s.setCollection("identity/conditionalAccess/namedLocations",
+				map[string]interface{}{"id": "l1", "displayName": "Head office"},
+				map[string]interface{}{"id": "l2", "displayName": "Branch offices"},
+			)
+		},
+		listQuals: map[string]string{"path": "identity/conditionalAccess/namedLocations"},
+		listRows:  2,
+	},
+	{
+		table: "azuread_group",
+		setup: func(s *graphStandIn) {
+			s.setCollection("groups",
+				map[string]interface{}{"id": "g1", "displayName": "Engineering", "mailEnabled": false, "securityEnabled": true, "groupTypes": []interface{}{}},
+				map[string]interface{}{"id": "g2", "displayName": "Sales", "mailEnabled": true, "securityEnabled": false, "groupTypes": []interface{}{"Unified"}},
+				map[string]interface{}{"id": "g3", "displayName": "Finance", "mailEnabled": false, "securityEnabled": true},
+			)
+		},
+		listRows: 3,
+		getQuals: map[string]string{"id": "g3"},
+	},
+	{
+		table: "azuread_group_app_role_assignment",
+		setup: func(s *graphStandIn) {
+			s.setCollection("groups/g1/appRoleAssignments", appRoleAssignment("gar1", "Group"))
+		},
+		listQuals: map[string]string{"group_id": "g1"},
+		listRows:  1,
+		getQuals:  map[string]string{"group_id": "g1", "id": "gar1"},
+	},
+	{
+		table: "azuread_group_member",
+		setup: func(s *graphStandIn) {
+			s.setCollection("groups/g1/members",
+				map[string]interface{}{"@odata.type": "#microsoft.graph.user", "id": "u1", "displayName": "Alice", "userPrincipalName": "alice@example.com"},
+				map[string]interface{}{"@odata.type": "#microsoft.graph.group", "id": "g2", "displayName": "Engineering"},
+			)
+		},
+		listQuals: map[string]string{"group_id": "g1"},
+		listRows:  2,
+	},
+	{
+		table: "azuread_group_nesting",
+		setup: func(s *graphStandIn) {
+			s.setCollection("groups", nestedGroup("g1", "All staff"), nestedGroup("g2", "Engineering"))
+			s.setCollection("groups/g1/members", nestedGroup("g2", "Engineering"))
+			s.setCollection("groups/g2/members")
+		},
+		listRows: 1,
+	},
+	{
+		table: "azuread_identity_provider",
+		setup: func(s *graphStandIn) {
+			s.setCollection("identity/identityProviders",
+				map[string]interface{}{"@odata.type": "#microsoft.graph.builtInIdentityProvider", "id": "AADSignup-OAUTH", "displayName": "Azure Active Directory Sign up", "identityProviderType": "AADSignup"},
+			)
+		},
+		listRows: 1,
+	},
+	{
+		table: "azuread_plugin_permission_check",
+		setup: func(s *graphStandIn) {
+			s.setCollection("subscribedSkus")
+		},
+		listRows: len(tableRequirements),
+	},
+	{
+		table: "azuread_security_defaults_policy",
+		setup: func(s *graphStandIn) {
+			s.setObject("policies/identitySecurityDefaultsEnforcementPolicy", map[string]interface{}{
+				"id":          "00000000-0000-0000-0000-000000000005",
+				"displayName": "Security Defaults",
+				"isEnabled":   true,
+			})
+		},
+		listRows: 1,
+	},
+	{
+		table: "azuread_service_principal",
+		setup: func(s *graphStandIn) {
+			s.setCollection("servicePrincipals",
+				map[string]interface{}{"id": "sp1", "appId": "app-1", "displayName": "App One", "accountEnabled": true, "servicePrincipalType": "Application"},
+				map[string]interface{}{"id": "sp2", "appId": "app-2", "displayName": "App Two", "accountEnabled": false, "servicePrincipalType": "ManagedIdentity"},
+			)
+		},
+		listRows: 2,
+		getQuals: map[string]string{"id": "sp2"},
+	},
+	{
+		table: "azuread_service_principal_app_role_assigned_to",
+		setup: func(s *graphStandIn) {
+			s.setCollection("servicePrincipals/sp1/appRoleAssignedTo",
+				appRoleAssignment("spar1", "User"),
+				appRoleAssignment("spar2", "User"),
+				appRoleAssignment("spar3", "ServicePrincipal"),
+			)
+		},
+		listQuals: map[string]string{"service_principal_id": "sp1"},
+		listRows:  3,
+		getQuals:  map[string]string{"service_principal_id": "sp1", "id": "spar3"},
+	},
+	{
+		table: "azuread_service_principal_app_role_assignment",
+		setup: func(s *graphStandIn) {
+			s.setCollection("servicePrincipals/sp1/appRoleAssignments", appRoleAssignment("spa1", "ServicePrincipal"))
+		},
+		listQuals: map[string]string{"service_principal_id": "sp1"},
+		listRows:  1,
+		getQuals:  map[string]string{"service_principal_id": "sp1", "id": "spa1"},
+	},
+	{
+		table: "azuread_sign_in_report",
+		setup: func(s *graphStandIn) {
+			s.setCollection("auditLogs/signIns",
+				map[string]interface{}{
+					"@odata.type":       "#microsoft.graph.signIn",
+					"id":                "si1",
+					"createdDateTime":   "2024-01-02T03:04:05Z",
+					"userPrincipalName": "user@example.com",
+					"appDisplayName":    "Azure Portal",
+					"ipAddress":         "192.0.2.1",
+					"status":            map[string]interface{}{"errorCode": 0},
+					"location":          map[string]interface{}{"city": "London", "countryOrRegion": "GB"},
+				},
+				map[string]interface{}{
+					"@odata.type":       "#microsoft.graph.signIn",
+					"id":                "si2",
+					"createdDateTime":   "2024-01-02T03:05:05Z",
+					"userPrincipalName": "user@example.com",
+					"riskEventTypes":    []interface{}{"unfamiliarFeatures"},
+				},
+			)
+		},
+		listRows: 2,
+		getQuals: map[string]string{"id": "si2"},
+	},
+	{
+		table: "azuread_user",
+		setup: func(s *graphStandIn) {
+			s.setCollection("users",
+				map[string]interface{}{"id": "u1", "displayName": "Ada Lovelace", "userPrincipalName": "ada@example.com", "accountEnabled": true, "userType": "Member"},
+				map[string]interface{}{"id": "u2", "displayName": "Alan Turing", "userPrincipalName": "alan@example.com", "accountEnabled": false, "userType": "Member"},
+				map[string]interface{}{"id": "u3", "userPrincipalName": "guest@example.com", "userType": "Guest"},
+			)
+		},
+		listRows: 3,
+		getQuals: map[string]string{"id": "u1"},
+	},
+	{
+		table: "azuread_user_app_role_assignment",
+		setup: func(s *graphStandIn) {
+			s.setCollection("users/u1/appRoleAssignments", appRoleAssignment("uar1", "User"))
+		},
+		listQuals: map[string]string{"user_id": "u1"},
+		listRows:  1,
+		getQuals:  map[string]string{"user_id": "u1", "id": "uar1"},
+	},
+	{
+		table: "azuread_user_transitive_member_of",
+		setup: func(s *graphStandIn) {
+			s.setCollection("users/u1/transitiveMemberOf",
+				map[string]interface{}{"@odata.type": "#microsoft.graph.group", "id": "g1", "displayName": "Engineering"},
+				map[string]interface{}{"@odata.type": "#microsoft.graph.directoryRole", "id": "r1", "displayName": "Global Reader"},
+			)
+			s.setCollection("users/u1/memberOf",
+				map[string]interface{}{"@odata.type": "#microsoft.graph.group", "id": "g1", "displayName": "Engineering"},
+				map[string]interface{}{"@odata.type": "#microsoft.graph.directoryRole", "id": "r1", "displayName": "Global Reader"},
+			)
+		},
+		listQuals: map[string]string{"user_id": "u1"},
+		listRows:  2,
+	},
+}
+
+// testTableColumns returns the columns of a table which do not need an extra
+// per-row hydrate call.
+func testTableColumns(t *testing.T, table string) []string {
+	t.Helper()
+
+	tbl, ok := Plugin(context.Background()).TableMap[table]
+	if !ok {
+		t.Fatalf("table %s not found in plugin", table)
+	}
+
+	var columns []string
+	for _, c := range tbl.Columns {
+		if c.Hydrate == nil || c.Name == "tenant_id" {
+			columns = append(columns, c.Name)
+		}
+	}
+	return columns
+}
+
+func TestTablesCovered(t *testing.T) {
+	tested := map[string]bool{}
+	for _, tc := range tableTestCases {
+		tested[tc.table] = true
+	}
+
+	for table := range Plugin(context.Background()).TableMap {
+		if !tested[table] {
+			t.Errorf("table %s has no test case", table)
+		}
+	}
+}
+
+func TestTableList(t *testing.T) {
+	for _, tc := range tableTestCases {
+		t.Run(tc.table, func(t *testing.T) {
+			standIn.reset()
+			tc.setup(standIn)
+
+			rows, err := executeQuery(t, tc.table, testTableColumns(t, tc.table), equalsQuals(tc.listQuals))
+			if err != nil {
+				t.Fatalf("list failed: %v", err)
+			}
+			if len(rows) != tc.listRows {
+				t.Fatalf("expected %d rows, got %d", tc.listRows, len(rows))
+			}
+			for _, row := range rows {
+				if _, ok := row["tenant_id"]; !ok {
+					continue
+				}
+				if got := row["tenant_id"].GetStringValue(); got != testTenantID {
+					t.Errorf("expected tenant_id %q, got %q", testTenantID, got)
+				}
+			}
+		})
+	}
+}
+
+func TestTableGet(t *testing.T) {
+	for _, tc := range tableTestCases {
+		if tc.getQuals == nil {
+			continue
+		}
+		t.Run(tc.table, func(t *testing.T) {
+			standIn.reset()
+			tc.setup(standIn)
+
+			rows, err := executeQuery(t, tc.table, testTableColumns(t, tc.table), equalsQuals(tc.getQuals))
+			if err != nil {
+				t.Fatalf("get failed: %v", err)
+			}
+			if len(rows) != 1 {
+				t.Fatalf("expected 1 row, got %d", len(rows))
+			}
+			if id, ok := tc.getQuals["id"]; ok {
+				if got := rows[0]["id"].GetStringValue(); got != id {
+					t.Errorf("expected id %q, got %q", id, got)
+				}
+			}
+		})
+	}
+}
+
+func TestTableGetNotFound(t *testing.T) {
+	standIn.reset()
+
+	rows, err := executeQuery(t, "azuread_user", []string{"id", "display_name"}, equalsQuals(map[string]string{"id": "missing"}))
+	if err != nil {
+		t.Fatalf("expected the not found error to be ignored, got %v", err)
+	}
+	if len(rows) != 0 {
+		t.Fatalf("expected no rows, got %d", len(rows))
+	}
+}
+
+func TestTableListODataError(t *testing.T) {
+	standIn.reset()
+	standIn.setError("groups", 403, "Authorization_RequestDenied", "Insufficient privileges to complete the operation.")
+
+	_, err := executeQuery(t, "azuread_group", []string{"id"}, nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"missing permission", "Authorization_RequestDenied", "Insufficient privileges", "Group.Read.All"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got %q", want, err.Error())
+		}
+	}
+}
+
+func TestTableListUnsupportedQuery(t *testing.T) {
+	standIn.reset()
+
+	// An invalid filter clause returns no rows, as it did before filters were pushed down
+	standIn.setError("groups", 400, "BadRequest", "Invalid filter clause")
+	rows, err := executeQuery(t, "azuread_group", []string{"id"}, nil)
+	if err != nil || len(rows) != 0 {
+		t.Fatalf("expected no rows and no error, got %d rows and %v", len(rows), err)
+	}
+
+	// A query Graph does not support fails, rather than silently returning no rows
+	standIn.setError("groups", 400, "Request_UnsupportedQuery", "Unsupported Query.")
+	_, err = executeQuery(t, "azuread_group", []string{"id"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "unsupported query") {
+		t.Fatalf("expected an unsupported query error, got %v", err)
+	}
+}
+
+func TestTableListPaging(t *testing.T) {
+	standIn.reset()
+	standIn.setPageSize(2)
+	var users []map[string]interface{}
+	for _, id := range []string{"u1", "u2", "u3", "u4", "u5"} {
+		users = append(users, map[string]interface{}{"id": id, "userPrincipalName": id + "@example.com"})
+	}
+	standIn.setCollection("users", users...)
+
+	rows, err := executeQuery(t, "azuread_user", []string{"id"}, nil)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("expected 5 rows, got %d", len(rows))
+	}
+	if got := len(standIn.requestsFor("users")); got != 3 {
+		t.Errorf("expected 3 page requests, got %d", got)
+	}
+}
+
+func TestTableListRetriesThrottledRequests(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("users", map[string]interface{}{"id": "u1"})
+	standIn.setThrottled("users", 2, "0")
+
+	rows, err := executeQuery(t, "azuread_user", []string{"id"}, nil)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, got %d", len(rows))
+	}
+	if got := len(standIn.requestsFor("users")); got != 3 {
+		t.Errorf("expected 3 requests, got %d", got)
+	}
+}
+
+func TestGroupMemberAndOwnerIds(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("groups", map[string]interface{}{"id": "g1", "displayName": "Engineering"})
+	standIn.setCollection("groups/g1/members",
+		map[string]interface{}{"@odata.type": "#microsoft.graph.user", "id": "u1"},
+		map[string]interface{}{"@odata.type": "#microsoft.graph.user", "id": "u2"},
+		map[string]interface{}{"@odata.type": "#microsoft.graph.group", "id": "g2"},
+	)
+	standIn.setCollection("groups/g1/owners", map[string]interface{}{"@odata.type": "#microsoft.graph.user", "id": "u1"})
+
+	rows, err := executeQuery(t, "azuread_group", []string{"id", "member_ids", "owner_ids"}, nil)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, got %d", len(rows))
+	}
+	if got := string(rows[0]["member_ids"].GetJsonValue()); got != `["u1","u2","g2"]` {
+		t.Errorf("unexpected member_ids %s", got)
+	}
+	if got := string(rows[0]["owner_ids"].GetJsonValue()); got != `["u1"]` {
+		t.Errorf("unexpected owner_ids %s", got)
+	}
+}
+
+func TestUserListQueryParameters(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("users")
+
+	quals := map[string]*proto.Quals{
+		"user_principal_name": {Quals: []*proto.Qual{stringQual("user_principal_name", "=", "ada@example.com")}},
+		"account_enabled":     {Quals: []*proto.Qual{boolQual("account_enabled", "<>", false)}},
+	}
+	if _, err := executeQuery(t, "azuread_user", []string{"id", "display_name", "title"}, quals); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+
+	requests := standIn.requestsFor("users")
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+	query := requests[0].URL.Query()
+
+	filter := query.Get("$filter")
+	for _, want := range []string{"userPrincipalName eq 'ada@example.com'", "accountEnabled eq true"} {
+		if !strings.Contains(filter, want) {
+			t.Errorf("expected $filter to contain %q, got %q", want, filter)
+		}
+	}
+	if got := query.Get("$select"); got != "id,displayName,userPrincipalName" {
+		t.Errorf("unexpected $select %q", got)
+	}
+}
+
+func TestDirectoryRoleAssignmentScheduleRequestSelect(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("roleManagement/directory/roleAssignmentScheduleRequests")
+
+	// Who activated which role, and why
+	columns := []string{"id", "action", "principal_id", "created_by", "justification", "ticket_info"}
+	if _, err := executeQuery(t, "azuread_directory_role_assignment_schedule_request", columns, nil); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+
+	requests := standIn.requestsFor("roleManagement/directory/roleAssignmentScheduleRequests")
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+	if got := requests[0].URL.Query().Get("$select"); got != "id,action,principalId,createdBy,justification,ticketInfo" {
+		t.Errorf("unexpected $select %q", got)
+	}
+}
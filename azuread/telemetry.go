@@ -0,0 +1,273 @@
+package azuread
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	khttp "github.com/microsoft/kiota-http-go"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+/*
+Telemetry of the Graph requests of the plugin.
+
+Spans and metrics are recorded through the global OpenTelemetry providers,
+which the plugin SDK exports over OTLP when STEAMPIPE_OTEL_LEVEL is set, see
+https://steampipe.io/docs/reference/env-vars/steampipe_otel_level. For offline
+use, STEAMPIPE_AZUREAD_OTEL_FILE writes them as JSON lines to a file, or to
+stdout, instead.
+*/
+
+const (
+	telemetryScope   = "github.com/turbot/steampipe-plugin-azuread"
+	envTelemetryFile = "STEAMPIPE_AZUREAD_OTEL_FILE"
+)
+
+// Attributes of the Graph request spans and metrics.
+const (
+	attributeTable      = attribute.Key("azuread.table")
+	attributeHydrate    = attribute.Key("azuread.hydrate")
+	attributePage       = attribute.Key("azuread.graph.page")
+	attributeRetryCount = attribute.Key("azuread.graph.retry_count")
+	attributeBatched    = attribute.Key("azuread.graph.batched")
+	attributeMethod     = attribute.Key("http.request.method")
+	attributeStatusCode = attribute.Key("http.response.status_code")
+	attributeUrlPath    = attribute.Key("url.path")
+)
+
+// graphTelemetry holds the tracer and the metric instruments of the Graph requests.
+type graphTelemetry struct {
+	tracer          trace.Tracer
+	requests        metric.Int64Counter
+	requestDuration metric.Float64Histogram
+	throttled       metric.Int64Counter
+	retryDelay      metric.Float64Histogram
+
+	// shutdown flushes and closes the file exporters, if used
+	shutdown func(context.Context) error
+}
+
+var (
+	graphTelemetryOnce     sync.Once
+	graphTelemetryInstance *graphTelemetry
+)
+
+// getGraphTelemetry returns the telemetry of the plugin process, which is
+// created on first use, after the plugin SDK has set the global providers.
+func getGraphTelemetry(ctx context.Context) *graphTelemetry {
+	graphTelemetryOnce.Do(func() {
+		var tracerProvider trace.TracerProvider = otel.GetTracerProvider()
+		var meterProvider metric.MeterProvider = otel.GetMeterProvider()
+		var shutdown func(context.Context) error
+
+		if path := os.Getenv(envTelemetryFile); path != "" {
+			fileTracerProvider, fileMeterProvider, err := newTelemetryFileProviders(ctx, path)
+			if err != nil {
+				plugin.Logger(ctx).Error("getGraphTelemetry", "telemetry_file_error", err, "path", path)
+			} else {
+				tracerProvider, meterProvider = fileTracerProvider, fileMeterProvider
+				shutdown = func(ctx context.Context) error {
+					return errors.Join(fileTracerProvider.Shutdown(ctx), fileMeterProvider.Shutdown(ctx))
+				}
+			}
+		}
+
+		telemetry, err := newGraphTelemetry(tracerProvider, meterProvider)
+		if err != nil {
+			plugin.Logger(ctx).Error("getGraphTelemetry", "instrument_error", err)
+		}
+		telemetry.shutdown = shutdown
+		graphTelemetryInstance = telemetry
+	})
+	return graphTelemetryInstance
+}
+
+func newGraphTelemetry(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) (*graphTelemetry, error) {
+	meter := meterProvider.Meter(telemetryScope)
+
+	// The instruments are usable even if invalid, they then record nothing
+	requests, requestsErr := meter.Int64Counter("azuread.graph.requests",
+		metric.WithDescription("The number of HTTP requests sent to Microsoft Graph, including retries of throttled requests."),
+		metric.WithUnit("{request}"))
+	requestDuration, requestDurationErr := meter.Float64Histogram("azuread.graph.request.duration",
+		metric.WithDescription("The duration of the requests sent to Microsoft Graph, including the time spent waiting to retry them."),
+		metric.WithUnit("s"))
+	throttled, throttledErr := meter.Int64Counter("azuread.graph.throttled",
+		metric.WithDescription("The number of requests throttled by Microsoft Graph, i.e. with status code 429."),
+		metric.WithUnit("{response}"))
+	retryDelay, retryDelayErr := meter.Float64Histogram("azuread.graph.retry.delay",
+		metric.WithDescription("The time waited before retrying a throttled or unavailable request."),
+		metric.WithUnit("s"))
+
+	return &graphTelemetry{
+		tracer:          tracerProvider.Tracer(telemetryScope),
+		requests:        requests,
+		requestDuration: requestDuration,
+		throttled:       throttled,
+		retryDelay:      retryDelay,
+	}, errors.Join(requestsErr, requestDurationErr, throttledErr, retryDelayErr)
+}
+
+// ShutdownTelemetry flushes the telemetry written to STEAMPIPE_AZUREAD_OTEL_FILE.
+// It is called when the plugin exits, the OTLP exporters are shut down by the plugin SDK.
+// It is not called if the plugin is killed, see telemetryFileMetricInterval.
+func ShutdownTelemetry() {
+	if graphTelemetryInstance == nil || graphTelemetryInstance.shutdown == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_ = graphTelemetryInstance.shutdown(ctx)
+}
+
+// recordRetry records a throttled or unavailable response which is retried after the delay.
+func (t *graphTelemetry) recordRetry(ctx context.Context, statusCode int, attempt int, delay time.Duration) {
+	attributes := append(graphRequestTelemetryFrom(ctx).attributes(), attributeStatusCode.Int(statusCode))
+	if statusCode == http.StatusTooManyRequests {
+		t.throttled.Add(ctx, 1, metric.WithAttributes(attributes...))
+	}
+	t.retryDelay.Record(ctx, delay.Seconds(), metric.WithAttributes(attributes...))
+
+	span, _ := ctx.Value(graphRequestSpanKey{}).(trace.Span)
+	if span == nil {
+		return
+	}
+	span.AddEvent("retry", trace.WithAttributes(
+		attributeStatusCode.Int(statusCode),
+		attributeRetryCount.Int(attempt),
+		attribute.Float64("azuread.graph.retry.delay", delay.Seconds()),
+	))
+}
+
+type graphRequestTelemetryKey struct{}
+
+// graphRequestSpanKey is the context key of the span of a Graph request. The
+// span of the context may be a child span of the Graph client middlewares.
+type graphRequestSpanKey struct{}
+
+// graphRequestTelemetry identifies the Graph requests sent by a hydrate call
+// in their spans and metrics.
+type graphRequestTelemetry struct {
+	table   string
+	hydrate string
+	// requests is the number of requests sent so far, so for a list it is the
+	// number of the last page fetched
+	requests atomic.Int64
+}
+
+// withGraphTelemetry returns a context which attributes the Graph requests
+// sent with it to the table of the query and the given hydrate function. It is
+// called at the start of each hydrate function with its own name, so the
+// requests of the helpers it calls are attributed to it.
+func withGraphTelemetry(ctx context.Context, d *plugin.QueryData, hydrate string) context.Context {
+	info := &graphRequestTelemetry{hydrate: hydrate}
+	if d != nil && d.Table != nil {
+		info.table = d.Table.Name
+	}
+	return context.WithValue(ctx, graphRequestTelemetryKey{}, info)
+}
+
+// withoutGraphTelemetry returns a context whose Graph requests are not
+// attributed to a hydrate call, e.g. a batch of requests of several calls.
+func withoutGraphTelemetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, graphRequestTelemetryKey{}, (*graphRequestTelemetry)(nil))
+}
+
+func graphRequestTelemetryFrom(ctx context.Context) *graphRequestTelemetry {
+	info, _ := ctx.Value(graphRequestTelemetryKey{}).(*graphRequestTelemetry)
+	return info
+}
+
+func (i *graphRequestTelemetry) attributes() []attribute.KeyValue {
+	if i == nil {
+		return nil
+	}
+	return []attribute.KeyValue{attributeTable.String(i.table), attributeHydrate.String(i.hydrate)}
+}
+
+// nextPage counts a request of the hydrate call and returns its number.
+func (i *graphRequestTelemetry) nextPage() int64 {
+	if i == nil {
+		return 1
+	}
+	return i.requests.Add(1)
+}
+
+// startRequestSpan starts the span of a Graph request.
+func (t *graphTelemetry) startRequestSpan(ctx context.Context, method string, path string, batched bool) (context.Context, trace.Span) {
+	info := graphRequestTelemetryFrom(ctx)
+	attributes := append(info.attributes(),
+		attributeMethod.String(method),
+		attributeUrlPath.String(path),
+		attributePage.Int64(info.nextPage()),
+	)
+	if batched {
+		attributes = append(attributes, attributeBatched.Bool(true))
+	}
+	ctx, span := t.tracer.Start(ctx, "azuread.graph.request", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+	return context.WithValue(ctx, graphRequestSpanKey{}, span), span
+}
+
+// endRequestSpan ends the span of a Graph request with its outcome.
+func endRequestSpan(span trace.Span, statusCode int, retries int, err error) {
+	if statusCode > 0 {
+		span.SetAttributes(attributeStatusCode.Int(statusCode))
+	}
+	span.SetAttributes(attributeRetryCount.Int(retries))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if statusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(statusCode))
+	}
+	span.End()
+}
+
+// telemetryHandler is a middleware for the Graph HTTP client which records a
+// span and metrics for each request. It comes before the retryHandler, so a
+// span covers the retries of its request, which are recorded as span events.
+type telemetryHandler struct{}
+
+func newTelemetryHandler() *telemetryHandler {
+	return &telemetryHandler{}
+}
+
+// Intercept implements the khttp.Middleware interface.
+func (h *telemetryHandler) Intercept(pipeline khttp.Pipeline, middlewareIndex int, req *http.Request) (*http.Response, error) {
+	telemetry := getGraphTelemetry(req.Context())
+
+	ctx, span := telemetry.startRequestSpan(req.Context(), req.Method, req.URL.Path, false)
+	req = req.WithContext(ctx)
+
+	start := time.Now()
+	resp, err := pipeline.Next(req, middlewareIndex)
+	duration := time.Since(start)
+
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+	// Set by the retryHandler on the request it sends again
+	retries, _ := strconv.Atoi(req.Header.Get("Retry-Attempt"))
+	endRequestSpan(span, statusCode, retries, err)
+
+	attributes := metric.WithAttributes(append(graphRequestTelemetryFrom(ctx).attributes(),
+		attributeMethod.String(req.Method),
+		attributeStatusCode.Int(statusCode),
+	)...)
+	telemetry.requests.Add(ctx, int64(retries+1), attributes)
+	telemetry.requestDuration.Record(ctx, duration.Seconds(), attributes)
+
+	return resp, err
+}
@@ -0,0 +1,200 @@
+package azuread
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+// telemetryFileStdout is the STEAMPIPE_AZUREAD_OTEL_FILE which writes to stdout.
+const telemetryFileStdout = "stdout"
+
+// telemetryFileMetricInterval is how often metrics are written. Steampipe may
+// kill the plugin without letting it exit, so metrics recorded since the last
+// write would be lost with the default interval of a minute.
+const telemetryFileMetricInterval = 10 * time.Second
+
+// newTelemetryFileProviders returns tracer and meter providers which write
+// the spans and metrics of the plugin to a file, or stdout, as JSON lines.
+// Spans are written as they end, and metrics every telemetryFileMetricInterval
+// and on shutdown.
+func newTelemetryFileProviders(ctx context.Context, path string) (*sdktrace.TracerProvider, *sdkmetric.MeterProvider, error) {
+	var out io.WriteCloser = nopWriteCloser{os.Stdout}
+	if path != telemetryFileStdout {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, nil, err
+		}
+		out = file
+	}
+	writer := newTelemetryFileWriter(out)
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithProcess(),
+		resource.WithAttributes(semconv.ServiceNameKey.String(pluginName)),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithResource(res),
+		sdktrace.WithSyncer(&telemetryFileSpanExporter{writer: writer}),
+	)
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(&telemetryFileMetricExporter{writer: writer},
+			sdkmetric.WithInterval(telemetryFileMetricInterval),
+		)),
+	)
+	return tracerProvider, meterProvider, nil
+}
+
+// telemetryFileWriter writes JSON lines, shared by the span and metric
+// exporters, and is closed when both of them are shut down.
+type telemetryFileWriter struct {
+	mu      sync.Mutex
+	out     io.WriteCloser
+	encoder *json.Encoder
+	users   int
+}
+
+func newTelemetryFileWriter(out io.WriteCloser) *telemetryFileWriter {
+	return &telemetryFileWriter{out: out, encoder: json.NewEncoder(out), users: 2}
+}
+
+func (w *telemetryFileWriter) write(v interface{}) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.encoder.Encode(v)
+}
+
+func (w *telemetryFileWriter) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.users--
+	if w.users > 0 {
+		return nil
+	}
+	return w.out.Close()
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// telemetryFileSpan is a span as written to the telemetry file.
+type telemetryFileSpan struct {
+	Type          string                 `json:"type"`
+	Name          string                 `json:"name"`
+	TraceId       string                 `json:"trace_id"`
+	SpanId        string                 `json:"span_id"`
+	ParentSpanId  string                 `json:"parent_span_id,omitempty"`
+	StartTime     time.Time              `json:"start_time"`
+	EndTime       time.Time              `json:"end_time"`
+	Status        string                 `json:"status"`
+	StatusMessage string                 `json:"status_message,omitempty"`
+	Attributes    map[string]interface{} `json:"attributes"`
+	Events        []telemetryFileEvent   `json:"events,omitempty"`
+}
+
+type telemetryFileEvent struct {
+	Name       string                 `json:"name"`
+	Time       time.Time              `json:"time"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// telemetryFileSpanExporter is a sdktrace.SpanExporter writing to a telemetryFileWriter.
+type telemetryFileSpanExporter struct {
+	writer *telemetryFileWriter
+}
+
+func (e *telemetryFileSpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	for _, span := range spans {
+		record := telemetryFileSpan{
+			Type:          "span",
+			Name:          span.Name(),
+			TraceId:       span.SpanContext().TraceID().String(),
+			SpanId:        span.SpanContext().SpanID().String(),
+			StartTime:     span.StartTime(),
+			EndTime:       span.EndTime(),
+			Status:        span.Status().Code.String(),
+			StatusMessage: span.Status().Description,
+			Attributes:    telemetryFileAttributes(span.Attributes()),
+		}
+		if span.Parent().IsValid() {
+			record.ParentSpanId = span.Parent().SpanID().String()
+		}
+		for _, event := range span.Events() {
+			record.Events = append(record.Events, telemetryFileEvent{
+				Name:       event.Name,
+				Time:       event.Time,
+				Attributes: telemetryFileAttributes(event.Attributes),
+			})
+		}
+		if err := e.writer.write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *telemetryFileSpanExporter) Shutdown(context.Context) error {
+	return e.writer.close()
+}
+
+// telemetryFileMetrics are the metrics of an export as written to the telemetry file.
+type telemetryFileMetrics struct {
+	Type    string                      `json:"type"`
+	Time    time.Time                   `json:"time"`
+	Metrics *metricdata.ResourceMetrics `json:"metrics"`
+}
+
+// telemetryFileMetricExporter is a sdkmetric.Exporter writing to a telemetryFileWriter.
+type telemetryFileMetricExporter struct {
+	writer *telemetryFileWriter
+}
+
+func (e *telemetryFileMetricExporter) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	return sdkmetric.DefaultTemporalitySelector(kind)
+}
+
+func (e *telemetryFileMetricExporter) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(kind)
+}
+
+func (e *telemetryFileMetricExporter) Export(ctx context.Context, metrics *metricdata.ResourceMetrics) error {
+	if len(metrics.ScopeMetrics) == 0 {
+		return nil
+	}
+	return e.writer.write(telemetryFileMetrics{Type: "metrics", Time: time.Now().UTC(), Metrics: metrics})
+}
+
+func (e *telemetryFileMetricExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+func (e *telemetryFileMetricExporter) Shutdown(context.Context) error {
+	return e.writer.close()
+}
+
+func telemetryFileAttributes(attributes []attribute.KeyValue) map[string]interface{} {
+	values := make(map[string]interface{}, len(attributes))
+	for _, a := range attributes {
+		values[string(a.Key)] = a.Value.AsInterface()
+	}
+	return values
+}
@@ -0,0 +1,202 @@
+package azuread
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// The global providers are set before the first Graph request of the tests,
+// which creates the telemetry of the plugin.
+var (
+	testSpanExporter = tracetest.NewInMemoryExporter()
+	testMetricReader = sdkmetric.NewManualReader()
+)
+
+func init() {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(testSpanExporter)))
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(testMetricReader)))
+}
+
+// graphRequestSpans returns the recorded Graph request spans of a hydrate function.
+func graphRequestSpans(hydrate string) []tracetest.SpanStub {
+	var spans []tracetest.SpanStub
+	for _, span := range testSpanExporter.GetSpans() {
+		if span.Name != "azuread.graph.request" {
+			continue
+		}
+		if spanAttribute(span.Attributes, attributeHydrate).AsString() == hydrate {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+func spanAttribute(attributes []attribute.KeyValue, key attribute.Key) attribute.Value {
+	for _, a := range attributes {
+		if a.Key == key {
+			return a.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+// metricSum returns the sum of the data points of a counter with the attribute.
+func metricSum(t *testing.T, name string, key attribute.Key, value string) int64 {
+	t.Helper()
+	rm := metricdata.ResourceMetrics{}
+	if err := testMetricReader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect failed: %v", err)
+	}
+	var sum int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				if v, ok := dp.Attributes.Value(key); ok && v.AsString() == value {
+					sum += dp.Value
+				}
+			}
+		}
+	}
+	return sum
+}
+
+func TestGraphRequestTelemetry(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("domains",
+		map[string]interface{}{"id": "example.com"},
+		map[string]interface{}{"id": "example.net"},
+		map[string]interface{}{"id": "example.org"},
+	)
+	standIn.setThrottled("domains", 1, "0")
+	testSpanExporter.Reset()
+	throttled := metricSum(t, "azuread.graph.throttled", attributeHydrate, "listAdDomains")
+
+	if _, err := executeQuery(t, "azuread_domain", []string{"id"}, nil); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+
+	// A span for each page, the first of which was retried once
+	spans := graphRequestSpans("listAdDomains")
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 request spans, got %d", len(spans))
+	}
+	for i, span := range spans {
+		if got := spanAttribute(span.Attributes, attributeTable).AsString(); got != "azuread_domain" {
+			t.Errorf("span %d: unexpected table %q", i, got)
+		}
+		if got := spanAttribute(span.Attributes, attributePage).AsInt64(); got != int64(i+1) {
+			t.Errorf("span %d: unexpected page %d", i, got)
+		}
+		if got := spanAttribute(span.Attributes, attributeStatusCode).AsInt64(); got != 200 {
+			t.Errorf("span %d: unexpected status code %d", i, got)
+		}
+	}
+	if got := spanAttribute(spans[0].Attributes, attributeRetryCount).AsInt64(); got != 1 {
+		t.Errorf("expected 1 retry, got %d", got)
+	}
+	if len(spans[0].Events) != 1 || spans[0].Events[0].Name != "retry" {
+		t.Errorf("expected a retry event, got %v", spans[0].Events)
+	}
+
+	if got := metricSum(t, "azuread.graph.throttled", attributeHydrate, "listAdDomains") - throttled; got != 1 {
+		t.Errorf("expected 1 throttled request, got %d", got)
+	}
+	if got := metricSum(t, "azuread.graph.requests", attributeTable, "azuread_domain"); got < 3 {
+		t.Errorf("expected at least 3 requests, got %d", got)
+	}
+}
+
+func TestBatchedRequestTelemetry(t *testing.T) {
+	standIn.reset()
+	standIn.setCollection("groups", map[string]interface{}{"id": "g1"})
+	standIn.setError("groups/g1/members", 403, "Authorization_RequestDenied", "Insufficient privileges to complete the operation.")
+	testSpanExporter.Reset()
+
+	if _, err := executeQuery(t, "azuread_group", []string{"id", "member_ids"}, nil); err == nil {
+		t.Fatalf("expected the members request to fail")
+	}
+
+	spans := graphRequestSpans("getAdGroupMembers")
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 request span, got %d", len(spans))
+	}
+	if !spanAttribute(spans[0].Attributes, attributeBatched).AsBool() {
+		t.Errorf("expected a batched request, got %v", spans[0].Attributes)
+	}
+	if got := spanAttribute(spans[0].Attributes, attributeStatusCode).AsInt64(); got != 403 || spans[0].Status.Code != codes.Error {
+		t.Errorf("expected a failed request, got status code %d and %v", got, spans[0].Status)
+	}
+
+	// The batch is not attributed to the hydrate call of one of its requests
+	for _, span := range testSpanExporter.GetSpans() {
+		if spanAttribute(span.Attributes, attributeUrlPath).AsString() == "/v1.0/$batch" && spanAttribute(span.Attributes, attributeHydrate).AsString() != "" {
+			t.Errorf("unexpected hydrate of the batch %v", span.Attributes)
+		}
+	}
+}
+
+func TestTelemetryFile(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "telemetry.jsonl")
+
+	tracerProvider, meterProvider, err := newTelemetryFileProviders(ctx, path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	telemetry, err := newGraphTelemetry(tracerProvider, meterProvider)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	ctx = withGraphTelemetry(ctx, nil, "listAdUsers")
+	_, span := telemetry.startRequestSpan(ctx, "GET", "/v1.0/users", false)
+	endRequestSpan(span, 200, 0, nil)
+	telemetry.requests.Add(ctx, 1)
+
+	if err := tracerProvider.Shutdown(ctx); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := meterProvider.Shutdown(ctx); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer file.Close()
+
+	types := map[string]int{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		record := map[string]interface{}{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid line %s: %v", scanner.Text(), err)
+		}
+		types[record["type"].(string)]++
+		if record["type"] == "span" {
+			attributes := record["attributes"].(map[string]interface{})
+			if record["name"] != "azuread.graph.request" || attributes["azuread.hydrate"] != "listAdUsers" || attributes["http.response.status_code"] != float64(200) {
+				t.Errorf("unexpected span %s", scanner.Text())
+			}
+		}
+	}
+	if types["span"] != 1 || types["metrics"] != 1 {
+		t.Errorf("expected a span and metrics, got %v", types)
+	}
+}
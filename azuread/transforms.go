@@ -1,6 +1,9 @@
 package azuread
 
 import (
+	"strings"
+	"time"
+
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 )
 
@@ -38,6 +41,30 @@ type ADDirectoryAuditReportInfo struct {
 	models.DirectoryAuditable
 }
 
+type ADDirectoryRoleAssignmentInfo struct {
+	models.UnifiedRoleAssignmentable
+}
+
+type ADDirectoryRoleAssignmentScheduleInstanceInfo struct {
+	models.UnifiedRoleAssignmentScheduleInstanceable
+}
+
+type ADDirectoryRoleAssignmentScheduleRequestInfo struct {
+	models.UnifiedRoleAssignmentScheduleRequestable
+}
+
+type ADDirectoryRoleDefinitionInfo struct {
+	models.UnifiedRoleDefinitionable
+}
+
+type ADDirectoryRoleEligibilityScheduleInfo struct {
+	models.UnifiedRoleEligibilityScheduleable
+}
+
+type ADDirectoryRoleEligibilityScheduleInstanceInfo struct {
+	models.UnifiedRoleEligibilityScheduleInstanceable
+}
+
 type ADDirectorySettingInfo struct {
 	// models.GroupSettingable
 	DisplayName *string
@@ -51,10 +78,36 @@ type ADGroupInfo struct {
 	models.Groupable
 	ResourceBehaviorOptions     []string
 	ResourceProvisioningOptions []string
+	// LastChangeTime is set for groups listed from the delta sync store
+	LastChangeTime *time.Time
+}
+
+type ADGroupMemberInfo struct {
+	models.DirectoryObjectable
+	GroupId    string
+	Transitive bool
+}
+
+type ADUserTransitiveMemberOfInfo struct {
+	models.DirectoryObjectable
+	UserId string
+	Path   []map[string]interface{}
+}
+
+type ADGroupNestingEdge struct {
+	RootGroupId            string
+	RootGroupDisplayName   *string
+	ParentGroupId          string
+	ParentGroupDisplayName *string
+	ChildGroupId           string
+	ChildGroupDisplayName  *string
+	Depth                  int
+	IsCycle                bool
+	MaxDepth               int
 }
 
 type ADIdentityProviderInfo struct {
-	models.BuiltInIdentityProvider
+	models.BuiltInIdentityProviderable
 	ClientId     interface{}
 	ClientSecret interface{}
 }
@@ -78,6 +131,8 @@ type ADSecurityDefaultsPolicyInfo struct {
 
 type ADServicePrincipalInfo struct {
 	models.ServicePrincipalable
+	// LastChangeTime is set for service principals listed from the delta sync store
+	LastChangeTime *time.Time
 }
 
 type ADSignInReportInfo struct {
@@ -87,6 +142,8 @@ type ADSignInReportInfo struct {
 type ADUserInfo struct {
 	models.Userable
 	RefreshTokensValidFromDateTime interface{}
+	// LastChangeTime is set for users listed from the delta sync store
+	LastChangeTime *time.Time
 }
 
 type ADUserAppRoleAssignmentInfo struct {
@@ -700,6 +757,146 @@ func (directoryAuditReport *ADDirectoryAuditReportInfo) DirectoryAuditTargetReso
 	return targetResources
 }
 
+// RoleAssignmentPrincipalType returns the type of the expanded principal of a role assignment, e.g. user for #microsoft.graph.user.
+func (roleDefinition *ADDirectoryRoleDefinitionInfo) RoleDefinitionRolePermissions() []map[string]interface{} {
+	if roleDefinition.GetRolePermissions() == nil {
+		return nil
+	}
+
+	rolePermissions := []map[string]interface{}{}
+	for _, p := range roleDefinition.GetRolePermissions() {
+		rolePermissionData := map[string]interface{}{
+			"allowedResourceActions": p.GetAllowedResourceActions(),
+		}
+		if p.GetExcludedResourceActions() != nil {
+			rolePermissionData["excludedResourceActions"] = p.GetExcludedResourceActions()
+		}
+		if p.GetCondition() != nil {
+			rolePermissionData["condition"] = *p.GetCondition()
+		}
+		rolePermissions = append(rolePermissions, rolePermissionData)
+	}
+	return rolePermissions
+}
+
+func (request *ADDirectoryRoleAssignmentScheduleRequestInfo) AssignmentScheduleRequestAction() *string {
+	if request.GetAction() == nil {
+		return nil
+	}
+	action := request.GetAction().String()
+	return &action
+}
+
+func (request *ADDirectoryRoleAssignmentScheduleRequestInfo) AssignmentScheduleRequestStartDateTime() *time.Time {
+	return requestScheduleStartDateTime(request.GetScheduleInfo())
+}
+
+func (request *ADDirectoryRoleAssignmentScheduleRequestInfo) AssignmentScheduleRequestEndDateTime() *time.Time {
+	return requestScheduleEndDateTime(request.GetScheduleInfo())
+}
+
+func (request *ADDirectoryRoleAssignmentScheduleRequestInfo) AssignmentScheduleRequestScheduleInfo() map[string]interface{} {
+	return requestScheduleInfo(request.GetScheduleInfo())
+}
+
+// AssignmentScheduleRequestCreatedBy returns the user or application which made the request, e.g. the principal who activated an eligible role.
+func (request *ADDirectoryRoleAssignmentScheduleRequestInfo) AssignmentScheduleRequestCreatedBy() map[string]interface{} {
+	createdBy := request.GetCreatedBy()
+	if createdBy == nil {
+		return nil
+	}
+
+	data := map[string]interface{}{}
+	for name, identity := range map[string]models.Identityable{
+		"user":        createdBy.GetUser(),
+		"application": createdBy.GetApplication(),
+		"device":      createdBy.GetDevice(),
+	} {
+		if identity == nil {
+			continue
+		}
+		identityData := map[string]interface{}{}
+		if identity.GetId() != nil {
+			identityData["id"] = *identity.GetId()
+		}
+		if identity.GetDisplayName() != nil {
+			identityData["displayName"] = *identity.GetDisplayName()
+		}
+		data[name] = identityData
+	}
+	return data
+}
+
+func (request *ADDirectoryRoleAssignmentScheduleRequestInfo) AssignmentScheduleRequestTicketInfo() map[string]interface{} {
+	ticketInfo := request.GetTicketInfo()
+	if ticketInfo == nil {
+		return nil
+	}
+
+	data := map[string]interface{}{}
+	if ticketInfo.GetTicketNumber() != nil {
+		data["ticketNumber"] = *ticketInfo.GetTicketNumber()
+	}
+	if ticketInfo.GetTicketSystem() != nil {
+		data["ticketSystem"] = *ticketInfo.GetTicketSystem()
+	}
+	return data
+}
+
+func (schedule *ADDirectoryRoleEligibilityScheduleInfo) EligibilityScheduleStartDateTime() *time.Time {
+	return requestScheduleStartDateTime(schedule.GetScheduleInfo())
+}
+
+func (schedule *ADDirectoryRoleEligibilityScheduleInfo) EligibilityScheduleEndDateTime() *time.Time {
+	return requestScheduleEndDateTime(schedule.GetScheduleInfo())
+}
+
+func (schedule *ADDirectoryRoleEligibilityScheduleInfo) EligibilityScheduleScheduleInfo() map[string]interface{} {
+	return requestScheduleInfo(schedule.GetScheduleInfo())
+}
+
+// requestScheduleStartDateTime returns when the eligibility or assignment of a PIM schedule starts.
+func requestScheduleStartDateTime(schedule models.RequestScheduleable) *time.Time {
+	if schedule == nil {
+		return nil
+	}
+	return schedule.GetStartDateTime()
+}
+
+// requestScheduleEndDateTime returns when the eligibility or assignment of a
+// PIM schedule ends, nil if it does not expire or expires after a duration.
+func requestScheduleEndDateTime(schedule models.RequestScheduleable) *time.Time {
+	if schedule == nil || schedule.GetExpiration() == nil {
+		return nil
+	}
+	return schedule.GetExpiration().GetEndDateTime()
+}
+
+func requestScheduleInfo(schedule models.RequestScheduleable) map[string]interface{} {
+	if schedule == nil {
+		return nil
+	}
+
+	data := map[string]interface{}{}
+	if schedule.GetStartDateTime() != nil {
+		data["startDateTime"] = *schedule.GetStartDateTime()
+	}
+	if expiration := schedule.GetExpiration(); expiration != nil {
+		expirationData := map[string]interface{}{}
+		if expiration.GetTypeEscaped() != nil {
+			expirationData["type"] = expiration.GetTypeEscaped().String()
+		}
+		if expiration.GetEndDateTime() != nil {
+			expirationData["endDateTime"] = *expiration.GetEndDateTime()
+		}
+		if expiration.GetDuration() != nil {
+			expirationData["duration"] = expiration.GetDuration().String()
+		}
+		data["expiration"] = expirationData
+	}
+	return data
+}
+
 // func (directorySetting *ADDirectorySettingInfo) DirectorySettingValues() []map[string]interface{} {
 // 	if directorySetting.GetValues() == nil {
 // 		return nil
@@ -738,6 +935,64 @@ func (group *ADGroupInfo) GroupAssignedLabels() []map[string]*string {
 	return assignedLabels
 }
 
+// directoryObjectType returns the type of a directory object without the
+// namespace of its OData type, e.g. user for #microsoft.graph.user.
+func directoryObjectType(object models.DirectoryObjectable) *string {
+	if object == nil || object.GetOdataType() == nil {
+		return nil
+	}
+	objectType := strings.TrimPrefix(*object.GetOdataType(), "#microsoft.graph.")
+	return &objectType
+}
+
+// MemberType returns the type of a group member, e.g. user for #microsoft.graph.user.
+func (member *ADGroupMemberInfo) MemberType() *string {
+	return directoryObjectType(member.DirectoryObjectable)
+}
+
+// directoryObjectDisplayName returns the display name of a directory object, if its type has one.
+func directoryObjectDisplayName(object models.DirectoryObjectable) *string {
+	if v, ok := object.(interface{ GetDisplayName() *string }); ok {
+		return v.GetDisplayName()
+	}
+	return nil
+}
+
+func (member *ADGroupMemberInfo) MemberDisplayName() *string {
+	return directoryObjectDisplayName(member.DirectoryObjectable)
+}
+
+func (member *ADGroupMemberInfo) MemberUserPrincipalName() *string {
+	if v, ok := member.DirectoryObjectable.(interface{ GetUserPrincipalName() *string }); ok {
+		return v.GetUserPrincipalName()
+	}
+	return nil
+}
+
+func (member *ADGroupMemberInfo) MemberMail() *string {
+	if v, ok := member.DirectoryObjectable.(interface{ GetMail() *string }); ok {
+		return v.GetMail()
+	}
+	return nil
+}
+
+// MemberOfType returns the type of the object the user is a member of, e.g. group for #microsoft.graph.group.
+func (memberOf *ADUserTransitiveMemberOfInfo) MemberOfType() *string {
+	return directoryObjectType(memberOf.DirectoryObjectable)
+}
+
+func (memberOf *ADUserTransitiveMemberOfInfo) MemberOfDisplayName() *string {
+	return directoryObjectDisplayName(memberOf.DirectoryObjectable)
+}
+
+func (memberOf *ADUserTransitiveMemberOfInfo) MemberOfDepth() int {
+	return len(memberOf.Path)
+}
+
+func (memberOf *ADUserTransitiveMemberOfInfo) MemberOfDirect() bool {
+	return len(memberOf.Path) == 1
+}
+
 func (servicePrincipal *ADServicePrincipalInfo) ServicePrincipalAddIns() []map[string]interface{} {
 	if servicePrincipal.GetAddIns() == nil {
 		return nil
@@ -1068,3 +1323,29 @@ func (user *ADUserInfo) UserPasswordProfile() map[string]interface{} {
 
 	return passwordProfileData
 }
+
+func (user *ADUserInfo) UserSignInActivity() map[string]interface{} {
+	if user.GetSignInActivity() == nil {
+		return nil
+	}
+
+	// Properties only returned by the beta endpoint, e.g. lastSuccessfulSignInDateTime
+	signInActivityData := map[string]interface{}{}
+	for name, value := range user.GetSignInActivity().GetAdditionalData() {
+		signInActivityData[name] = value
+	}
+	if user.GetSignInActivity().GetLastSignInDateTime() != nil {
+		signInActivityData["lastSignInDateTime"] = *user.GetSignInActivity().GetLastSignInDateTime()
+	}
+	if user.GetSignInActivity().GetLastSignInRequestId() != nil {
+		signInActivityData["lastSignInRequestId"] = *user.GetSignInActivity().GetLastSignInRequestId()
+	}
+	if user.GetSignInActivity().GetLastNonInteractiveSignInDateTime() != nil {
+		signInActivityData["lastNonInteractiveSignInDateTime"] = *user.GetSignInActivity().GetLastNonInteractiveSignInDateTime()
+	}
+	if user.GetSignInActivity().GetLastNonInteractiveSignInRequestId() != nil {
+		signInActivityData["lastNonInteractiveSignInRequestId"] = *user.GetSignInActivity().GetLastNonInteractiveSignInRequestId()
+	}
+
+	return signInActivityData
+}
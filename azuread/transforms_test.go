@@ -0,0 +1,118 @@
+package azuread
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestUserMemberOf(t *testing.T) {
+	user := models.NewUser()
+	if got := (&ADUserInfo{Userable: user}).UserMemberOf(); got != nil {
+		t.Errorf("expected nil for a user without memberOf, got %v", got)
+	}
+
+	group := models.NewGroup()
+	group.SetId(stringPtr("g1"))
+	group.SetOdataType(stringPtr("#microsoft.graph.group"))
+	user.SetMemberOf([]models.DirectoryObjectable{group})
+
+	got := (&ADUserInfo{Userable: user}).UserMemberOf()
+	want := []map[string]interface{}{
+		{"@odata.type": stringPtr("#microsoft.graph.group"), "id": stringPtr("g1")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestGroupAssignedLabels(t *testing.T) {
+	label := models.NewAssignedLabel()
+	label.SetLabelId(stringPtr("l1"))
+	label.SetDisplayName(stringPtr("Confidential"))
+
+	group := models.NewGroup()
+	group.SetAssignedLabels([]models.AssignedLabelable{label})
+
+	got := (&ADGroupInfo{Groupable: group}).GroupAssignedLabels()
+	want := []map[string]*string{
+		{"labelId": stringPtr("l1"), "displayName": stringPtr("Confidential")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestDirectoryAuditReport(t *testing.T) {
+	audit := models.NewDirectoryAudit()
+	info := &ADDirectoryAuditReportInfo{DirectoryAuditable: audit}
+
+	if got := info.DirectoryAuditResult(); got != "" {
+		t.Errorf("expected empty result, got %q", got)
+	}
+	if got := info.DirectoryAuditInitiatedBy(); got != nil {
+		t.Errorf("expected nil initiated_by, got %v", got)
+	}
+
+	result := models.SUCCESS_OPERATIONRESULT
+	audit.SetResult(&result)
+
+	user := models.NewUserIdentity()
+	user.SetId(stringPtr("u1"))
+	user.SetUserPrincipalName(stringPtr("admin@example.com"))
+	initiatedBy := models.NewAuditActivityInitiator()
+	initiatedBy.SetUser(user)
+	audit.SetInitiatedBy(initiatedBy)
+
+	property := models.NewModifiedProperty()
+	property.SetDisplayName(stringPtr("AccountEnabled"))
+	property.SetNewValue(stringPtr("[true]"))
+	target := models.NewTargetResource()
+	target.SetId(stringPtr("u2"))
+	target.SetTypeEscaped(stringPtr("User"))
+	target.SetModifiedProperties([]models.ModifiedPropertyable{property})
+	audit.SetTargetResources([]models.TargetResourceable{target})
+
+	if got := info.DirectoryAuditResult(); got != "success" {
+		t.Errorf("expected result success, got %q", got)
+	}
+
+	wantInitiatedBy := map[string]interface{}{
+		"user": map[string]interface{}{"id": "u1", "userPrincipalName": "admin@example.com"},
+	}
+	if got := info.DirectoryAuditInitiatedBy(); !reflect.DeepEqual(got, wantInitiatedBy) {
+		t.Errorf("expected initiated_by %v, got %v", wantInitiatedBy, got)
+	}
+
+	wantTargetResources := []map[string]interface{}{
+		{
+			"id":   "u2",
+			"type": "User",
+			"modifiedProperties": []map[string]interface{}{
+				{"displayName": "AccountEnabled", "newValue": "[true]"},
+			},
+		},
+	}
+	if got := info.DirectoryAuditTargetResources(); !reflect.DeepEqual(got, wantTargetResources) {
+		t.Errorf("expected target_resources %v, got %v", wantTargetResources, got)
+	}
+}
+
+func TestUserPasswordProfile(t *testing.T) {
+	profile := models.NewPasswordProfile()
+	profile.SetForceChangePasswordNextSignIn(Bool(true))
+
+	user := models.NewUser()
+	user.SetPasswordProfile(profile)
+
+	got := (&ADUserInfo{Userable: user}).UserPasswordProfile()
+	want := map[string]interface{}{"forceChangePasswordNextSignIn": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func stringPtr(v string) *string {
+	return &v
+}
@@ -0,0 +1,113 @@
+package azuread
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// clientAssertionType is the OAuth 2.0 client assertion type of a federated token.
+const clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// workloadIdentityCredential authenticates an application with a federated
+// token issued by an external identity provider, e.g. a Kubernetes service
+// account token or a GitHub Actions OIDC token, which is exchanged for a
+// Microsoft Entra access token using the OAuth 2.0 client credentials flow.
+//
+// The token file is read for every token request, since the token in it is
+// short lived and rotated by the platform.
+type workloadIdentityCredential struct {
+	tenantID      string
+	clientID      string
+	tokenFile     string
+	authorityHost string
+	client        *http.Client
+}
+
+func newWorkloadIdentityCredential(tenantID, clientID, tokenFile, authorityHost string) *workloadIdentityCredential {
+	return &workloadIdentityCredential{
+		tenantID:      tenantID,
+		clientID:      clientID,
+		tokenFile:     tokenFile,
+		authorityHost: authorityHost,
+		client:        &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// workloadIdentityError is returned when a federated token cannot be exchanged
+// for an access token. Retrying will not help, so it is not retriable.
+type workloadIdentityError struct {
+	message string
+}
+
+func (e *workloadIdentityError) Error() string {
+	return "workload identity authentication failed: " + e.message
+}
+
+// NonRetriable marks the error as a credential error, like the azidentity credential errors.
+func (e *workloadIdentityError) NonRetriable() {}
+
+func (c *workloadIdentityCredential) GetToken(ctx context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	assertion, err := os.ReadFile(c.tokenFile)
+	if err != nil {
+		return azcore.AccessToken{}, &workloadIdentityError{fmt.Sprintf("error reading federated token file %s: %v", c.tokenFile, err)}
+	}
+
+	form := url.Values{
+		"client_id":             {c.clientID},
+		"client_assertion_type": {clientAssertionType},
+		"client_assertion":      {strings.TrimSpace(string(assertion))},
+		"grant_type":            {"client_credentials"},
+		"scope":                 {strings.Join(opts.Scopes, " ")},
+	}
+	tokenUrl := fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimSuffix(c.authorityHost, "/"), c.tenantID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return azcore.AccessToken{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return azcore.AccessToken{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return azcore.AccessToken{}, err
+	}
+
+	var tokenResponse struct {
+		AccessToken      string      `json:"access_token"`
+		ExpiresIn        json.Number `json:"expires_in"`
+		Error            string      `json:"error"`
+		ErrorDescription string      `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &tokenResponse); err != nil {
+		return azcore.AccessToken{}, &workloadIdentityError{fmt.Sprintf("unexpected response from %s: %s", tokenUrl, resp.Status)}
+	}
+	if resp.StatusCode != http.StatusOK || tokenResponse.AccessToken == "" {
+		return azcore.AccessToken{}, &workloadIdentityError{fmt.Sprintf("%s: %s", tokenResponse.Error, tokenResponse.ErrorDescription)}
+	}
+
+	expiresIn, err := tokenResponse.ExpiresIn.Int64()
+	if err != nil {
+		return azcore.AccessToken{}, &workloadIdentityError{fmt.Sprintf("invalid expires_in %q in the token response", tokenResponse.ExpiresIn)}
+	}
+
+	return azcore.AccessToken{
+		Token:     tokenResponse.AccessToken,
+		ExpiresOn: time.Now().Add(time.Duration(expiresIn) * time.Second),
+	}, nil
+}
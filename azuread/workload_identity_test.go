@@ -0,0 +1,79 @@
+package azuread
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+func TestWorkloadIdentityCredential(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("federated-token-1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var assertions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+testTenantID+"/oauth2/v2.0/token" {
+			t.Errorf("unexpected token path %s", r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.Form.Get("client_assertion_type") != clientAssertionType || r.Form.Get("grant_type") != "client_credentials" {
+			t.Errorf("unexpected token request %v", r.Form)
+		}
+		if got := r.Form.Get("scope"); got != "https://graph.microsoft.com/.default" {
+			t.Errorf("unexpected scope %q", got)
+		}
+		assertions = append(assertions, r.Form.Get("client_assertion"))
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.Form.Get("client_id") != "app-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"unauthorized_client","error_description":"AADSTS700016: Application not found."}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"token_type":"Bearer","expires_in":3599,"access_token":"graph-token"}`))
+	}))
+	defer server.Close()
+
+	opts := policy.TokenRequestOptions{Scopes: []string{"https://graph.microsoft.com/.default"}}
+
+	cred := newWorkloadIdentityCredential(testTenantID, "app-1", tokenFile, server.URL+"/")
+	token, err := cred.GetToken(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("get token failed: %v", err)
+	}
+	if token.Token != "graph-token" || time.Until(token.ExpiresOn) < 59*time.Minute {
+		t.Errorf("unexpected token %+v", token)
+	}
+
+	// The rotated token is read from the file for the next request
+	if err := os.WriteFile(tokenFile, []byte("federated-token-2"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cred.GetToken(context.Background(), opts); err != nil {
+		t.Fatalf("get token failed: %v", err)
+	}
+	if strings.Join(assertions, ",") != "federated-token-1,federated-token-2" {
+		t.Errorf("unexpected client assertions %v", assertions)
+	}
+
+	_, err = newWorkloadIdentityCredential(testTenantID, "app-2", tokenFile, server.URL).GetToken(context.Background(), opts)
+	var credentialErr *workloadIdentityError
+	if !errors.As(err, &credentialErr) || !strings.Contains(err.Error(), "AADSTS700016") {
+		t.Fatalf("expected a workload identity error, got %v", err)
+	}
+	if got := getErrorObject(nil, err).Category; got != ErrorCategoryAuthentication {
+		t.Errorf("expected category %q, got %q", ErrorCategoryAuthentication, got)
+	}
+}
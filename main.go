@@ -7,6 +7,8 @@ import (
 )
 
 func main() {
+	defer azuread.ShutdownTelemetry()
+
 	plugin.Serve(&plugin.ServeOpts{
 		PluginFunc: azuread.Plugin})
 }